@@ -52,6 +52,21 @@ func (r *Redis) Del(key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// Incr increments the counter at key and starts its ttl on the first hit.
+func (r *Redis) Incr(key string, ttl time.Duration) (int64, error) {
+	ctx := context.Background()
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
 func (r *Redis) Close() error {
 	return r.client.Close()
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/server/configs"
 	"github.com/server/internal/transport"
+	"github.com/server/pkg/jwt"
 	"github.com/server/pkg/logger"
 
 	"github.com/server/adapters/storage/postgresql"
@@ -17,6 +18,10 @@ func main() {
 		log.Error("Failed to load config", zap.Error(err))
 		return
 	}
+	if err := jwt.Init(conf); err != nil {
+		log.Error("Failed to load jwt keys", zap.Error(err))
+		return
+	}
 	db, err := postgresql.New(conf, log)
 	if err != nil {
 		log.Error("Failed to initialize db", zap.Error(err))
@@ -2,19 +2,59 @@ package configs
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
 
 type Config struct {
-	DB           string
-	PORT         string
-	SECRET       string
-	CLIENT_URL   string
-	REDIS_HOST   string
-	RABBITMQ_URL string
+	DB            string
+	PORT          string
+	SECRET        string
+	CLIENT_URL    string
+	REDIS_HOST    string
+	RABBITMQ_URL  string
+	SMTP_HOST     string
+	SMTP_PORT     string
+	SMTP_USER     string
+	SMTP_PASSWORD string
+	SMTP_FROM     string
+
+	REQUIRE_EMAIL_VERIFICATION bool
+
+	OIDC_PROVIDERS []OIDCProviderConfig
+
+	JWT_SIGNING_KEY       string
+	JWT_SIGNING_KEY_FILE  string
+	JWT_SIGNING_KEY_ID    string
+	JWT_VERIFICATION_KEYS string
+	JWT_ACCEPT_HS256      bool
+
+	BOOTSTRAP_ADMIN_LOGIN string
+
+	SIMILARITY_THRESHOLD float64
+
+	SANDBOX_GO_BINARY string
+	SANDBOX_CACHE_DIR string
+
+	TRUSTED_PROXIES []*net.IPNet
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	Scopes       []string
 }
 
 func Load(log *zap.Logger) (*Config, error) {
@@ -58,12 +98,113 @@ func Load(log *zap.Logger) (*Config, error) {
 		return nil, fmt.Errorf("Load: REDIS_HOST env variable not set")
 	}
 
+	similarityThreshold, err := strconv.ParseFloat(getEnv("SIMILARITY_THRESHOLD", "0.6"), 64)
+	if err != nil || similarityThreshold <= 0 || similarityThreshold > 1 {
+		return nil, fmt.Errorf("Load: SIMILARITY_THRESHOLD must be a number in (0, 1]")
+	}
+
+	trustedProxies, err := loadTrustedProxies()
+	if err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+
 	return &Config{
-		DB:           db,
-		PORT:         port,
-		SECRET:       secret,
-		CLIENT_URL:   clientUrl,
-		REDIS_HOST:   redis,
-		RABBITMQ_URL: rabbit,
+		DB:            db,
+		PORT:          port,
+		SECRET:        secret,
+		CLIENT_URL:    clientUrl,
+		REDIS_HOST:    redis,
+		RABBITMQ_URL:  rabbit,
+		SMTP_HOST:     os.Getenv("SMTP_HOST"),
+		SMTP_PORT:     getEnv("SMTP_PORT", "587"),
+		SMTP_USER:     os.Getenv("SMTP_USER"),
+		SMTP_PASSWORD: os.Getenv("SMTP_PASSWORD"),
+		SMTP_FROM:     getEnv("SMTP_FROM", "no-reply@testconstructor.local"),
+
+		REQUIRE_EMAIL_VERIFICATION: os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true",
+
+		OIDC_PROVIDERS: loadOIDCProviders(),
+
+		JWT_SIGNING_KEY:       os.Getenv("JWT_SIGNING_KEY"),
+		JWT_SIGNING_KEY_FILE:  os.Getenv("JWT_SIGNING_KEY_FILE"),
+		JWT_SIGNING_KEY_ID:    os.Getenv("JWT_SIGNING_KEY_ID"),
+		JWT_VERIFICATION_KEYS: os.Getenv("JWT_VERIFICATION_KEYS"),
+		JWT_ACCEPT_HS256:      os.Getenv("JWT_ACCEPT_HS256") == "true",
+
+		BOOTSTRAP_ADMIN_LOGIN: os.Getenv("BOOTSTRAP_ADMIN_LOGIN"),
+
+		SIMILARITY_THRESHOLD: similarityThreshold,
+
+		SANDBOX_GO_BINARY: getEnv("SANDBOX_GO_BINARY", "go"),
+		SANDBOX_CACHE_DIR: getEnv("SANDBOX_CACHE_DIR", filepath.Join(os.TempDir(), "sandbox-go-cache")),
+
+		TRUSTED_PROXIES: trustedProxies,
 	}, nil
 }
+
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			JWKSURL:      os.Getenv(prefix + "JWKS_URL"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+
+	return providers
+}
+
+// loadTrustedProxies parses TRUSTED_PROXIES, a comma-separated list of
+// addresses and CIDR networks of the reverse proxies in front of the server.
+func loadTrustedProxies() ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("loadTrustedProxies: invalid address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("loadTrustedProxies: invalid network %q: %w", entry, err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Assignment struct {
+	gorm.Model
+	GroupID      uint       `json:"group_id" gorm:"index;not null"`
+	TestID       uint       `json:"test_id" gorm:"index;not null"`
+	AssignedByID uint       `json:"assigned_by_id"`
+	OpensAt      time.Time  `json:"opens_at"`
+	DueAt        *time.Time `json:"due_at"`
+	// MaxAttempts of zero means the number of attempts is not limited.
+	MaxAttempts int  `json:"max_attempts" gorm:"default:0"`
+	Test        Test `json:"-" gorm:"foreignKey:TestID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Attempt struct {
+	gorm.Model
+	TestID       uint     `json:"test_id" gorm:"index;not null"`
+	UserID       uint     `json:"user_id" gorm:"index;not null"`
+	AssignmentID *uint    `json:"assignment_id" gorm:"index"`
+	Score        *float64 `json:"score"`
+	// StartedAt is before the user sees the questions, so limits and
+	// cooldowns are checked on start. The attempt stays open until submitted.
+	StartedAt   time.Time  `json:"started_at"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	// PendingReview has no score until a grader finalizes its text answers.
+	PendingReview bool `json:"pending_review" gorm:"index;default:false"`
+	// Ability and its standard error end an adaptive attempt instead of a score.
+	Ability   *float64 `json:"ability"`
+	AbilitySE *float64 `json:"ability_se"`
+	// CurrentQuestionID is asked next in an adaptive attempt.
+	CurrentQuestionID *uint `json:"current_question_id"`
+	// CurrentSectionID is where an attempt of a test with sections is, it keeps
+	// its way through them in Sections.
+	CurrentSectionID *uint `json:"current_section_id"`
+	User             User  `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	// Variables hold the values drawn for the numeric questions on start.
+	Variables []AttemptVariable `json:"-" gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+	Sections  []AttemptSection  `json:"sections" gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+
+	AttemptGrade
+}
+
+// AttemptGrade is the outcome of a scored attempt under the pass mark and
+// grade bands of its test. Passed is nil when the test has no pass mark.
+type AttemptGrade struct {
+	Passed   *bool  `json:"passed"`
+	Band     string `json:"band"`
+	Feedback string `json:"feedback"`
+}
+
+type AttemptAnswer struct {
+	gorm.Model
+	AttemptID  uint `json:"attempt_id" gorm:"index;not null"`
+	QuestionID uint `json:"question_id" gorm:"index"`
+	// VariantID is a variant the user marked in a submitted attempt.
+	VariantID uint `json:"variant_id"`
+	Selected  bool `json:"selected"`
+	// Correct tells whether the mark or number was right.
+	Correct bool `json:"correct"`
+	// Text is the answer to a text question or the source of a code question.
+	Text string `json:"text"`
+	// Number is given to a numeric question, Expected is its value in the attempt.
+	Number   *float64 `json:"number"`
+	Expected *float64 `json:"expected"`
+	// Points are set on submit for variants, numbers and code and by a grader
+	// for text, or for what could not be graded automatically.
+	Points     *float64   `json:"points"`
+	Comment    string     `json:"comment"`
+	GradedByID *uint      `json:"graded_by_id"`
+	GradedAt   *time.Time `json:"graded_at"`
+	// CheckedAt is set once a text answer was compared with the others.
+	CheckedAt       *time.Time             `json:"-" gorm:"index"`
+	Attempt         Attempt                `json:"-" gorm:"foreignKey:AttemptID;constraint:OnDelete:CASCADE"`
+	CriterionScores []AnswerCriterionScore `json:"criterion_scores" gorm:"foreignKey:AnswerID;constraint:OnDelete:CASCADE"`
+	CaseResults     []CodeTestResult       `json:"case_results" gorm:"foreignKey:AnswerID;constraint:OnDelete:CASCADE"`
+}
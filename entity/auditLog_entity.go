@@ -0,0 +1,13 @@
+package entity
+
+import "gorm.io/gorm"
+
+type AuditLog struct {
+	gorm.Model
+	ActorID    uint   `json:"actor_id" gorm:"index;not null"`
+	Action     string `json:"action" gorm:"index;not null"`
+	TargetType string `json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	IP         string `json:"ip"`
+	Details    string `json:"details"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CodeTestCase feeds Input to the program of a code question on stdin and
+// expects Expected on stdout, compared without surrounding whitespace. Hidden
+// cases are never shown to the taker.
+type CodeTestCase struct {
+	gorm.Model
+	QuestionID uint    `json:"question_id" gorm:"index;not null"`
+	Name       string  `json:"name"`
+	Input      string  `json:"input"`
+	Expected   string  `json:"expected"`
+	Hidden     bool    `json:"hidden" gorm:"default:false"`
+	Points     float64 `json:"points" gorm:"default:1"`
+	Position   int     `json:"position"`
+}
+
+// CodeTestResult is how a code answer did on one test case. Output and Error
+// are cut to the sandbox output limit.
+type CodeTestResult struct {
+	gorm.Model
+	AnswerID   uint   `json:"answer_id" gorm:"index;not null"`
+	TestCaseID uint   `json:"test_case_id" gorm:"index"`
+	Passed     bool   `json:"passed"`
+	Output     string `json:"output"`
+	Error      string `json:"error"`
+	TimedOut   bool   `json:"timed_out"`
+	DurationMs int64  `json:"duration_ms"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "gorm.io/gorm"
+
+type Group struct {
+	gorm.Model
+	Name        string        `json:"name" gorm:"not null"`
+	OwnerID     uint          `json:"owner_id" gorm:"index;not null"`
+	JoinCode    string        `json:"join_code" gorm:"uniqueIndex;not null"`
+	Members     []GroupMember `json:"-" gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+	Assignments []Assignment  `json:"-" gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+}
+
+type GroupMember struct {
+	gorm.Model
+	GroupID uint `json:"group_id" gorm:"uniqueIndex:idx_group_member;not null"`
+	UserID  uint `json:"user_id" gorm:"uniqueIndex:idx_group_member;not null"`
+	User    User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
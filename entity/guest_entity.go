@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Guest describes an anonymous test taker. UserID points to the placeholder
+// user that owns the guest's attempts until they are claimed by a real account.
+type Guest struct {
+	gorm.Model
+	UserID      uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	TestID      uint       `json:"test_id" gorm:"index;not null"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	IP          string     `json:"ip"`
+	ClaimedByID *uint      `json:"claimed_by_id"`
+	ClaimedAt   *time.Time `json:"claimed_at"`
+	User        User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
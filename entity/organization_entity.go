@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Organization struct {
+	gorm.Model
+	Name    string               `json:"name" gorm:"not null"`
+	Members []OrganizationMember `json:"-" gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
+	Tests   []Test               `json:"-" gorm:"foreignKey:OrganizationID;constraint:OnDelete:SET NULL"`
+}
+
+type OrganizationMember struct {
+	gorm.Model
+	OrganizationID uint   `json:"organization_id" gorm:"uniqueIndex:idx_organization_member;not null"`
+	UserID         uint   `json:"user_id" gorm:"uniqueIndex:idx_organization_member;not null"`
+	Role           string `json:"role" gorm:"not null"`
+	User           User   `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type OrganizationInvite struct {
+	gorm.Model
+	OrganizationID uint       `json:"organization_id" gorm:"index;not null"`
+	Email          string     `json:"email" gorm:"index;not null"`
+	Role           string     `json:"role" gorm:"not null"`
+	TokenHash      string     `json:"-" gorm:"uniqueIndex;not null"`
+	InvitedByID    uint       `json:"invited_by_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
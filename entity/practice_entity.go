@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PracticeCard is the spaced repetition state of one question for one user.
+// The question is due again at DueAt, IntervalDays after it was last
+// reviewed; Lapses counts the wrong answers.
+type PracticeCard struct {
+	gorm.Model
+	UserID         uint       `json:"user_id" gorm:"uniqueIndex:idx_practice_card;not null"`
+	QuestionID     uint       `json:"question_id" gorm:"uniqueIndex:idx_practice_card;not null"`
+	TestID         uint       `json:"test_id" gorm:"index;not null"`
+	Repetitions    int        `json:"repetitions"`
+	IntervalDays   int        `json:"interval_days"`
+	EaseFactor     float64    `json:"ease_factor" gorm:"default:2.5"`
+	Lapses         int        `json:"lapses"`
+	DueAt          time.Time  `json:"due_at" gorm:"index"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+	User           User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Question       Question   `json:"-" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+}
+
+// PracticeReview is one practice answer with the ids of the marked variants
+// in Selected, separated by commas. It is kept apart from attempts so that
+// practice never counts towards the results of a test.
+type PracticeReview struct {
+	gorm.Model
+	CardID     uint         `json:"card_id" gorm:"index;not null"`
+	Selected   string       `json:"selected"`
+	Correct    bool         `json:"correct"`
+	Quality    int          `json:"quality"`
+	ReviewedAt time.Time    `json:"reviewed_at"`
+	Card       PracticeCard `json:"-" gorm:"foreignKey:CardID;constraint:OnDelete:CASCADE"`
+}
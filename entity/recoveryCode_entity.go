@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `json:"user_id" gorm:"index;not null"`
+	CodeHash string     `json:"-" gorm:"not null"`
+	UsedAt   *time.Time `json:"used_at"`
+}
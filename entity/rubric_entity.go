@@ -0,0 +1,32 @@
+package entity
+
+import "gorm.io/gorm"
+
+// RubricCriterion is one aspect a text answer is graded on. A criterion is
+// worth the points of its highest level.
+type RubricCriterion struct {
+	gorm.Model
+	QuestionID  uint          `json:"question_id" gorm:"index;not null"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Position    int           `json:"position"`
+	Levels      []RubricLevel `json:"levels" gorm:"foreignKey:CriterionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type RubricLevel struct {
+	gorm.Model
+	CriterionID uint    `json:"criterion_id" gorm:"index;not null"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Points      float64 `json:"points"`
+}
+
+// AnswerCriterionScore is the grade of a text answer on one rubric criterion.
+// LevelID is nil when the grader gave points without picking a level.
+type AnswerCriterionScore struct {
+	gorm.Model
+	AnswerID    uint    `json:"answer_id" gorm:"index;not null"`
+	CriterionID uint    `json:"criterion_id" gorm:"index"`
+	LevelID     *uint   `json:"level_id"`
+	Points      float64 `json:"points"`
+}
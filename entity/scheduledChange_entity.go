@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledChange struct {
+	gorm.Model
+	TestID      uint       `json:"test_id" gorm:"index;not null"`
+	Action      string     `json:"action" gorm:"not null"`
+	RunAt       time.Time  `json:"run_at" gorm:"index;not null"`
+	CreatedByID uint       `json:"created_by_id"`
+	AppliedAt   *time.Time `json:"applied_at"`
+	Test        Test       `json:"-" gorm:"foreignKey:TestID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Section is a part of a test taken in Position order. TimeLimit is in
+// seconds and not limited when zero. A section without AllowBack is closed
+// for good once the taker moves on.
+type Section struct {
+	gorm.Model
+	TestID       uint   `json:"test_id" gorm:"index;not null"`
+	Name         string `json:"name"`
+	Instructions string `json:"instructions"`
+	Position     int    `json:"position"`
+	TimeLimit    int    `json:"time_limit"`
+	AllowBack    bool   `json:"allow_back" gorm:"default:false"`
+}
+
+// AttemptSection is how an attempt went through one section. SpentSeconds
+// add up the earlier visits, the current one started at EnteredAt. The
+// answers of a section are stored when it is left and a ClosedAt section
+// can not be entered again.
+type AttemptSection struct {
+	gorm.Model
+	AttemptID    uint       `json:"attempt_id" gorm:"uniqueIndex:idx_attempt_section;not null"`
+	SectionID    uint       `json:"section_id" gorm:"uniqueIndex:idx_attempt_section;not null"`
+	EnteredAt    *time.Time `json:"entered_at"`
+	SpentSeconds int        `json:"spent_seconds"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
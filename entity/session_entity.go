@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Session struct {
+	gorm.Model
+	UserID       uint      `json:"user_id" gorm:"index;not null"`
+	RefreshToken string    `json:"-" gorm:"type:text"`
+	UserAgent    string    `json:"user_agent"`
+	IP           string    `json:"ip"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	// ImpersonatorID is set when an admin opened this session on behalf of the user.
+	ImpersonatorID *uint `json:"impersonator_id" gorm:"index"`
+}
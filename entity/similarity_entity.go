@@ -0,0 +1,16 @@
+package entity
+
+import "gorm.io/gorm"
+
+// SimilarityFlag marks two text answers to the same question that look
+// copied from each other. FirstAnswerID is always the smaller id.
+type SimilarityFlag struct {
+	gorm.Model
+	TestID         uint          `json:"test_id" gorm:"index;not null"`
+	QuestionID     uint          `json:"question_id" gorm:"index"`
+	FirstAnswerID  uint          `json:"first_answer_id" gorm:"uniqueIndex:idx_similarity_pair"`
+	SecondAnswerID uint          `json:"second_answer_id" gorm:"uniqueIndex:idx_similarity_pair"`
+	Similarity     float64       `json:"similarity"`
+	FirstAnswer    AttemptAnswer `json:"-" gorm:"foreignKey:FirstAnswerID;constraint:OnDelete:CASCADE"`
+	SecondAnswer   AttemptAnswer `json:"-" gorm:"foreignKey:SecondAnswerID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "gorm.io/gorm"
+
+type TestCollaborator struct {
+	gorm.Model
+	TestID    uint   `json:"test_id" gorm:"uniqueIndex:idx_test_collaborator;not null"`
+	UserID    uint   `json:"user_id" gorm:"uniqueIndex:idx_test_collaborator;not null"`
+	Role      string `json:"role" gorm:"not null"`
+	AddedByID uint   `json:"added_by_id"`
+	User      User   `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Test      Test   `json:"-" gorm:"foreignKey:TestID;constraint:OnDelete:CASCADE"`
+}
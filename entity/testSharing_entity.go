@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestInvitee lets a user pass an invite-only test, matched either by login
+// or by verified email.
+type TestInvitee struct {
+	gorm.Model
+	TestID uint   `json:"test_id" gorm:"index;not null"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+}
+
+// TestInviteLink is shared as a signed token; Uses is bumped every time a new
+// user redeems it. MaxUses of zero means unlimited.
+type TestInviteLink struct {
+	gorm.Model
+	TestID      uint       `json:"test_id" gorm:"index;not null"`
+	CreatedByID uint       `json:"created_by_id" gorm:"not null"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxUses     int        `json:"max_uses"`
+	Uses        int        `json:"uses"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// TestAccessGrant remembers that a user opened a restricted test through its
+// slug, access code or an invite link.
+type TestAccessGrant struct {
+	gorm.Model
+	TestID uint   `json:"test_id" gorm:"uniqueIndex:idx_test_access_grant;not null"`
+	UserID uint   `json:"user_id" gorm:"uniqueIndex:idx_test_access_grant;not null"`
+	Source string `json:"source"`
+}
@@ -1,30 +1,108 @@
 package entity
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Test struct {
 	gorm.Model
-	Name          string     `json:"name"`
-	AuthorLogin   string     `json:"author_login"`
-	UserID        uint       `json:"user_id"`
-	IsActive      bool       `json:"is_active" gorm:"default:true"`
-	CountUserPast uint       `json:"count_user_past"`
-	Questions     []Question `json:"questions" gorm:"foreignKey:TestID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Name        string `json:"name"`
+	AuthorLogin string `json:"author_login"`
+	UserID      uint   `json:"user_id"`
+	// OrganizationID owns the test instead of its author when set.
+	OrganizationID *uint `json:"organization_id" gorm:"index"`
+	IsActive       bool  `json:"is_active" gorm:"default:true"`
+	// AccessMode restricts who may pass an available test, Slug and
+	// AccessCodeHash are only used by the link and code modes.
+	AccessMode     string  `json:"access_mode" gorm:"default:public"`
+	Slug           *string `json:"-" gorm:"uniqueIndex"`
+	AccessCodeHash string  `json:"-"`
+	// AllowGuests lets people without an account pass the test, GuestInfo
+	// says what they must tell.
+	AllowGuests bool   `json:"allow_guests" gorm:"default:false"`
+	GuestInfo   string `json:"guest_info" gorm:"default:none"`
+	// OpensAt and ClosesAt limit when passing users can see and submit it.
+	OpensAt  *time.Time `json:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at"`
+	// MaxAttempts and AttemptCooldown (in seconds) are not limited when zero.
+	MaxAttempts     int `json:"max_attempts"`
+	AttemptCooldown int `json:"attempt_cooldown"`
+	// GradingPolicy picks which attempt counts as the official grade.
+	GradingPolicy string `json:"grading_policy" gorm:"default:best"`
+	// PassMark is a percentage, a test without it has no pass/fail outcome.
+	PassMark *float64 `json:"pass_mark"`
+	// ReviewPolicy says how much of a submitted attempt its taker may see and
+	// ReviewRelease when, ReviewReleasedAt is set by the owner in manual mode.
+	ReviewPolicy     string     `json:"review_policy" gorm:"default:never"`
+	ReviewRelease    string     `json:"review_release" gorm:"default:immediate"`
+	ReviewReleasedAt *time.Time `json:"review_released_at"`
+	// Adaptive asks one question at a time, picked by the ability of the taker
+	// estimated under AdaptiveModel, until the standard error of the estimate
+	// reaches AdaptiveTargetSE or AdaptiveMaxItems were asked.
+	Adaptive         bool    `json:"adaptive" gorm:"default:false"`
+	AdaptiveModel    string  `json:"adaptive_model" gorm:"default:1pl"`
+	AdaptiveMaxItems int     `json:"adaptive_max_items"`
+	AdaptiveTargetSE float64 `json:"adaptive_target_se"`
+	// Practice lets passing users drill the questions with instant feedback.
+	Practice      bool        `json:"practice" gorm:"default:false"`
+	CountUserPast uint        `json:"count_user_past"`
+	Questions     []Question  `json:"questions" gorm:"foreignKey:TestID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	GradeBands    []GradeBand `json:"grade_bands" gorm:"foreignKey:TestID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// Sections are taken one by one, every question then belongs to one of them.
+	Sections []Section `json:"sections" gorm:"foreignKey:TestID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 type Question struct {
 	gorm.Model
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Variants    []Variant `json:"variants" gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	TestID      uint      `json:"test_id" gorm:"index"`
+	// Name and Description of a numeric question are templates with {name}
+	// placeholders for its Variables.
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Explanation string `json:"explanation"`
+	// Type is choice for questions answered by marking variants, text for free
+	// text, code or numeric.
+	Type string `json:"type" gorm:"default:choice"`
+	// MaxPoints is what a text answer can be graded by hand for, unless the
+	// question has rubric Criteria.
+	MaxPoints float64 `json:"max_points" gorm:"default:1"`
+	// Language and Harness of the author build the source of a code answer,
+	// Starter is the code the taker begins with.
+	Language string `json:"language"`
+	Harness  string `json:"harness"`
+	Starter  string `json:"starter"`
+	// A numeric answer within Tolerance of AnswerExpr evaluated with the values
+	// of the attempt gets MaxPoints.
+	AnswerExpr string  `json:"answer_expr"`
+	Tolerance  float64 `json:"tolerance"`
+	// Difficulty and Discrimination are the item parameters adaptive tests
+	// choose by, fitted from past answers at CalibratedAt.
+	Difficulty     float64            `json:"difficulty"`
+	Discrimination float64            `json:"discrimination" gorm:"default:1"`
+	CalibratedAt   *time.Time         `json:"calibrated_at"`
+	Variants       []Variant          `json:"variants" gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Criteria       []RubricCriterion  `json:"criteria" gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TestCases      []CodeTestCase     `json:"test_cases" gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Variables      []QuestionVariable `json:"variables" gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TestID         uint               `json:"test_id" gorm:"index"`
+	SectionID      *uint              `json:"section_id" gorm:"index"`
 }
 
 type Variant struct {
 	gorm.Model
-	Name       string `json:"name"`
-	QuestionID uint   `json:"question_id" gorm:"index"`
-	IsCorrect  bool   `json:"is_correct" gorm:"default:false" `
+	Name        string `json:"name"`
+	QuestionID  uint   `json:"question_id" gorm:"index"`
+	IsCorrect   bool   `json:"is_correct" gorm:"default:false" `
+	Explanation string `json:"explanation"`
+}
+
+// GradeBand is awarded to attempts scoring at least MinScore percent. The band
+// with the highest MinScore not above the score wins.
+type GradeBand struct {
+	gorm.Model
+	TestID   uint    `json:"test_id" gorm:"index;not null"`
+	Name     string  `json:"name"`
+	MinScore float64 `json:"min_score"`
+	Feedback string  `json:"feedback"`
 }
@@ -0,0 +1,11 @@
+package entity
+
+import "gorm.io/gorm"
+
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"index;not null"`
+	Provider string `json:"provider" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+	Subject  string `json:"subject" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+	Email    string `json:"email"`
+}
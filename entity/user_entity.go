@@ -1,14 +1,27 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Name         string  `json:"name"`
-	Login        string  `json:"login" gorm:"index,unique,not null"`
-	Password     string  `json:"password"`
-	Avatar       *string `json:"avatar"`
-	Email        string  `json:"email" gorm:"index,unique,not null"`
-	RefreshToken string  `json:"refresh_token" gorm:"type:text"`
-	Tests        []Test  `json:"tests" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name          string     `json:"name"`
+	Login         string     `json:"login" gorm:"index,unique,not null"`
+	Password      string     `json:"password"`
+	Avatar        *string    `json:"avatar"`
+	Email         string     `json:"email" gorm:"index,unique,not null"`
+	EmailVerified bool       `json:"email_verified" gorm:"default:false"`
+	PendingEmail  string     `json:"pending_email"`
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabled   bool       `json:"two_factor_enabled" gorm:"default:false"`
+	TOTPLastStep  int64      `json:"-" gorm:"default:0;not null"`
+	TOTPFailures  int        `json:"-" gorm:"default:0;not null"`
+	TOTPLockUntil *time.Time `json:"-"`
+	Role          string     `json:"role" gorm:"default:user;not null"`
+	SuspendedAt   *time.Time `json:"suspended_at"`
+	Tests         []Test     `json:"tests" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Sessions      []Session  `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
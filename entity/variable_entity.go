@@ -0,0 +1,23 @@
+package entity
+
+import "gorm.io/gorm"
+
+// QuestionVariable gets a value between Min and Max rounded to Decimals
+// places for every attempt.
+type QuestionVariable struct {
+	gorm.Model
+	QuestionID uint    `json:"question_id" gorm:"index;not null"`
+	Name       string  `json:"name"`
+	Min        float64 `json:"min"`
+	Max        float64 `json:"max"`
+	Decimals   int     `json:"decimals"`
+}
+
+// AttemptVariable is the value a question variable got in one attempt.
+type AttemptVariable struct {
+	gorm.Model
+	AttemptID  uint    `json:"attempt_id" gorm:"index;not null"`
+	QuestionID uint    `json:"question_id" gorm:"index"`
+	Name       string  `json:"name"`
+	Value      float64 `json:"value"`
+}
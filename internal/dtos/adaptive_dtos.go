@@ -0,0 +1,52 @@
+package dtos
+
+import "time"
+
+type UpdateAdaptiveSettingsRequest struct {
+	Adaptive bool    `json:"adaptive"`
+	Model    string  `json:"model" validate:"required,oneof=1pl 2pl"`
+	MaxItems int     `json:"max_items" validate:"gte=0,lte=500"`
+	TargetSE float64 `json:"target_se" validate:"gte=0,lte=2"`
+}
+
+type AdaptiveSettingsResponse struct {
+	TestID   uint    `json:"test_id"`
+	Adaptive bool    `json:"adaptive"`
+	Model    string  `json:"model"`
+	MaxItems int     `json:"max_items"`
+	TargetSE float64 `json:"target_se"`
+}
+
+type ItemParameters struct {
+	QuestionID     uint       `json:"question_id"`
+	Name           string     `json:"name"`
+	Difficulty     float64    `json:"difficulty"`
+	Discrimination float64    `json:"discrimination"`
+	Responses      int        `json:"responses"`
+	CalibratedAt   *time.Time `json:"calibrated_at"`
+}
+
+type CalibrationResponse struct {
+	TestID uint             `json:"test_id"`
+	Model  string           `json:"model"`
+	Items  []ItemParameters `json:"items"`
+}
+
+// NextQuestionRequest answers the question the attempt was asked last:
+// Selected holds the marked variants of a choice question and Value the
+// number given to a numeric one. An empty request asks for the current or
+// the first question.
+type NextQuestionRequest struct {
+	QuestionID uint     `json:"question_id"`
+	Selected   []uint   `json:"selected"`
+	Value      *float64 `json:"value"`
+}
+
+type NextQuestionResponse struct {
+	AttemptID     uint                 `json:"attempt_id"`
+	Finished      bool                 `json:"finished"`
+	Ability       float64              `json:"ability"`
+	StandardError float64              `json:"standard_error"`
+	Answered      int                  `json:"answered"`
+	Question      *GetQuestionResponse `json:"question,omitempty"`
+}
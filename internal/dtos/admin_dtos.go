@@ -0,0 +1,58 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/server/entity"
+)
+
+type AdminUserResponse struct {
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	Login         string     `json:"login"`
+	Email         string     `json:"email"`
+	Avatar        *string    `json:"avatar"`
+	EmailVerified bool       `json:"email_verified"`
+	Role          string     `json:"role"`
+	SuspendedAt   *time.Time `json:"suspended_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type AdminUsersResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Count int64               `json:"count"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type AuditLogsResponse struct {
+	Entries []entity.AuditLog `json:"entries"`
+	Count   int64             `json:"count"`
+}
+
+type ImpersonateResponse struct {
+	Token string `json:"token"`
+}
+
+func ToAdminUsersResponse(users []entity.User, count int64) *AdminUsersResponse {
+	result := &AdminUsersResponse{
+		Users: make([]AdminUserResponse, len(users)),
+		Count: count,
+	}
+	for i, user := range users {
+		result.Users[i] = AdminUserResponse{
+			ID:            user.ID,
+			Name:          user.Name,
+			Login:         user.Login,
+			Email:         user.Email,
+			Avatar:        user.Avatar,
+			EmailVerified: user.EmailVerified,
+			Role:          user.Role,
+			SuspendedAt:   user.SuspendedAt,
+			CreatedAt:     user.CreatedAt,
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package dtos
+
+import (
+	"strings"
+	"time"
+
+	"github.com/server/entity"
+)
+
+func ToAPIKeyResponses(keys []entity.APIKey) []APIKeyResponse {
+	result := make([]APIKeyResponse, len(keys))
+
+	for i, key := range keys {
+		result[i] = ToAPIKeyResponse(&key)
+	}
+
+	return result
+}
+
+func ToAPIKeyResponse(key *entity.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=tests:read tests:write results:read"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"gte=0"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key string `json:"key"`
+	APIKeyResponse
+}
@@ -0,0 +1,68 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/server/entity"
+)
+
+type CreateAssignmentRequest struct {
+	TestID      uint       `json:"test_id" validate:"required"`
+	OpensAt     *time.Time `json:"opens_at"`
+	DueAt       *time.Time `json:"due_at"`
+	MaxAttempts int        `json:"max_attempts" validate:"gte=0"`
+}
+
+type AssignmentResponse struct {
+	ID          uint       `json:"id"`
+	GroupID     uint       `json:"group_id"`
+	TestID      uint       `json:"test_id"`
+	TestName    string     `json:"test_name"`
+	OpensAt     time.Time  `json:"opens_at"`
+	DueAt       *time.Time `json:"due_at"`
+	MaxAttempts int        `json:"max_attempts"`
+}
+
+type UserAssignmentResponse struct {
+	AssignmentResponse
+	Status       string   `json:"status"`
+	AttemptsUsed int      `json:"attempts_used"`
+	Score        *float64 `json:"score"`
+}
+
+type AssignmentReportRow struct {
+	UserID          uint       `json:"user_id"`
+	Login           string     `json:"login"`
+	Name            string     `json:"name"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	Score           *float64   `json:"score"`
+	LastSubmittedAt *time.Time `json:"last_submitted_at"`
+}
+
+type AssignmentReportResponse struct {
+	Assignment AssignmentResponse    `json:"assignment"`
+	Total      int                   `json:"total"`
+	Completed  int                   `json:"completed"`
+	Members    []AssignmentReportRow `json:"members"`
+}
+
+func ToAssignmentResponse(assignment *entity.Assignment) AssignmentResponse {
+	return AssignmentResponse{
+		ID:          assignment.ID,
+		GroupID:     assignment.GroupID,
+		TestID:      assignment.TestID,
+		TestName:    assignment.Test.Name,
+		OpensAt:     assignment.OpensAt,
+		DueAt:       assignment.DueAt,
+		MaxAttempts: assignment.MaxAttempts,
+	}
+}
+
+func ToAssignmentResponses(assignments []entity.Assignment) []AssignmentResponse {
+	result := make([]AssignmentResponse, len(assignments))
+	for i := range assignments {
+		result[i] = ToAssignmentResponse(&assignments[i])
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/server/entity"
+)
+
+type StartAttemptRequest struct {
+	AssignmentID *uint `json:"assignment_id"`
+}
+
+type TestResultRow struct {
+	UserID          uint       `json:"user_id"`
+	Login           string     `json:"login"`
+	Name            string     `json:"name"`
+	Attempts        int        `json:"attempts"`
+	Score           *float64   `json:"score"`
+	Passed          *bool      `json:"passed"`
+	Band            string     `json:"band"`
+	LastSubmittedAt *time.Time `json:"last_submitted_at"`
+	Ability         *float64   `json:"ability,omitempty"`
+}
+
+type TestResultsResponse struct {
+	TestID        uint            `json:"test_id"`
+	GradingPolicy string          `json:"grading_policy"`
+	Results       []TestResultRow `json:"results"`
+}
+
+type LeaderboardEntry struct {
+	Rank  int     `json:"rank"`
+	Login string  `json:"login"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type GradeBandRequest struct {
+	Name     string  `json:"name" validate:"required,max=50"`
+	MinScore float64 `json:"min_score" validate:"gte=0,lte=100"`
+	Feedback string  `json:"feedback" validate:"max=2000"`
+}
+
+type UpdateGradingRequest struct {
+	PassMark *float64           `json:"pass_mark" validate:"omitempty,gte=0,lte=100"`
+	Bands    []GradeBandRequest `json:"bands" validate:"dive"`
+}
+
+type GradingResponse struct {
+	TestID   uint               `json:"test_id"`
+	PassMark *float64           `json:"pass_mark"`
+	Bands    []entity.GradeBand `json:"bands"`
+}
+
+type ReapplyGradingResponse struct {
+	Updated int `json:"updated"`
+}
+
+type UpdateReviewPolicyRequest struct {
+	Policy  string `json:"policy" validate:"required,oneof=never score mistakes full"`
+	Release string `json:"release" validate:"required,oneof=immediate after_deadline manual"`
+}
+
+type ReviewVariant struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Selected    bool   `json:"selected"`
+	IsCorrect   *bool  `json:"is_correct,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+type ReviewQuestion struct {
+	QuestionID  uint             `json:"question_id"`
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Correct     bool             `json:"correct"`
+	Answer      string           `json:"answer,omitempty"`
+	Points      *float64         `json:"points,omitempty"`
+	MaxPoints   float64          `json:"max_points,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
+	Expected    *float64         `json:"expected,omitempty"`
+	Explanation string           `json:"explanation,omitempty"`
+	Variants    []ReviewVariant  `json:"variants"`
+	Cases       []ReviewTestCase `json:"cases,omitempty"`
+}
+
+type AttemptReviewResponse struct {
+	AttemptID   uint             `json:"attempt_id"`
+	TestID      uint             `json:"test_id"`
+	Policy      string           `json:"policy"`
+	Score       *float64         `json:"score"`
+	Passed      *bool            `json:"passed"`
+	Band        string           `json:"band"`
+	Feedback    string           `json:"feedback"`
+	SubmittedAt *time.Time       `json:"submitted_at"`
+	Sections    []SectionScore   `json:"sections,omitempty"`
+	Questions   []ReviewQuestion `json:"questions,omitempty"`
+}
+
+type GradingQueueItem struct {
+	AnswerID     uint       `json:"answer_id"`
+	AttemptID    uint       `json:"attempt_id"`
+	QuestionID   uint       `json:"question_id"`
+	QuestionName string     `json:"question_name"`
+	MaxPoints    float64    `json:"max_points"`
+	Text         string     `json:"text"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+}
+
+type CriterionScoreInput struct {
+	CriterionID uint     `json:"criterion_id" validate:"required"`
+	LevelID     *uint    `json:"level_id"`
+	Points      *float64 `json:"points" validate:"omitempty,gte=0"`
+}
+
+type GradeAnswerRequest struct {
+	Points   float64               `json:"points" validate:"gte=0"`
+	Criteria []CriterionScoreInput `json:"criteria" validate:"dive"`
+	Comment  string                `json:"comment" validate:"max=2000"`
+}
+
+type AnswerGradeInput struct {
+	AnswerID uint                  `json:"answer_id" validate:"required"`
+	Points   float64               `json:"points" validate:"gte=0"`
+	Criteria []CriterionScoreInput `json:"criteria" validate:"dive"`
+	Comment  string                `json:"comment" validate:"max=2000"`
+}
+
+type BulkGradeRequest struct {
+	QuestionID uint               `json:"question_id" validate:"required"`
+	Grades     []AnswerGradeInput `json:"grades" validate:"required,min=1,dive"`
+}
@@ -13,8 +13,10 @@ func (r *RegistrationRequest) ToUser() entity.User {
 }
 
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  *entity.User `json:"user"`
+	Token             string       `json:"token"`
+	User              *entity.User `json:"user"`
+	TwoFactorRequired bool         `json:"two_factor_required"`
+	ChallengeToken    string       `json:"challenge_token,omitempty"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,46 @@
+package dtos
+
+import "github.com/server/entity"
+
+type CodeTestCaseInput struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	Input    string  `json:"input" validate:"max=65536"`
+	Expected string  `json:"expected" validate:"max=65536"`
+	Hidden   bool    `json:"hidden"`
+	Points   float64 `json:"points" validate:"gt=0"`
+}
+
+type UpdateCodeTestsRequest struct {
+	Language string              `json:"language" validate:"required,max=20"`
+	Harness  string              `json:"harness" validate:"required,max=65536"`
+	Starter  string              `json:"starter" validate:"max=65536"`
+	Cases    []CodeTestCaseInput `json:"cases" validate:"required,min=1,dive"`
+}
+
+type CodeTestsResponse struct {
+	QuestionID uint                  `json:"question_id"`
+	Language   string                `json:"language"`
+	Harness    string                `json:"harness"`
+	Starter    string                `json:"starter"`
+	MaxPoints  float64               `json:"max_points"`
+	Languages  []string              `json:"languages"`
+	Cases      []entity.CodeTestCase `json:"cases"`
+}
+
+type CodeAnswerInput struct {
+	QuestionID uint   `json:"question_id"`
+	Code       string `json:"code"`
+}
+
+// ReviewTestCase is how a code answer did on one test case. The data of the
+// case is only shown when the case is not hidden.
+type ReviewTestCase struct {
+	Name     string `json:"name"`
+	Hidden   bool   `json:"hidden"`
+	Passed   bool   `json:"passed"`
+	TimedOut bool   `json:"timed_out"`
+	Input    string `json:"input,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,56 @@
+package dtos
+
+import "github.com/server/entity"
+
+type CreateGroupRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type JoinGroupRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type GroupResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	OwnerID  uint   `json:"owner_id"`
+	IsOwner  bool   `json:"is_owner"`
+	JoinCode string `json:"join_code,omitempty"`
+}
+
+type GroupMemberResponse struct {
+	UserID uint    `json:"user_id"`
+	Login  string  `json:"login"`
+	Name   string  `json:"name"`
+	Avatar *string `json:"avatar"`
+}
+
+// ToGroupResponses hides join codes from everyone except the group owner.
+func ToGroupResponses(groups []entity.Group, userId uint) []GroupResponse {
+	result := make([]GroupResponse, len(groups))
+	for i, group := range groups {
+		result[i] = GroupResponse{
+			ID:      group.ID,
+			Name:    group.Name,
+			OwnerID: group.OwnerID,
+			IsOwner: group.OwnerID == userId,
+		}
+		if group.OwnerID == userId {
+			result[i].JoinCode = group.JoinCode
+		}
+	}
+	return result
+}
+
+func ToGroupMemberResponses(members []entity.GroupMember) []GroupMemberResponse {
+	result := make([]GroupMemberResponse, len(members))
+	for i, member := range members {
+		result[i] = GroupMemberResponse{
+			UserID: member.UserID,
+			Login:  member.User.Login,
+			Name:   member.User.Name,
+			Avatar: member.User.Avatar,
+		}
+	}
+	return result
+}
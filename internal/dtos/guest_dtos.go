@@ -0,0 +1,23 @@
+package dtos
+
+import "time"
+
+type UpdateGuestAccessRequest struct {
+	AllowGuests bool   `json:"allow_guests"`
+	GuestInfo   string `json:"guest_info" validate:"required,oneof=none name email"`
+}
+
+type StartGuestRequest struct {
+	Name  string `json:"name" validate:"omitempty,max=100"`
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+type GuestSessionResponse struct {
+	Token     string    `json:"guest_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	TestID    uint      `json:"test_id"`
+}
+
+type ClaimGuestRequest struct {
+	GuestToken string `json:"guest_token" validate:"required"`
+}
@@ -0,0 +1,86 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/server/entity"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrganizationMemberResponse struct {
+	UserID uint    `json:"user_id"`
+	Login  string  `json:"login"`
+	Name   string  `json:"name"`
+	Email  string  `json:"email"`
+	Avatar *string `json:"avatar"`
+	Role   string  `json:"role"`
+}
+
+type ChangeMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin editor viewer"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+type AcceptInviteRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type OrganizationInviteResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func ToOrganizationResponses(organizations []entity.Organization) []OrganizationResponse {
+	result := make([]OrganizationResponse, len(organizations))
+	for i, organization := range organizations {
+		result[i] = OrganizationResponse{
+			ID:        organization.ID,
+			Name:      organization.Name,
+			CreatedAt: organization.CreatedAt,
+		}
+	}
+	return result
+}
+
+func ToOrganizationMemberResponses(members []entity.OrganizationMember) []OrganizationMemberResponse {
+	result := make([]OrganizationMemberResponse, len(members))
+	for i, member := range members {
+		result[i] = OrganizationMemberResponse{
+			UserID: member.UserID,
+			Login:  member.User.Login,
+			Name:   member.User.Name,
+			Email:  member.User.Email,
+			Avatar: member.User.Avatar,
+			Role:   member.Role,
+		}
+	}
+	return result
+}
+
+func ToOrganizationInviteResponses(invites []entity.OrganizationInvite) []OrganizationInviteResponse {
+	result := make([]OrganizationInviteResponse, len(invites))
+	for i, invite := range invites {
+		result[i] = OrganizationInviteResponse{
+			ID:        invite.ID,
+			Email:     invite.Email,
+			Role:      invite.Role,
+			ExpiresAt: invite.ExpiresAt,
+		}
+	}
+	return result
+}
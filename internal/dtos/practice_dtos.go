@@ -0,0 +1,45 @@
+package dtos
+
+import "time"
+
+type UpdatePracticeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type PracticeSettingsResponse struct {
+	TestID  uint `json:"test_id"`
+	Enabled bool `json:"enabled"`
+}
+
+// PracticeQuestion is a question to drill. DueAt is nil for a question the
+// user never practiced.
+type PracticeQuestion struct {
+	TestID      uint                `json:"test_id"`
+	TestName    string              `json:"test_name"`
+	Question    GetQuestionResponse `json:"question"`
+	Repetitions int                 `json:"repetitions"`
+	Lapses      int                 `json:"lapses"`
+	DueAt       *time.Time          `json:"due_at"`
+}
+
+// PracticeAnswerRequest marks the Selected variants. Quality rates the recall
+// from 0 to 5 as in SM-2; without it a right answer counts as 4 and a wrong
+// one as 1.
+type PracticeAnswerRequest struct {
+	Selected []uint `json:"selected" validate:"max=100"`
+	Quality  *int   `json:"quality" validate:"omitempty,gte=0,lte=5"`
+}
+
+// PracticeAnswerResponse is the feedback on a practice answer, as much of it
+// as the review policy of the test allows: Correct is nil under never, and
+// the correct variants and explanations are only filled in full.
+type PracticeAnswerResponse struct {
+	QuestionID   uint            `json:"question_id"`
+	Correct      *bool           `json:"correct"`
+	Explanation  string          `json:"explanation,omitempty"`
+	Variants     []ReviewVariant `json:"variants"`
+	Repetitions  int             `json:"repetitions"`
+	IntervalDays int             `json:"interval_days"`
+	EaseFactor   float64         `json:"ease_factor"`
+	DueAt        time.Time       `json:"due_at"`
+}
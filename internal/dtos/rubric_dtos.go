@@ -0,0 +1,50 @@
+package dtos
+
+import "github.com/server/entity"
+
+type RubricLevelInput struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description" validate:"max=1000"`
+	Points      float64 `json:"points" validate:"gte=0"`
+}
+
+type RubricCriterionInput struct {
+	Name        string             `json:"name" validate:"required,max=100"`
+	Description string             `json:"description" validate:"max=1000"`
+	Levels      []RubricLevelInput `json:"levels" validate:"required,min=1,dive"`
+}
+
+type UpdateRubricRequest struct {
+	Criteria []RubricCriterionInput `json:"criteria" validate:"dive"`
+}
+
+type RubricResponse struct {
+	QuestionID uint                     `json:"question_id"`
+	MaxPoints  float64                  `json:"max_points"`
+	Criteria   []entity.RubricCriterion `json:"criteria"`
+}
+
+type LevelCount struct {
+	LevelID uint   `json:"level_id"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+}
+
+type CriterionAnalytics struct {
+	CriterionID   uint         `json:"criterion_id"`
+	Name          string       `json:"name"`
+	MaxPoints     float64      `json:"max_points"`
+	AveragePoints *float64     `json:"average_points"`
+	Levels        []LevelCount `json:"levels"`
+}
+
+type ItemAnalytics struct {
+	QuestionID    uint                 `json:"question_id"`
+	Name          string               `json:"name"`
+	Type          string               `json:"type"`
+	Answers       int                  `json:"answers"`
+	MaxPoints     float64              `json:"max_points"`
+	AveragePoints *float64             `json:"average_points"`
+	CorrectRate   *float64             `json:"correct_rate"`
+	Criteria      []CriterionAnalytics `json:"criteria,omitempty"`
+}
@@ -0,0 +1,62 @@
+package dtos
+
+import "github.com/server/entity"
+
+type SectionInput struct {
+	Name         string `json:"name" validate:"required,max=100"`
+	Instructions string `json:"instructions" validate:"max=5000"`
+	TimeLimit    int    `json:"time_limit" validate:"gte=0,lte=86400"`
+	AllowBack    bool   `json:"allow_back"`
+	QuestionIDs  []uint `json:"question_ids" validate:"required,min=1"`
+}
+
+// UpdateSectionsRequest lists the sections in the order they are taken. An
+// empty list removes the sections of the test.
+type UpdateSectionsRequest struct {
+	Sections []SectionInput `json:"sections" validate:"max=50,dive"`
+}
+
+type GetSectionResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Instructions string `json:"instructions"`
+	TimeLimit    int    `json:"time_limit"`
+	AllowBack    bool   `json:"allow_back"`
+}
+
+// EnterSectionRequest moves an attempt to SectionID with the answers given in
+// the section it leaves, in the shape they are submitted in.
+type EnterSectionRequest struct {
+	SectionID      uint                 `json:"section_id" validate:"required"`
+	Test           *entity.Test         `json:"test"`
+	TextAnswers    []TextAnswerInput    `json:"text_answers"`
+	CodeAnswers    []CodeAnswerInput    `json:"code_answers"`
+	NumericAnswers []NumericAnswerInput `json:"numeric_answers"`
+}
+
+type AttemptSectionState struct {
+	SectionID    uint   `json:"section_id"`
+	Name         string `json:"name"`
+	TimeLimit    int    `json:"time_limit"`
+	AllowBack    bool   `json:"allow_back"`
+	SpentSeconds int    `json:"spent_seconds"`
+	Current      bool   `json:"current"`
+	Closed       bool   `json:"closed"`
+}
+
+// SectionStateResponse is where an attempt is. TimeLeft is in seconds and nil
+// when the current section has no time limit.
+type SectionStateResponse struct {
+	AttemptID    uint                  `json:"attempt_id"`
+	SectionID    uint                  `json:"section_id"`
+	Name         string                `json:"name"`
+	Instructions string                `json:"instructions"`
+	TimeLeft     *int                  `json:"time_left"`
+	Sections     []AttemptSectionState `json:"sections"`
+}
+
+type SectionScore struct {
+	SectionID uint     `json:"section_id"`
+	Name      string   `json:"name"`
+	Score     *float64 `json:"score"`
+}
@@ -0,0 +1,20 @@
+package dtos
+
+import "github.com/server/pkg/similarity"
+
+type SimilarAnswer struct {
+	AnswerID  uint              `json:"answer_id"`
+	AttemptID uint              `json:"attempt_id"`
+	Login     string            `json:"login"`
+	Name      string            `json:"name"`
+	Text      string            `json:"text"`
+	Overlaps  []similarity.Span `json:"overlaps"`
+}
+
+type SimilarityPair struct {
+	QuestionID   uint          `json:"question_id"`
+	QuestionName string        `json:"question_name"`
+	Similarity   float64       `json:"similarity"`
+	First        SimilarAnswer `json:"first"`
+	Second       SimilarAnswer `json:"second"`
+}
@@ -0,0 +1,30 @@
+package dtos
+
+import "github.com/server/entity"
+
+type AddCollaboratorRequest struct {
+	Login string `json:"login" validate:"required"`
+	Role  string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type CollaboratorResponse struct {
+	UserID uint    `json:"user_id"`
+	Login  string  `json:"login"`
+	Name   string  `json:"name"`
+	Avatar *string `json:"avatar"`
+	Role   string  `json:"role"`
+}
+
+func ToCollaboratorResponses(collaborators []entity.TestCollaborator) []CollaboratorResponse {
+	result := make([]CollaboratorResponse, len(collaborators))
+	for i, collaborator := range collaborators {
+		result[i] = CollaboratorResponse{
+			UserID: collaborator.UserID,
+			Login:  collaborator.User.Login,
+			Name:   collaborator.User.Name,
+			Avatar: collaborator.User.Avatar,
+			Role:   collaborator.Role,
+		}
+	}
+	return result
+}
@@ -1,22 +1,44 @@
 package dtos
 
-import "github.com/server/entity"
+import (
+	"time"
+
+	"github.com/server/entity"
+)
 
 type GetTestResponse struct {
-	ID            uint
-	Name          string                `json:"name"`
-	AuthorLogin   string                `json:"author_login"`
-	UserID        uint                  `json:"user_id"`
-	IsActive      bool                  `json:"is_active"`
-	CountUserPast uint                  `json:"count_user_past"`
-	Questions     []GetQuestionResponse `json:"questions"`
-	Role          string                `json:"user_role"`
+	ID              uint
+	Name            string                `json:"name"`
+	AuthorLogin     string                `json:"author_login"`
+	UserID          uint                  `json:"user_id"`
+	OrganizationID  *uint                 `json:"organization_id"`
+	IsActive        bool                  `json:"is_active"`
+	OpensAt         *time.Time            `json:"opens_at"`
+	ClosesAt        *time.Time            `json:"closes_at"`
+	MaxAttempts     int                   `json:"max_attempts"`
+	AttemptCooldown int                   `json:"attempt_cooldown"`
+	GradingPolicy   string                `json:"grading_policy"`
+	AllowGuests     bool                  `json:"allow_guests"`
+	GuestInfo       string                `json:"guest_info"`
+	PassMark        *float64              `json:"pass_mark"`
+	ReviewPolicy    string                `json:"review_policy"`
+	Adaptive        bool                  `json:"adaptive"`
+	Practice        bool                  `json:"practice"`
+	CountUserPast   uint                  `json:"count_user_past"`
+	Questions       []GetQuestionResponse `json:"questions"`
+	Sections        []GetSectionResponse  `json:"sections"`
+	Role            string                `json:"user_role"`
 }
 
 type GetQuestionResponse struct {
 	ID          uint
 	Name        string               `json:"name"`
 	Description string               `json:"description"`
+	Type        string               `json:"type"`
+	MaxPoints   float64              `json:"max_points"`
+	Language    string               `json:"language,omitempty"`
+	Starter     string               `json:"starter,omitempty"`
+	SectionID   *uint                `json:"section_id,omitempty"`
 	Variants    []GetVariantResponse `json:"variants"`
 }
 
@@ -28,40 +50,74 @@ type GetVariantResponse struct {
 func MapTestToGetTestResponse(test *entity.Test, role string, userID uint) *GetTestResponse {
 	questions := make([]GetQuestionResponse, len(test.Questions))
 
-	for i, question := range test.Questions {
-		variants := make([]GetVariantResponse, len(question.Variants))
-		for j, variant := range question.Variants {
-			variants[j] = GetVariantResponse{
-				ID:   variant.ID,
-				Name: variant.Name,
-			}
-		}
+	for i := range test.Questions {
+		questions[i] = MapQuestionToGetQuestionResponse(&test.Questions[i])
+	}
 
-		questions[i] = GetQuestionResponse{
-			ID:          question.ID,
-			Name:        question.Name,
-			Description: question.Description,
-			Variants:    variants,
+	sections := make([]GetSectionResponse, len(test.Sections))
+	for i, section := range test.Sections {
+		sections[i] = GetSectionResponse{
+			ID:           section.ID,
+			Name:         section.Name,
+			Instructions: section.Instructions,
+			TimeLimit:    section.TimeLimit,
+			AllowBack:    section.AllowBack,
 		}
 	}
 
 	return &GetTestResponse{
-		ID:            test.ID,
-		Name:          test.Name,
-		AuthorLogin:   test.AuthorLogin,
-		UserID:        userID,
-		IsActive:      test.IsActive,
-		CountUserPast: test.CountUserPast,
-		Questions:     questions,
-		Role:          role,
+		ID:              test.ID,
+		Name:            test.Name,
+		AuthorLogin:     test.AuthorLogin,
+		UserID:          userID,
+		OrganizationID:  test.OrganizationID,
+		IsActive:        test.IsActive,
+		OpensAt:         test.OpensAt,
+		ClosesAt:        test.ClosesAt,
+		MaxAttempts:     test.MaxAttempts,
+		AttemptCooldown: test.AttemptCooldown,
+		GradingPolicy:   test.GradingPolicy,
+		AllowGuests:     test.AllowGuests,
+		GuestInfo:       test.GuestInfo,
+		PassMark:        test.PassMark,
+		ReviewPolicy:    test.ReviewPolicy,
+		Adaptive:        test.Adaptive,
+		Practice:        test.Practice,
+		CountUserPast:   test.CountUserPast,
+		Questions:       questions,
+		Sections:        sections,
+		Role:            role,
+	}
+}
+
+func MapQuestionToGetQuestionResponse(question *entity.Question) GetQuestionResponse {
+	variants := make([]GetVariantResponse, len(question.Variants))
+	for i, variant := range question.Variants {
+		variants[i] = GetVariantResponse{
+			ID:   variant.ID,
+			Name: variant.Name,
+		}
+	}
+
+	return GetQuestionResponse{
+		ID:          question.ID,
+		Name:        question.Name,
+		Description: question.Description,
+		Type:        question.Type,
+		MaxPoints:   question.MaxPoints,
+		Language:    question.Language,
+		Starter:     question.Starter,
+		SectionID:   question.SectionID,
+		Variants:    variants,
 	}
 }
 
 func MapCreateTestRequestToModel(req *CreateTestRequest, userId uint) entity.Test {
 	test := entity.Test{
-		Name:      req.Name,
-		UserID:    userId,
-		Questions: mapQuestions(req.Questions),
+		Name:           req.Name,
+		UserID:         userId,
+		OrganizationID: req.OrganizationID,
+		Questions:      mapQuestions(req.Questions),
 	}
 
 	return test
@@ -74,6 +130,15 @@ func mapQuestions(questions []CreateQuestionInput) []entity.Question {
 		mappedQuestions[i] = entity.Question{
 			Name:        question.Name,
 			Description: question.Description,
+			Explanation: question.Explanation,
+			Type:        question.Type,
+			MaxPoints:   question.MaxPoints,
+			Language:    question.Language,
+			Harness:     question.Harness,
+			Starter:     question.Starter,
+			AnswerExpr:  question.AnswerExpr,
+			Tolerance:   question.Tolerance,
+			Variables:   mapVariables(question.Variables),
 			Variants:    mapVariants(question.Variants),
 		}
 	}
@@ -81,13 +146,29 @@ func mapQuestions(questions []CreateQuestionInput) []entity.Question {
 	return mappedQuestions
 }
 
+func mapVariables(variables []CreateVariableInput) []entity.QuestionVariable {
+	mappedVariables := make([]entity.QuestionVariable, len(variables))
+
+	for i, variable := range variables {
+		mappedVariables[i] = entity.QuestionVariable{
+			Name:     variable.Name,
+			Min:      variable.Min,
+			Max:      variable.Max,
+			Decimals: variable.Decimals,
+		}
+	}
+
+	return mappedVariables
+}
+
 func mapVariants(variants []CreateVariantInput) []entity.Variant {
 	mappedVariants := make([]entity.Variant, len(variants))
 
 	for i, variant := range variants {
 		mappedVariants[i] = entity.Variant{
-			Name:      variant.Name,
-			IsCorrect: variant.IsCorrect,
+			Name:        variant.Name,
+			IsCorrect:   variant.IsCorrect,
+			Explanation: variant.Explanation,
 		}
 	}
 
@@ -147,22 +228,56 @@ type GetTestByIdResponse struct {
 }
 
 type CreateTestRequest struct {
-	Name      string                `json:"name" validate:"required"`
-	Questions []CreateQuestionInput `json:"questions" validate:"required"`
+	Name           string                `json:"name" validate:"required"`
+	OrganizationID *uint                 `json:"organization_id"`
+	Questions      []CreateQuestionInput `json:"questions" validate:"required,dive"`
 }
 
 type CreateQuestionInput struct {
-	Name        string               `json:"name" validate:"required"`
-	Description string               `json:"description" validate:"required"`
-	Variants    []CreateVariantInput `json:"variants"`
+	Name        string                `json:"name" validate:"required"`
+	Description string                `json:"description" validate:"required"`
+	Explanation string                `json:"explanation"`
+	Type        string                `json:"type" validate:"omitempty,oneof=choice text code numeric"`
+	MaxPoints   float64               `json:"max_points" validate:"gte=0"`
+	Language    string                `json:"language" validate:"max=20"`
+	Harness     string                `json:"harness" validate:"max=65536"`
+	Starter     string                `json:"starter" validate:"max=65536"`
+	AnswerExpr  string                `json:"answer_expr" validate:"max=1000"`
+	Tolerance   float64               `json:"tolerance" validate:"gte=0"`
+	Variables   []CreateVariableInput `json:"variables" validate:"dive"`
+	Variants    []CreateVariantInput  `json:"variants" validate:"dive"`
+}
+
+type CreateVariableInput struct {
+	Name     string  `json:"name" validate:"required,max=50"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max" validate:"gtefield=Min"`
+	Decimals int     `json:"decimals" validate:"gte=0,lte=10"`
 }
 
 type CreateVariantInput struct {
-	Name      string `json:"name" validate:"required"`
-	IsCorrect bool   `json:"is_correct" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	IsCorrect   bool   `json:"is_correct"`
+	Explanation string `json:"explanation"`
 }
 
 type UpdateTestActiveStatus struct {
 	TestId   uint `json:"test_id" validate:"required"`
 	IsActive bool `json:"is_active"`
 }
+
+type UpdateTestWindowRequest struct {
+	OpensAt  *time.Time `json:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+type ScheduleChangeRequest struct {
+	Action string    `json:"action" validate:"required,oneof=publish unpublish"`
+	RunAt  time.Time `json:"run_at" validate:"required"`
+}
+
+type UpdateAttemptPolicyRequest struct {
+	MaxAttempts     int    `json:"max_attempts" validate:"gte=0"`
+	AttemptCooldown int    `json:"attempt_cooldown" validate:"gte=0"`
+	GradingPolicy   string `json:"grading_policy" validate:"required,oneof=best last first average"`
+}
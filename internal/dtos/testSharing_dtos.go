@@ -0,0 +1,66 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/server/entity"
+)
+
+type UpdateTestAccessRequest struct {
+	Mode       string `json:"mode" validate:"required,oneof=public link code invite"`
+	AccessCode string `json:"access_code" validate:"omitempty,min=8"`
+}
+
+type TestAccessResponse struct {
+	Mode          string  `json:"mode"`
+	Slug          *string `json:"slug"`
+	HasAccessCode bool    `json:"has_access_code"`
+}
+
+type UpdateTestInviteesRequest struct {
+	Invitees []string `json:"invitees" validate:"dive,required"`
+}
+
+type CreateTestInviteLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"gte=0"`
+	MaxUses        int `json:"max_uses" validate:"gte=0"`
+}
+
+type TestInviteLinkResponse struct {
+	ID        uint       `json:"id"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+type RedeemTestInviteRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockTestRequest struct {
+	AccessCode string `json:"access_code" validate:"required"`
+}
+
+type OpenTestResponse struct {
+	TestID uint `json:"test_id"`
+}
+
+func ToTestAccessResponse(test *entity.Test) TestAccessResponse {
+	return TestAccessResponse{
+		Mode:          test.AccessMode,
+		Slug:          test.Slug,
+		HasAccessCode: test.AccessCodeHash != "",
+	}
+}
+
+func ToTestInviteLinkResponse(link *entity.TestInviteLink) TestInviteLinkResponse {
+	return TestInviteLinkResponse{
+		ID:        link.ID,
+		ExpiresAt: link.ExpiresAt,
+		MaxUses:   link.MaxUses,
+		Uses:      link.Uses,
+		RevokedAt: link.RevokedAt,
+	}
+}
@@ -1,14 +1,20 @@
 package dtos
 
-import "github.com/server/entity"
+import (
+	"time"
+
+	"github.com/server/entity"
+)
 
 func ToGetUserByLoginResponse(user *entity.User) GetUserByLoginResponse {
 	return GetUserByLoginResponse{
-		Login:  user.Login,
-		Name:   user.Name,
-		Avatar: user.Avatar,
-		Id:     user.ID,
-		Email:  user.Email,
+		Login:         user.Login,
+		Name:          user.Name,
+		Avatar:        user.Avatar,
+		Id:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
 	}
 }
 
@@ -25,9 +31,48 @@ type GetUserByLoginRequest struct {
 }
 
 type GetUserByLoginResponse struct {
-	Login  string  `json:"login"`
-	Email  string  `json:"email"`
-	Id     uint    `json:"id"`
-	Name   string  `json:"name"`
-	Avatar *string `json:"avatar"`
+	Login         string  `json:"login"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"email_verified"`
+	Id            uint    `json:"id"`
+	Name          string  `json:"name"`
+	Avatar        *string `json:"avatar"`
+	Role          string  `json:"role"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func ToSessionResponses(sessions []entity.Session, currentSessionID uint) []SessionResponse {
+	result := make([]SessionResponse, len(sessions))
+
+	for i, session := range sessions {
+		result[i] = SessionResponse{
+			ID:           session.ID,
+			UserAgent:    session.UserAgent,
+			IP:           session.IP,
+			CreatedAt:    session.CreatedAt,
+			LastSeenAt:   session.LastSeenAt,
+			Current:      session.ID == currentSessionID,
+			Impersonated: session.ImpersonatorID != nil,
+		}
+	}
+
+	return result
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+	// Impersonated marks sessions opened by an admin on behalf of the user.
+	Impersonated bool `json:"impersonated"`
 }
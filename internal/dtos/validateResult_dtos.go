@@ -3,5 +3,30 @@ package dtos
 import "github.com/server/entity"
 
 type ValidateResultRequestPayload struct {
-	Test *entity.Test `json:"test" validate:"required"`
+	Test           *entity.Test         `json:"test" validate:"required"`
+	AttemptID      *uint                `json:"attempt_id"`
+	AssignmentID   *uint                `json:"assignment_id"`
+	TextAnswers    []TextAnswerInput    `json:"text_answers"`
+	CodeAnswers    []CodeAnswerInput    `json:"code_answers"`
+	NumericAnswers []NumericAnswerInput `json:"numeric_answers"`
+}
+
+type TextAnswerInput struct {
+	QuestionID uint   `json:"question_id"`
+	Text       string `json:"text"`
+}
+
+type NumericAnswerInput struct {
+	QuestionID uint     `json:"question_id"`
+	Value      *float64 `json:"value"`
+}
+
+type ValidateResultResponse struct {
+	AttemptID     uint           `json:"attempt_id"`
+	PendingReview bool           `json:"pending_review"`
+	Score         *float64       `json:"score"`
+	Passed        *bool          `json:"passed"`
+	Band          string         `json:"band"`
+	Feedback      string         `json:"feedback"`
+	Sections      []SectionScore `json:"sections,omitempty"`
 }
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Adaptive struct {
+	db *gorm.DB
+}
+
+func NewAdaptive(db *gorm.DB) *Adaptive {
+	return &Adaptive{
+		db: db,
+	}
+}
+
+func (s *Adaptive) GetAdaptiveTestIds() ([]uint, error) {
+	var ids []uint
+
+	if err := s.db.Model(&entity.Test{}).Where("adaptive = ?", true).Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("GetAdaptiveTestIds: failed to get tests: %w", err)
+	}
+
+	return ids, nil
+}
+
+// UpdateItemParameters stores fitted difficulty and discrimination of the
+// given questions.
+func (s *Adaptive) UpdateItemParameters(questions []entity.Question, calibratedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, question := range questions {
+			if err := tx.Model(&entity.Question{}).Where("id = ?", question.ID).Updates(map[string]interface{}{
+				"difficulty":     question.Difficulty,
+				"discrimination": question.Discrimination,
+				"calibrated_at":  calibratedAt,
+			}).Error; err != nil {
+				return fmt.Errorf("UpdateItemParameters: failed to update question %d: %w", question.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveAdaptiveStep stores the answer to the last asked question with the new
+// ability estimate and the question asked next. With submittedAt the attempt
+// is finished and nothing is asked next. An attempt that is already submitted,
+// or whose current question is no longer currentQuestionId because a
+// concurrent request took the step first, is not changed.
+func (s *Adaptive) SaveAdaptiveStep(attemptId uint, currentQuestionId *uint, answers []entity.AttemptAnswer, ability float64, abilitySE float64, nextQuestionId *uint, submittedAt *time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"ability":             ability,
+			"ability_se":          abilitySE,
+			"current_question_id": nextQuestionId,
+		}
+		if submittedAt != nil {
+			updates["submitted_at"] = *submittedAt
+		}
+
+		query := tx.Model(&entity.Attempt{}).Where("id = ? AND submitted_at IS NULL", attemptId)
+		if currentQuestionId != nil {
+			query = query.Where("current_question_id = ?", *currentQuestionId)
+		} else {
+			query = query.Where("current_question_id IS NULL")
+		}
+
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("SaveAdaptiveStep: failed to update attempt: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("SaveAdaptiveStep: %w", gorm.ErrRecordNotFound)
+		}
+
+		if len(answers) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&answers).Error; err != nil {
+			return fmt.Errorf("SaveAdaptiveStep: failed to save answers: %w", err)
+		}
+
+		return nil
+	})
+}
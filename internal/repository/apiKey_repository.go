@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type APIKey struct {
+	db *gorm.DB
+}
+
+func NewAPIKey(db *gorm.DB) *APIKey {
+	return &APIKey{
+		db: db,
+	}
+}
+
+func (s *APIKey) CreateAPIKey(key *entity.APIKey) error {
+	if err := s.db.Create(key).Error; err != nil {
+		return fmt.Errorf("CreateAPIKey: failed to create api key: %w", err)
+	}
+	return nil
+}
+
+func (s *APIKey) GetAPIKeysByUserId(userId uint) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+
+	if err := s.db.Where("user_id = ?", userId).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("GetAPIKeysByUserId: failed to get api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *APIKey) GetAPIKeyByHash(hash string) (*entity.APIKey, error) {
+	var key entity.APIKey
+
+	if err := s.db.First(&key, "key_hash = ?", hash).Error; err != nil {
+		return nil, fmt.Errorf("GetAPIKeyByHash: failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (s *APIKey) TouchAPIKey(id uint, lastUsedAt time.Time) error {
+	if err := s.db.Model(&entity.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", lastUsedAt).Error; err != nil {
+		return fmt.Errorf("TouchAPIKey: failed to update last used time: %w", err)
+	}
+	return nil
+}
+
+func (s *APIKey) DeleteAPIKey(id uint, userId uint) error {
+	result := s.db.Unscoped().Where("id = ? AND user_id = ?", id, userId).Delete(&entity.APIKey{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteAPIKey: failed to delete api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteAPIKey: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Assignment struct {
+	db *gorm.DB
+}
+
+func NewAssignment(db *gorm.DB) *Assignment {
+	return &Assignment{
+		db: db,
+	}
+}
+
+func (s *Assignment) CreateAssignment(assignment *entity.Assignment) error {
+	if err := s.db.Create(assignment).Error; err != nil {
+		return fmt.Errorf("CreateAssignment: failed to create assignment: %w", err)
+	}
+	return nil
+}
+
+func (s *Assignment) GetAssignmentById(id uint) (*entity.Assignment, error) {
+	var assignment entity.Assignment
+
+	if err := s.db.Preload("Test", selectTestSummary).First(&assignment, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetAssignmentById: failed to get assignment: %w", err)
+	}
+
+	return &assignment, nil
+}
+
+func (s *Assignment) GetAssignmentsByGroupId(groupId uint) ([]entity.Assignment, error) {
+	var assignments []entity.Assignment
+
+	if err := s.db.Preload("Test", selectTestSummary).
+		Where("group_id = ?", groupId).
+		Order("opens_at ASC").
+		Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("GetAssignmentsByGroupId: failed to get assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func (s *Assignment) GetAssignmentsByUserId(userId uint) ([]entity.Assignment, error) {
+	var assignments []entity.Assignment
+
+	if err := s.db.Preload("Test", selectTestSummary).
+		Where("group_id IN (?)", s.db.Model(&entity.GroupMember{}).Select("group_id").Where("user_id = ?", userId)).
+		Order("opens_at ASC").
+		Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("GetAssignmentsByUserId: failed to get assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func (s *Assignment) HasUserAssignment(userId uint, testId uint) (bool, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.Assignment{}).
+		Where("test_id = ?", testId).
+		Where("group_id IN (?)", s.db.Model(&entity.GroupMember{}).Select("group_id").Where("user_id = ?", userId)).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("HasUserAssignment: failed to count assignments: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *Assignment) DeleteAssignment(id uint) error {
+	if err := s.db.Delete(&entity.Assignment{}, id).Error; err != nil {
+		return fmt.Errorf("DeleteAssignment: failed to delete assignment: %w", err)
+	}
+	return nil
+}
+
+func selectTestSummary(db *gorm.DB) *gorm.DB {
+	return db.Select("id, name, author_login, user_id, is_active, grading_policy")
+}
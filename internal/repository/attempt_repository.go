@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the postgres error code of a unique index conflict.
+const uniqueViolation = "23505"
+
+type Attempt struct {
+	db *gorm.DB
+}
+
+func NewAttempt(db *gorm.DB) *Attempt {
+	return &Attempt{
+		db: db,
+	}
+}
+
+// CreateAttempt fails with gorm.ErrDuplicatedKey when the user already has an
+// open attempt for the test and assignment: the idx_attempts_open index allows
+// only one at a time.
+func (s *Attempt) CreateAttempt(attempt *entity.Attempt) error {
+	if err := s.db.Create(attempt).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return fmt.Errorf("CreateAttempt: open attempt already exists: %w", gorm.ErrDuplicatedKey)
+		}
+		return fmt.Errorf("CreateAttempt: failed to create attempt: %w", err)
+	}
+	return nil
+}
+
+func (s *Attempt) GetAttemptById(id uint) (*entity.Attempt, error) {
+	var attempt entity.Attempt
+
+	if err := s.db.Preload("Variables").Preload("Sections").First(&attempt, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetAttemptById: failed to get attempt: %w", err)
+	}
+
+	return &attempt, nil
+}
+
+// GetOpenAttempt returns the started but not yet submitted attempt of the user.
+func (s *Attempt) GetOpenAttempt(userId uint, testId uint, assignmentId *uint) (*entity.Attempt, error) {
+	var attempt entity.Attempt
+
+	query := s.db.Where("user_id = ? AND test_id = ? AND submitted_at IS NULL", userId, testId)
+	if assignmentId != nil {
+		query = query.Where("assignment_id = ?", *assignmentId)
+	} else {
+		query = query.Where("assignment_id IS NULL")
+	}
+
+	if err := query.Preload("Variables").Preload("Sections").Order("started_at DESC").First(&attempt).Error; err != nil {
+		return nil, fmt.Errorf("GetOpenAttempt: failed to get attempt: %w", err)
+	}
+
+	return &attempt, nil
+}
+
+func (s *Attempt) GetLastSubmittedAttempt(userId uint, testId uint) (*entity.Attempt, error) {
+	var attempt entity.Attempt
+
+	if err := s.db.Where("user_id = ? AND test_id = ? AND submitted_at IS NOT NULL", userId, testId).
+		Order("submitted_at DESC").
+		First(&attempt).Error; err != nil {
+		return nil, fmt.Errorf("GetLastSubmittedAttempt: failed to get attempt: %w", err)
+	}
+
+	return &attempt, nil
+}
+
+func (s *Attempt) CountTestAttempts(userId uint, testId uint) (int64, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.Attempt{}).
+		Where("user_id = ? AND test_id = ?", userId, testId).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("CountTestAttempts: failed to count attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountOpenAttempts counts the started but not yet submitted attempts of the
+// user on the test, with and without an assignment.
+func (s *Attempt) CountOpenAttempts(userId uint, testId uint) (int64, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.Attempt{}).
+		Where("user_id = ? AND test_id = ? AND submitted_at IS NULL", userId, testId).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("CountOpenAttempts: failed to count attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// FinishAttempt stores the score and answers of an open attempt at once, so a
+// submitted attempt always has its answers. An attempt can be submitted only
+// once. The answers replace those stored for the same questions when a
+// section was left.
+func (s *Attempt) FinishAttempt(id uint, score *float64, grade entity.AttemptGrade, answers []entity.AttemptAnswer, pendingReview bool, submittedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Attempt{}).
+			Where("id = ? AND submitted_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"score":          score,
+				"passed":         grade.Passed,
+				"band":           grade.Band,
+				"feedback":       grade.Feedback,
+				"pending_review": pendingReview,
+				"submitted_at":   submittedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("FinishAttempt: failed to update attempt: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("FinishAttempt: %w", gorm.ErrRecordNotFound)
+		}
+
+		if len(answers) == 0 {
+			return nil
+		}
+
+		questionIds := make([]uint, len(answers))
+		for i, answer := range answers {
+			questionIds[i] = answer.QuestionID
+		}
+
+		if err := tx.Unscoped().Where("attempt_id = ? AND question_id IN ?", id, questionIds).Delete(&entity.AttemptAnswer{}).Error; err != nil {
+			return fmt.Errorf("FinishAttempt: failed to delete replaced answers: %w", err)
+		}
+
+		if err := tx.Create(&answers).Error; err != nil {
+			return fmt.Errorf("FinishAttempt: failed to save answers: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Attempt) GetAttemptAnswers(attemptId uint) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	if err := s.db.Preload("CaseResults").Where("attempt_id = ?", attemptId).Order("id ASC").Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetAttemptAnswers: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+func (s *Attempt) GetAnswersByIds(ids []uint) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	if err := s.db.Preload("Attempt").Where("id IN ?", ids).Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetAnswersByIds: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// GetTestAnswers returns the answers of all submitted attempts of a test with
+// their rubric scores.
+func (s *Attempt) GetTestAnswers(testId uint) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	if err := s.db.Preload("CriterionScores").
+		Joins("JOIN attempts ON attempts.id = attempt_answers.attempt_id AND attempts.deleted_at IS NULL").
+		Where("attempts.test_id = ? AND attempts.submitted_at IS NOT NULL", testId).
+		Order("attempt_answers.attempt_id ASC, attempt_answers.id ASC").
+		Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetTestAnswers: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// GetUngradedAnswers returns text and code answers of attempts waiting for
+// review, oldest submissions first. questionId narrows the queue to one
+// question.
+func (s *Attempt) GetUngradedAnswers(testId uint, questionId *uint) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	query := s.db.Preload("Attempt").
+		Joins("JOIN attempts ON attempts.id = attempt_answers.attempt_id AND attempts.deleted_at IS NULL").
+		Where("attempts.test_id = ? AND attempts.pending_review = ? AND attempt_answers.points IS NULL", testId, true)
+	if questionId != nil {
+		query = query.Where("attempt_answers.question_id = ?", *questionId)
+	}
+
+	if err := query.Order("attempts.submitted_at ASC, attempt_answers.id ASC").Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetUngradedAnswers: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// GradeAnswers stores points, comments and rubric scores given by a grader.
+// Answers of attempts that are already finalized are not changed.
+func (s *Attempt) GradeAnswers(answers []entity.AttemptAnswer, gradedById uint, gradedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, answer := range answers {
+			result := tx.Model(&entity.AttemptAnswer{}).
+				Where("id = ? AND attempt_id IN (?)", answer.ID,
+					tx.Model(&entity.Attempt{}).Select("id").Where("pending_review = ?", true)).
+				Updates(map[string]interface{}{
+					"points":       answer.Points,
+					"comment":      answer.Comment,
+					"graded_by_id": gradedById,
+					"graded_at":    gradedAt,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("GradeAnswers: failed to grade answer %d: %w", answer.ID, result.Error)
+			}
+
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("GradeAnswers: answer %d: %w", answer.ID, gorm.ErrRecordNotFound)
+			}
+
+			if err := tx.Unscoped().Where("answer_id = ?", answer.ID).Delete(&entity.AnswerCriterionScore{}).Error; err != nil {
+				return fmt.Errorf("GradeAnswers: failed to delete criterion scores of answer %d: %w", answer.ID, err)
+			}
+
+			if len(answer.CriterionScores) == 0 {
+				continue
+			}
+
+			for i := range answer.CriterionScores {
+				answer.CriterionScores[i].AnswerID = answer.ID
+			}
+			if err := tx.Create(&answer.CriterionScores).Error; err != nil {
+				return fmt.Errorf("GradeAnswers: failed to save criterion scores of answer %d: %w", answer.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// FinalizeAttempt sets the score of an attempt waiting for review.
+func (s *Attempt) FinalizeAttempt(id uint, score *float64, grade entity.AttemptGrade) error {
+	result := s.db.Model(&entity.Attempt{}).
+		Where("id = ? AND pending_review = ?", id, true).
+		Updates(map[string]interface{}{
+			"score":          score,
+			"passed":         grade.Passed,
+			"band":           grade.Band,
+			"feedback":       grade.Feedback,
+			"pending_review": false,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("FinalizeAttempt: failed to update attempt: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("FinalizeAttempt: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Attempt) UpdateAttemptGrades(grades map[uint]entity.AttemptGrade) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for id, grade := range grades {
+			if err := tx.Model(&entity.Attempt{}).
+				Where("id = ?", id).
+				Updates(map[string]interface{}{
+					"passed":   grade.Passed,
+					"band":     grade.Band,
+					"feedback": grade.Feedback,
+				}).Error; err != nil {
+				return fmt.Errorf("UpdateAttemptGrades: failed to update attempt %d: %w", id, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Attempt) GetSubmittedAttemptsByTestId(testId uint) ([]entity.Attempt, error) {
+	var attempts []entity.Attempt
+
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, login, name")
+	}).
+		Where("test_id = ? AND submitted_at IS NOT NULL", testId).
+		Order("submitted_at ASC").
+		Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("GetSubmittedAttemptsByTestId: failed to get attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (s *Attempt) CountAssignmentAttempts(userId uint, assignmentId uint) (int64, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.Attempt{}).
+		Where("user_id = ? AND assignment_id = ?", userId, assignmentId).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("CountAssignmentAttempts: failed to count attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Attempt) GetAttemptsByAssignmentId(assignmentId uint) ([]entity.Attempt, error) {
+	var attempts []entity.Attempt
+
+	if err := s.db.Where("assignment_id = ?", assignmentId).
+		Order("started_at ASC").
+		Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("GetAttemptsByAssignmentId: failed to get attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (s *Attempt) GetUserAttemptsByAssignmentIds(userId uint, assignmentIds []uint) ([]entity.Attempt, error) {
+	var attempts []entity.Attempt
+	if len(assignmentIds) == 0 {
+		return attempts, nil
+	}
+
+	if err := s.db.Where("user_id = ? AND assignment_id IN ?", userId, assignmentIds).
+		Order("started_at ASC").
+		Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("GetUserAttemptsByAssignmentIds: failed to get attempts: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type AuditLog struct {
+	db *gorm.DB
+}
+
+func NewAuditLog(db *gorm.DB) *AuditLog {
+	return &AuditLog{
+		db: db,
+	}
+}
+
+func (s *AuditLog) CreateAuditLog(entry *entity.AuditLog) error {
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("CreateAuditLog: failed to create audit log entry: %w", err)
+	}
+	return nil
+}
+
+func (s *AuditLog) GetAuditLogs(action string, limit, offset int) ([]entity.AuditLog, int64, error) {
+	var entries []entity.AuditLog
+	db := s.db.Model(&entity.AuditLog{})
+	if action != "" {
+		db = db.Where("action = ?", action)
+	}
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("GetAuditLogs: failed to count audit log entries: %w", err)
+	}
+
+	if err := db.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("GetAuditLogs: failed to get audit log entries: %w", err)
+	}
+
+	return entries, count, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type CodeQuestion struct {
+	db *gorm.DB
+}
+
+func NewCodeQuestion(db *gorm.DB) *CodeQuestion {
+	return &CodeQuestion{
+		db: db,
+	}
+}
+
+func (s *CodeQuestion) GetQuestionById(id uint) (*entity.Question, error) {
+	var question entity.Question
+
+	if err := s.db.Preload("TestCases", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).
+		First(&question, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetQuestionById: failed to get question: %w", err)
+	}
+
+	return &question, nil
+}
+
+// ReplaceTestCases swaps the test cases of a code question together with its
+// language, harness and starter. MaxPoints becomes what the cases are worth.
+func (s *CodeQuestion) ReplaceTestCases(question *entity.Question, cases []entity.CodeTestCase) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("question_id = ?", question.ID).Delete(&entity.CodeTestCase{}).Error; err != nil {
+			return fmt.Errorf("ReplaceTestCases: failed to delete test cases: %w", err)
+		}
+
+		if len(cases) > 0 {
+			if err := tx.Create(&cases).Error; err != nil {
+				return fmt.Errorf("ReplaceTestCases: failed to create test cases: %w", err)
+			}
+		}
+
+		if err := tx.Model(&entity.Question{}).Where("id = ?", question.ID).Updates(map[string]interface{}{
+			"language":   question.Language,
+			"harness":    question.Harness,
+			"starter":    question.Starter,
+			"max_points": question.MaxPoints,
+		}).Error; err != nil {
+			return fmt.Errorf("ReplaceTestCases: failed to update question: %w", err)
+		}
+
+		return nil
+	})
+}
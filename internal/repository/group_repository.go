@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Group struct {
+	db *gorm.DB
+}
+
+func NewGroup(db *gorm.DB) *Group {
+	return &Group{
+		db: db,
+	}
+}
+
+func (s *Group) CreateGroup(group *entity.Group) error {
+	if err := s.db.Create(group).Error; err != nil {
+		return fmt.Errorf("CreateGroup: failed to create group: %w", err)
+	}
+	return nil
+}
+
+func (s *Group) GetGroupById(id uint) (*entity.Group, error) {
+	var group entity.Group
+
+	if err := s.db.First(&group, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetGroupById: failed to get group: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (s *Group) GetGroupByJoinCode(code string) (*entity.Group, error) {
+	var group entity.Group
+
+	if err := s.db.Where("join_code = ?", code).First(&group).Error; err != nil {
+		return nil, fmt.Errorf("GetGroupByJoinCode: failed to get group: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (s *Group) GetGroupsByUserId(userId uint) ([]entity.Group, error) {
+	var groups []entity.Group
+
+	if err := s.db.Where("owner_id = ? OR id IN (?)", userId,
+		s.db.Model(&entity.GroupMember{}).Select("group_id").Where("user_id = ?", userId)).
+		Order("id ASC").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("GetGroupsByUserId: failed to get groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (s *Group) UpdateJoinCode(groupId uint, code string) error {
+	if err := s.db.Model(&entity.Group{}).
+		Where("id = ?", groupId).
+		Update("join_code", code).Error; err != nil {
+		return fmt.Errorf("UpdateJoinCode: failed to update join code: %w", err)
+	}
+	return nil
+}
+
+func (s *Group) DeleteGroup(id uint) error {
+	if err := s.db.Delete(&entity.Group{}, id).Error; err != nil {
+		return fmt.Errorf("DeleteGroup: failed to delete group: %w", err)
+	}
+	return nil
+}
+
+func (s *Group) AddMember(member *entity.GroupMember) error {
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error; err != nil {
+		return fmt.Errorf("AddMember: failed to add group member: %w", err)
+	}
+	return nil
+}
+
+func (s *Group) IsMember(groupId uint, userId uint) (bool, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupId, userId).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("IsMember: failed to check group member: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *Group) GetMembers(groupId uint) ([]entity.GroupMember, error) {
+	var members []entity.GroupMember
+
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, login, name, email, avatar")
+	}).
+		Where("group_id = ?", groupId).
+		Order("id ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("GetMembers: failed to get group members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *Group) DeleteMember(groupId uint, userId uint) error {
+	result := s.db.Unscoped().
+		Where("group_id = ? AND user_id = ?", groupId, userId).
+		Delete(&entity.GroupMember{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteMember: failed to delete group member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteMember: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Guest struct {
+	db *gorm.DB
+}
+
+func NewGuest(db *gorm.DB) *Guest {
+	return &Guest{
+		db: db,
+	}
+}
+
+func (s *Guest) CreateGuest(guest *entity.Guest) error {
+	if err := s.db.Create(guest).Error; err != nil {
+		return fmt.Errorf("CreateGuest: failed to create guest: %w", err)
+	}
+	return nil
+}
+
+func (s *Guest) GetGuestByUserId(userId uint) (*entity.Guest, error) {
+	var guest entity.Guest
+
+	if err := s.db.Where("user_id = ?", userId).First(&guest).Error; err != nil {
+		return nil, fmt.Errorf("GetGuestByUserId: failed to get guest: %w", err)
+	}
+
+	return &guest, nil
+}
+
+// ClaimGuest moves the guest's attempts to the claiming user and suspends the
+// placeholder user, so the guest token can not be used any more.
+func (s *Guest) ClaimGuest(guest *entity.Guest, userId uint, claimedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Guest{}).
+			Where("id = ? AND claimed_at IS NULL", guest.ID).
+			Updates(map[string]interface{}{
+				"claimed_by_id": userId,
+				"claimed_at":    claimedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("ClaimGuest: failed to update guest: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("ClaimGuest: %w", gorm.ErrRecordNotFound)
+		}
+
+		if err := tx.Model(&entity.Attempt{}).
+			Where("user_id = ?", guest.UserID).
+			Update("user_id", userId).Error; err != nil {
+			return fmt.Errorf("ClaimGuest: failed to move attempts: %w", err)
+		}
+
+		if err := tx.Model(&entity.User{}).
+			Where("id = ?", guest.UserID).
+			Update("suspended_at", claimedAt).Error; err != nil {
+			return fmt.Errorf("ClaimGuest: failed to suspend guest user: %w", err)
+		}
+
+		return nil
+	})
+}
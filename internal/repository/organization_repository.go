@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Organization struct {
+	db *gorm.DB
+}
+
+func NewOrganization(db *gorm.DB) *Organization {
+	return &Organization{
+		db: db,
+	}
+}
+
+func (s *Organization) CreateOrganization(organization *entity.Organization, ownerId uint, ownerRole string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return fmt.Errorf("CreateOrganization: failed to create organization: %w", err)
+		}
+
+		if err := tx.Create(&entity.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerId,
+			Role:           ownerRole,
+		}).Error; err != nil {
+			return fmt.Errorf("CreateOrganization: failed to add owner: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Organization) GetOrganizationById(id uint) (*entity.Organization, error) {
+	var organization entity.Organization
+
+	if err := s.db.First(&organization, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetOrganizationById: failed to get organization: %w", err)
+	}
+
+	return &organization, nil
+}
+
+func (s *Organization) GetOrganizationsByUserId(userId uint) ([]entity.Organization, error) {
+	var organizations []entity.Organization
+
+	if err := s.db.
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id AND organization_members.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userId).
+		Order("organizations.id ASC").
+		Find(&organizations).Error; err != nil {
+		return nil, fmt.Errorf("GetOrganizationsByUserId: failed to get organizations: %w", err)
+	}
+
+	return organizations, nil
+}
+
+func (s *Organization) GetMember(organizationId uint, userId uint) (*entity.OrganizationMember, error) {
+	var member entity.OrganizationMember
+
+	if err := s.db.Where("organization_id = ? AND user_id = ?", organizationId, userId).
+		First(&member).Error; err != nil {
+		return nil, fmt.Errorf("GetMember: failed to get organization member: %w", err)
+	}
+
+	return &member, nil
+}
+
+func (s *Organization) GetMembers(organizationId uint) ([]entity.OrganizationMember, error) {
+	var members []entity.OrganizationMember
+
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, login, name, email, avatar")
+	}).
+		Where("organization_id = ?", organizationId).
+		Order("id ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("GetMembers: failed to get organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *Organization) AddMember(member *entity.OrganizationMember) error {
+	if err := s.db.Create(member).Error; err != nil {
+		return fmt.Errorf("AddMember: failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+func (s *Organization) UpdateMemberRole(organizationId uint, userId uint, role string) error {
+	result := s.db.Model(&entity.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationId, userId).
+		Update("role", role)
+	if result.Error != nil {
+		return fmt.Errorf("UpdateMemberRole: failed to update member role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("UpdateMemberRole: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Organization) DeleteMember(organizationId uint, userId uint) error {
+	result := s.db.Unscoped().
+		Where("organization_id = ? AND user_id = ?", organizationId, userId).
+		Delete(&entity.OrganizationMember{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteMember: failed to delete member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteMember: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Organization) CountMembersByRole(organizationId uint, role string) (int64, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationId, role).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("CountMembersByRole: failed to count members: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Organization) CreateInvite(invite *entity.OrganizationInvite) error {
+	if err := s.db.Create(invite).Error; err != nil {
+		return fmt.Errorf("CreateInvite: failed to create invite: %w", err)
+	}
+	return nil
+}
+
+func (s *Organization) GetInviteByHash(hash string) (*entity.OrganizationInvite, error) {
+	var invite entity.OrganizationInvite
+
+	if err := s.db.Where("token_hash = ?", hash).First(&invite).Error; err != nil {
+		return nil, fmt.Errorf("GetInviteByHash: failed to get invite: %w", err)
+	}
+
+	return &invite, nil
+}
+
+func (s *Organization) GetPendingInvites(organizationId uint) ([]entity.OrganizationInvite, error) {
+	var invites []entity.OrganizationInvite
+
+	if err := s.db.Where("organization_id = ? AND accepted_at IS NULL AND expires_at > ?", organizationId, time.Now()).
+		Order("id DESC").
+		Find(&invites).Error; err != nil {
+		return nil, fmt.Errorf("GetPendingInvites: failed to get invites: %w", err)
+	}
+
+	return invites, nil
+}
+
+func (s *Organization) MarkInviteAccepted(id uint, acceptedAt time.Time) error {
+	result := s.db.Model(&entity.OrganizationInvite{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Update("accepted_at", acceptedAt)
+	if result.Error != nil {
+		return fmt.Errorf("MarkInviteAccepted: failed to accept invite: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("MarkInviteAccepted: invite already accepted")
+	}
+	return nil
+}
+
+func (s *Organization) DeleteInvite(id uint, organizationId uint) error {
+	result := s.db.Unscoped().
+		Where("id = ? AND organization_id = ?", id, organizationId).
+		Delete(&entity.OrganizationInvite{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteInvite: failed to delete invite: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteInvite: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type PasswordReset struct {
+	db *gorm.DB
+}
+
+func NewPasswordReset(db *gorm.DB) *PasswordReset {
+	return &PasswordReset{
+		db: db,
+	}
+}
+
+func (s *PasswordReset) CreateResetToken(token *entity.PasswordResetToken) error {
+	if err := s.db.Create(token).Error; err != nil {
+		return fmt.Errorf("CreateResetToken: failed to create reset token: %w", err)
+	}
+	return nil
+}
+
+func (s *PasswordReset) GetResetTokenByHash(hash string) (*entity.PasswordResetToken, error) {
+	var token entity.PasswordResetToken
+
+	if err := s.db.First(&token, "token_hash = ?", hash).Error; err != nil {
+		return nil, fmt.Errorf("GetResetTokenByHash: failed to get reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (s *PasswordReset) MarkResetTokenUsed(id uint, usedAt time.Time) error {
+	result := s.db.Model(&entity.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("MarkResetTokenUsed: failed to mark reset token as used: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("MarkResetTokenUsed: reset token already used")
+	}
+	return nil
+}
+
+func (s *PasswordReset) DeleteResetTokensByUserId(userId uint) error {
+	if err := s.db.Unscoped().Where("user_id = ? AND used_at IS NULL", userId).Delete(&entity.PasswordResetToken{}).Error; err != nil {
+		return fmt.Errorf("DeleteResetTokensByUserId: failed to delete reset tokens: %w", err)
+	}
+	return nil
+}
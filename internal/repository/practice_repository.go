@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Practice struct {
+	db *gorm.DB
+}
+
+func NewPractice(db *gorm.DB) *Practice {
+	return &Practice{
+		db: db,
+	}
+}
+
+func (s *Practice) GetQuestionById(id uint) (*entity.Question, error) {
+	var question entity.Question
+
+	if err := s.db.First(&question, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetQuestionById: failed to get question: %w", err)
+	}
+
+	return &question, nil
+}
+
+func (s *Practice) GetCard(userId uint, questionId uint) (*entity.PracticeCard, error) {
+	var card entity.PracticeCard
+
+	if err := s.db.Where("user_id = ? AND question_id = ?", userId, questionId).First(&card).Error; err != nil {
+		return nil, fmt.Errorf("GetCard: failed to get card: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (s *Practice) GetTestCards(userId uint, testId uint) ([]entity.PracticeCard, error) {
+	var cards []entity.PracticeCard
+
+	if err := s.db.Where("user_id = ? AND test_id = ?", userId, testId).Find(&cards).Error; err != nil {
+		return nil, fmt.Errorf("GetTestCards: failed to get cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// GetDueCards returns the cards of a user due before the given time, the
+// most overdue first.
+func (s *Practice) GetDueCards(userId uint, before time.Time) ([]entity.PracticeCard, error) {
+	var cards []entity.PracticeCard
+
+	if err := s.db.Where("user_id = ? AND due_at < ?", userId, before).
+		Order("due_at ASC, id ASC").
+		Find(&cards).Error; err != nil {
+		return nil, fmt.Errorf("GetDueCards: failed to get cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// SaveReview stores the rescheduled card together with the answer that
+// rescheduled it. A card without an id is created.
+func (s *Practice) SaveReview(card *entity.PracticeCard, review *entity.PracticeReview) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(card).Error; err != nil {
+			return fmt.Errorf("SaveReview: failed to save card: %w", err)
+		}
+
+		review.CardID = card.ID
+		if err := tx.Create(review).Error; err != nil {
+			return fmt.Errorf("SaveReview: failed to save review: %w", err)
+		}
+
+		return nil
+	})
+}
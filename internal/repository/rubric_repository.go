@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Rubric struct {
+	db *gorm.DB
+}
+
+func NewRubric(db *gorm.DB) *Rubric {
+	return &Rubric{
+		db: db,
+	}
+}
+
+func (s *Rubric) GetQuestionById(id uint) (*entity.Question, error) {
+	var question entity.Question
+
+	if err := s.db.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).
+		Preload("Criteria.Levels", func(db *gorm.DB) *gorm.DB {
+			return db.Order("points ASC")
+		}).
+		First(&question, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetQuestionById: failed to get question: %w", err)
+	}
+
+	return &question, nil
+}
+
+// ReplaceRubric swaps the criteria of a question and sets its MaxPoints to
+// what the new rubric can give. An empty rubric keeps the current MaxPoints.
+func (s *Rubric) ReplaceRubric(questionId uint, criteria []entity.RubricCriterion, maxPoints float64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		criterionIds := tx.Model(&entity.RubricCriterion{}).Select("id").Where("question_id = ?", questionId)
+		if err := tx.Unscoped().Where("criterion_id IN (?)", criterionIds).Delete(&entity.RubricLevel{}).Error; err != nil {
+			return fmt.Errorf("ReplaceRubric: failed to delete levels: %w", err)
+		}
+
+		if err := tx.Unscoped().Where("question_id = ?", questionId).Delete(&entity.RubricCriterion{}).Error; err != nil {
+			return fmt.Errorf("ReplaceRubric: failed to delete criteria: %w", err)
+		}
+
+		if len(criteria) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&criteria).Error; err != nil {
+			return fmt.Errorf("ReplaceRubric: failed to create criteria: %w", err)
+		}
+
+		if err := tx.Model(&entity.Question{}).Where("id = ?", questionId).Update("max_points", maxPoints).Error; err != nil {
+			return fmt.Errorf("ReplaceRubric: failed to update max points: %w", err)
+		}
+
+		return nil
+	})
+}
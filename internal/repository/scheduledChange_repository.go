@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type ScheduledChange struct {
+	db *gorm.DB
+}
+
+func NewScheduledChange(db *gorm.DB) *ScheduledChange {
+	return &ScheduledChange{
+		db: db,
+	}
+}
+
+func (s *ScheduledChange) CreateScheduledChange(change *entity.ScheduledChange) error {
+	if err := s.db.Create(change).Error; err != nil {
+		return fmt.Errorf("CreateScheduledChange: failed to create scheduled change: %w", err)
+	}
+	return nil
+}
+
+func (s *ScheduledChange) GetPendingChangesByTestId(testId uint) ([]entity.ScheduledChange, error) {
+	var changes []entity.ScheduledChange
+
+	if err := s.db.Where("test_id = ? AND applied_at IS NULL", testId).
+		Order("run_at ASC").
+		Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("GetPendingChangesByTestId: failed to get scheduled changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (s *ScheduledChange) GetDueChanges(now time.Time, limit int) ([]entity.ScheduledChange, error) {
+	var changes []entity.ScheduledChange
+
+	if err := s.db.Where("applied_at IS NULL AND run_at <= ?", now).
+		Order("run_at ASC").
+		Limit(limit).
+		Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("GetDueChanges: failed to get due changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// ApplyChange marks the change as applied and sets the active status of its
+// test in one transaction, and reports whether this call did it, so several
+// app instances never apply the same change twice and a failed change stays
+// pending.
+func (s *ScheduledChange) ApplyChange(id uint, testId uint, active bool, appliedAt time.Time) (bool, error) {
+	applied := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.ScheduledChange{}).
+			Where("id = ? AND applied_at IS NULL", id).
+			Update("applied_at", appliedAt)
+		if result.Error != nil {
+			return fmt.Errorf("ApplyChange: failed to claim scheduled change: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&entity.Test{}).
+			Where("id = ?", testId).
+			Update("is_active", active).Error; err != nil {
+			return fmt.Errorf("ApplyChange: failed to change active status test: %w", err)
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
+
+func (s *ScheduledChange) DeleteScheduledChange(id uint, testId uint) error {
+	result := s.db.Unscoped().
+		Where("id = ? AND test_id = ? AND applied_at IS NULL", id, testId).
+		Delete(&entity.ScheduledChange{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteScheduledChange: failed to delete scheduled change: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteScheduledChange: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type Section struct {
+	db *gorm.DB
+}
+
+func NewSection(db *gorm.DB) *Section {
+	return &Section{
+		db: db,
+	}
+}
+
+func (s *Section) CountOpenAttempts(testId uint) (int64, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.Attempt{}).Where("test_id = ? AND submitted_at IS NULL", testId).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("CountOpenAttempts: failed to count attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReplaceSections swaps the sections of a test. questionIds holds the
+// questions of every new section by its index.
+func (s *Section) ReplaceSections(testId uint, sections []entity.Section, questionIds [][]uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Question{}).Where("test_id = ?", testId).Update("section_id", nil).Error; err != nil {
+			return fmt.Errorf("ReplaceSections: failed to detach questions: %w", err)
+		}
+
+		if err := tx.Unscoped().Where("test_id = ?", testId).Delete(&entity.Section{}).Error; err != nil {
+			return fmt.Errorf("ReplaceSections: failed to delete sections: %w", err)
+		}
+
+		if len(sections) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&sections).Error; err != nil {
+			return fmt.Errorf("ReplaceSections: failed to create sections: %w", err)
+		}
+
+		for i, section := range sections {
+			if err := tx.Model(&entity.Question{}).
+				Where("test_id = ? AND id IN ?", testId, questionIds[i]).
+				Update("section_id", section.ID).Error; err != nil {
+				return fmt.Errorf("ReplaceSections: failed to attach questions: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// MoveAttempt puts an open attempt from one section into another. The
+// answers given in the section left replace those stored for questionIds
+// before. An attempt that has moved meanwhile is not found.
+func (s *Section) MoveAttempt(attemptId uint, fromSectionId uint, toSectionId uint, states []entity.AttemptSection, answers []entity.AttemptAnswer, questionIds []uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Attempt{}).
+			Where("id = ? AND submitted_at IS NULL AND current_section_id = ?", attemptId, fromSectionId).
+			Update("current_section_id", toSectionId)
+		if result.Error != nil {
+			return fmt.Errorf("MoveAttempt: failed to update attempt: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("MoveAttempt: %w", gorm.ErrRecordNotFound)
+		}
+
+		for i := range states {
+			if err := tx.Save(&states[i]).Error; err != nil {
+				return fmt.Errorf("MoveAttempt: failed to save section state: %w", err)
+			}
+		}
+
+		if len(questionIds) > 0 {
+			if err := tx.Unscoped().Where("attempt_id = ? AND question_id IN ?", attemptId, questionIds).Delete(&entity.AttemptAnswer{}).Error; err != nil {
+				return fmt.Errorf("MoveAttempt: failed to delete replaced answers: %w", err)
+			}
+		}
+
+		if len(answers) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&answers).Error; err != nil {
+			return fmt.Errorf("MoveAttempt: failed to save answers: %w", err)
+		}
+
+		return nil
+	})
+}
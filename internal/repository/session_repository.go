@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Session struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewSession(db *gorm.DB, logger *zap.Logger) *Session {
+	return &Session{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *Session) CreateSession(session *entity.Session) error {
+	if err := s.db.Create(session).Error; err != nil {
+		return fmt.Errorf("CreateSession: failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) GetSessionById(id uint) (*entity.Session, error) {
+	var session entity.Session
+
+	if err := s.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetSessionById: failed to get session by id: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (s *Session) GetSessionsByUserId(userId uint) ([]entity.Session, error) {
+	var sessions []entity.Session
+
+	if err := s.db.Where("user_id = ?", userId).Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("GetSessionsByUserId: failed to get user sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *Session) SaveRefreshToken(sessionId uint, refreshToken string) error {
+	if err := s.db.Model(&entity.Session{}).
+		Where("id = ?", sessionId).
+		Update("refresh_token", refreshToken).Error; err != nil {
+		return fmt.Errorf("SaveRefreshToken: failed to update session: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) TouchSession(sessionId uint, lastSeenAt time.Time) error {
+	if err := s.db.Model(&entity.Session{}).
+		Where("id = ?", sessionId).
+		Update("last_seen_at", lastSeenAt).Error; err != nil {
+		return fmt.Errorf("TouchSession: failed to update last seen time: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) DeleteSession(sessionId uint, userId uint) error {
+	result := s.db.Unscoped().Where("id = ? AND user_id = ?", sessionId, userId).Delete(&entity.Session{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteSession: failed to delete session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteSession: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Session) DeleteSessionsByUserId(userId uint) error {
+	if err := s.db.Unscoped().Where("user_id = ?", userId).Delete(&entity.Session{}).Error; err != nil {
+		return fmt.Errorf("DeleteSessionsByUserId: failed to delete user sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) DeleteOtherSessions(userId uint, keepSessionId uint) error {
+	if err := s.db.Unscoped().Where("user_id = ? AND id <> ?", userId, keepSessionId).Delete(&entity.Session{}).Error; err != nil {
+		return fmt.Errorf("DeleteOtherSessions: failed to delete user sessions: %w", err)
+	}
+	return nil
+}
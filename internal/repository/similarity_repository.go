@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/pkg/constants"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Similarity struct {
+	db *gorm.DB
+}
+
+func NewSimilarity(db *gorm.DB) *Similarity {
+	return &Similarity{
+		db: db,
+	}
+}
+
+// GetUncheckedAnswers returns submitted text answers that were not compared
+// with the other answers to their question yet.
+func (s *Similarity) GetUncheckedAnswers(limit int) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	if err := s.db.Preload("Attempt").
+		Joins("JOIN attempts ON attempts.id = attempt_answers.attempt_id AND attempts.deleted_at IS NULL").
+		Joins("JOIN questions ON questions.id = attempt_answers.question_id").
+		Where("attempts.submitted_at IS NOT NULL AND questions.type = ? AND attempt_answers.text <> '' AND attempt_answers.checked_at IS NULL", constants.QuestionText).
+		Order("attempt_answers.id ASC").
+		Limit(limit).
+		Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetUncheckedAnswers: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+func (s *Similarity) GetQuestionAnswers(questionId uint) ([]entity.AttemptAnswer, error) {
+	var answers []entity.AttemptAnswer
+
+	if err := s.db.Preload("Attempt").
+		Joins("JOIN attempts ON attempts.id = attempt_answers.attempt_id AND attempts.deleted_at IS NULL").
+		Where("attempts.submitted_at IS NOT NULL AND attempt_answers.question_id = ? AND attempt_answers.variant_id = 0 AND attempt_answers.text <> ''", questionId).
+		Find(&answers).Error; err != nil {
+		return nil, fmt.Errorf("GetQuestionAnswers: failed to get answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// SaveFlags stores flagged pairs and marks the compared answers as checked.
+// Pairs that are already flagged are kept as they are.
+func (s *Similarity) SaveFlags(flags []entity.SimilarityFlag, checkedIds []uint, checkedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(flags) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&flags).Error; err != nil {
+				return fmt.Errorf("SaveFlags: failed to create flags: %w", err)
+			}
+		}
+
+		if len(checkedIds) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&entity.AttemptAnswer{}).
+			Where("id IN ?", checkedIds).
+			Update("checked_at", checkedAt).Error; err != nil {
+			return fmt.Errorf("SaveFlags: failed to mark answers checked: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Similarity) GetFlagsByTestId(testId uint) ([]entity.SimilarityFlag, error) {
+	var flags []entity.SimilarityFlag
+
+	if err := s.db.Preload("FirstAnswer.Attempt.User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, login, name")
+	}).
+		Preload("SecondAnswer.Attempt.User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, login, name")
+		}).
+		Where("test_id = ?", testId).
+		Order("similarity DESC").
+		Find(&flags).Error; err != nil {
+		return nil, fmt.Errorf("GetFlagsByTestId: failed to get flags: %w", err)
+	}
+
+	return flags, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TestCollaborator struct {
+	db *gorm.DB
+}
+
+func NewTestCollaborator(db *gorm.DB) *TestCollaborator {
+	return &TestCollaborator{
+		db: db,
+	}
+}
+
+func (s *TestCollaborator) SaveCollaborator(collaborator *entity.TestCollaborator) error {
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "test_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "added_by_id", "updated_at"}),
+	}).Create(collaborator).Error; err != nil {
+		return fmt.Errorf("SaveCollaborator: failed to save collaborator: %w", err)
+	}
+	return nil
+}
+
+func (s *TestCollaborator) GetCollaborator(testId uint, userId uint) (*entity.TestCollaborator, error) {
+	var collaborator entity.TestCollaborator
+
+	if err := s.db.Where("test_id = ? AND user_id = ?", testId, userId).
+		First(&collaborator).Error; err != nil {
+		return nil, fmt.Errorf("GetCollaborator: failed to get collaborator: %w", err)
+	}
+
+	return &collaborator, nil
+}
+
+func (s *TestCollaborator) GetCollaborators(testId uint) ([]entity.TestCollaborator, error) {
+	var collaborators []entity.TestCollaborator
+
+	if err := s.db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, login, name, email, avatar")
+	}).
+		Where("test_id = ?", testId).
+		Order("id ASC").
+		Find(&collaborators).Error; err != nil {
+		return nil, fmt.Errorf("GetCollaborators: failed to get collaborators: %w", err)
+	}
+
+	return collaborators, nil
+}
+
+func (s *TestCollaborator) DeleteCollaborator(testId uint, userId uint) error {
+	result := s.db.Unscoped().
+		Where("test_id = ? AND user_id = ?", testId, userId).
+		Delete(&entity.TestCollaborator{})
+	if result.Error != nil {
+		return fmt.Errorf("DeleteCollaborator: failed to delete collaborator: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("DeleteCollaborator: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
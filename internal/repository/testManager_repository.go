@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/server/entity"
 	"gorm.io/gorm"
@@ -36,16 +37,59 @@ func (s *TestManager) GetAllTests(user_id uint, lastID, limit int) ([]entity.Tes
 	return tests, count, nil
 }
 
+func (s *TestManager) GetTestsByOrganizationId(organizationId uint, limit, offset int) ([]entity.Test, int64, error) {
+	var tests []entity.Test
+	query := s.db.Model(&entity.Test{}).Where("organization_id = ?", organizationId)
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("GetTestsByOrganizationId: failed to get count: %w", err)
+	}
+
+	if err := query.Order("id ASC").Limit(limit).Offset(offset).Find(&tests).Error; err != nil {
+		return nil, 0, fmt.Errorf("GetTestsByOrganizationId: failed to get tests: %w", err)
+	}
+
+	return tests, count, nil
+}
+
 func (s *TestManager) GetTestById(id uint) (*entity.Test, error) {
 	var test entity.Test
 
-	if err := s.db.Preload("Questions.Variants").First(&test, "id = ?", id).Error; err != nil {
+	if err := s.db.Preload("Questions.Variants").
+		Preload("Questions.Criteria", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Questions.Criteria.Levels", func(db *gorm.DB) *gorm.DB {
+			return db.Order("points ASC")
+		}).
+		Preload("Questions.TestCases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Questions.Variables").
+		Preload("GradeBands", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_score DESC")
+		}).
+		Preload("Sections", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		First(&test, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("GetTestById: failed to get test by id: %w", err)
 	}
 
 	return &test, nil
 }
 
+func (s *TestManager) GetTestBySlug(slug string) (*entity.Test, error) {
+	var test entity.Test
+
+	if err := s.db.Where("slug = ?", slug).First(&test).Error; err != nil {
+		return nil, fmt.Errorf("GetTestBySlug: failed to get test: %w", err)
+	}
+
+	return &test, nil
+}
+
 func (s *TestManager) CreateTest(data *entity.Test) error {
 	if err := s.db.Create(&data).Error; err != nil {
 		return fmt.Errorf("CreateTest: failed to create test: %w", err)
@@ -69,6 +113,120 @@ func (s *TestManager) ChangeActiveStatus(status bool, testId uint) error {
 	return nil
 }
 
+func (s *TestManager) UpdateWindow(testId uint, opensAt *time.Time, closesAt *time.Time) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{"opens_at": opensAt, "closes_at": closesAt}).Error; err != nil {
+		return fmt.Errorf("UpdateWindow: failed to update test window: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) UpdateAttemptPolicy(testId uint, maxAttempts int, cooldown int, gradingPolicy string) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{
+			"max_attempts":     maxAttempts,
+			"attempt_cooldown": cooldown,
+			"grading_policy":   gradingPolicy,
+		}).Error; err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: failed to update attempt policy: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) ReplaceGrading(testId uint, passMark *float64, bands []entity.GradeBand) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Test{}).
+			Where("id = ?", testId).
+			Update("pass_mark", passMark).Error; err != nil {
+			return fmt.Errorf("ReplaceGrading: failed to update pass mark: %w", err)
+		}
+
+		if err := tx.Unscoped().Where("test_id = ?", testId).Delete(&entity.GradeBand{}).Error; err != nil {
+			return fmt.Errorf("ReplaceGrading: failed to delete grade bands: %w", err)
+		}
+
+		if len(bands) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&bands).Error; err != nil {
+			return fmt.Errorf("ReplaceGrading: failed to create grade bands: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *TestManager) UpdateReviewPolicy(testId uint, policy string, release string) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{
+			"review_policy":      policy,
+			"review_release":     release,
+			"review_released_at": nil,
+		}).Error; err != nil {
+		return fmt.Errorf("UpdateReviewPolicy: failed to update review policy: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) UpdateAdaptiveSettings(testId uint, adaptive bool, model string, maxItems int, targetSE float64) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{
+			"adaptive":           adaptive,
+			"adaptive_model":     model,
+			"adaptive_max_items": maxItems,
+			"adaptive_target_se": targetSE,
+		}).Error; err != nil {
+		return fmt.Errorf("UpdateAdaptiveSettings: failed to update adaptive settings: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) UpdatePracticeSettings(testId uint, enabled bool) error {
+	if err := s.db.Model(&entity.Test{}).Where("id = ?", testId).Update("practice", enabled).Error; err != nil {
+		return fmt.Errorf("UpdatePracticeSettings: failed to update practice settings: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) ReleaseReview(testId uint, releasedAt time.Time) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Update("review_released_at", releasedAt).Error; err != nil {
+		return fmt.Errorf("ReleaseReview: failed to release review: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) UpdateAccess(testId uint, mode string, slug *string, accessCodeHash string) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{
+			"access_mode":      mode,
+			"slug":             slug,
+			"access_code_hash": accessCodeHash,
+		}).Error; err != nil {
+		return fmt.Errorf("UpdateAccess: failed to update test access: %w", err)
+	}
+	return nil
+}
+
+func (s *TestManager) UpdateGuestAccess(testId uint, allowGuests bool, guestInfo string) error {
+	if err := s.db.Model(&entity.Test{}).
+		Where("id = ?", testId).
+		Updates(map[string]interface{}{
+			"allow_guests": allowGuests,
+			"guest_info":   guestInfo,
+		}).Error; err != nil {
+		return fmt.Errorf("UpdateGuestAccess: failed to update guest access: %w", err)
+	}
+	return nil
+}
+
 func (s *TestManager) IncrementCountUserPast(testId uint, count int) error {
 	if err := s.db.Model(&entity.Test{}).Where("id = ?", testId).Update("count_user_past", count+1).Error; err != nil {
 		return fmt.Errorf("IncrementCountUserPast: failed to increment count user past: %w", err)
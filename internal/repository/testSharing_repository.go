@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TestSharing struct {
+	db *gorm.DB
+}
+
+func NewTestSharing(db *gorm.DB) *TestSharing {
+	return &TestSharing{
+		db: db,
+	}
+}
+
+func (s *TestSharing) GetInvitees(testId uint) ([]entity.TestInvitee, error) {
+	var invitees []entity.TestInvitee
+
+	if err := s.db.Where("test_id = ?", testId).
+		Order("id ASC").
+		Find(&invitees).Error; err != nil {
+		return nil, fmt.Errorf("GetInvitees: failed to get invitees: %w", err)
+	}
+
+	return invitees, nil
+}
+
+func (s *TestSharing) ReplaceInvitees(testId uint, invitees []entity.TestInvitee) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("test_id = ?", testId).Delete(&entity.TestInvitee{}).Error; err != nil {
+			return fmt.Errorf("ReplaceInvitees: failed to delete invitees: %w", err)
+		}
+
+		if len(invitees) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&invitees).Error; err != nil {
+			return fmt.Errorf("ReplaceInvitees: failed to create invitees: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *TestSharing) IsInvited(testId uint, login string, email string) (bool, error) {
+	var count int64
+
+	query := s.db.Model(&entity.TestInvitee{}).Where("test_id = ?", testId)
+	if email != "" {
+		query = query.Where("login = ? OR email = ?", login, email)
+	} else {
+		query = query.Where("login = ?", login)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("IsInvited: failed to count invitees: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *TestSharing) CreateInviteLink(link *entity.TestInviteLink) error {
+	if err := s.db.Create(link).Error; err != nil {
+		return fmt.Errorf("CreateInviteLink: failed to create invite link: %w", err)
+	}
+	return nil
+}
+
+func (s *TestSharing) GetInviteLinkById(id uint) (*entity.TestInviteLink, error) {
+	var link entity.TestInviteLink
+
+	if err := s.db.First(&link, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("GetInviteLinkById: failed to get invite link: %w", err)
+	}
+
+	return &link, nil
+}
+
+func (s *TestSharing) GetInviteLinksByTestId(testId uint) ([]entity.TestInviteLink, error) {
+	var links []entity.TestInviteLink
+
+	if err := s.db.Where("test_id = ?", testId).
+		Order("created_at DESC").
+		Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("GetInviteLinksByTestId: failed to get invite links: %w", err)
+	}
+
+	return links, nil
+}
+
+// UseInviteLink counts one more use of the link unless it is revoked, expired
+// or used up.
+func (s *TestSharing) UseInviteLink(id uint, now time.Time) error {
+	result := s.db.Model(&entity.TestInviteLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Where("max_uses = 0 OR uses < max_uses").
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return fmt.Errorf("UseInviteLink: failed to update invite link: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("UseInviteLink: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *TestSharing) RevokeInviteLink(id uint, testId uint, revokedAt time.Time) error {
+	result := s.db.Model(&entity.TestInviteLink{}).
+		Where("id = ? AND test_id = ? AND revoked_at IS NULL", id, testId).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return fmt.Errorf("RevokeInviteLink: failed to revoke invite link: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("RevokeInviteLink: %w", gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *TestSharing) SaveGrant(grant *entity.TestAccessGrant) error {
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "test_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(grant).Error; err != nil {
+		return fmt.Errorf("SaveGrant: failed to save access grant: %w", err)
+	}
+	return nil
+}
+
+func (s *TestSharing) HasGrant(testId uint, userId uint) (bool, error) {
+	var count int64
+
+	if err := s.db.Model(&entity.TestAccessGrant{}).
+		Where("test_id = ? AND user_id = ?", testId, userId).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("HasGrant: failed to count access grants: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *TestSharing) DeleteGrants(testId uint) error {
+	if err := s.db.Unscoped().Where("test_id = ?", testId).Delete(&entity.TestAccessGrant{}).Error; err != nil {
+		return fmt.Errorf("DeleteGrants: failed to delete access grants: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type TwoFactor struct {
+	db *gorm.DB
+}
+
+func NewTwoFactor(db *gorm.DB) *TwoFactor {
+	return &TwoFactor{
+		db: db,
+	}
+}
+
+func (s *TwoFactor) ReplaceRecoveryCodes(userId uint, codeHashes []string) error {
+	codes := make([]entity.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = entity.RecoveryCode{
+			UserID:   userId,
+			CodeHash: hash,
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return fmt.Errorf("ReplaceRecoveryCodes: failed to replace recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TwoFactor) UseRecoveryCode(userId uint, codeHash string, usedAt time.Time) error {
+	result := s.db.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("UseRecoveryCode: failed to use recovery code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("UseRecoveryCode: recovery code is invalid or already used")
+	}
+
+	return nil
+}
+
+func (s *TwoFactor) DeleteRecoveryCodes(userId uint) error {
+	if err := s.db.Unscoped().Where("user_id = ?", userId).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("DeleteRecoveryCodes: failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// AcceptTOTPStep records step as the last accepted TOTP time step of the user.
+// It fails when a code of that step or a later one was accepted before, so a
+// code can be used only once.
+func (s *TwoFactor) AcceptTOTPStep(userId uint, step int64) error {
+	result := s.db.Model(&entity.User{}).
+		Where("id = ? AND totp_last_step < ?", userId, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return fmt.Errorf("AcceptTOTPStep: failed to save totp step: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("AcceptTOTPStep: totp code was already used")
+	}
+
+	return nil
+}
+
+// GetTOTPLock returns until when the second factor of the user is locked,
+// nil when it is not.
+func (s *TwoFactor) GetTOTPLock(userId uint) (*time.Time, error) {
+	var user entity.User
+
+	if err := s.db.Select("id, totp_lock_until").
+		Where("id = ?", userId).
+		First(&user).Error; err != nil {
+		return nil, fmt.Errorf("GetTOTPLock: failed to get two factor lock: %w", err)
+	}
+
+	return user.TOTPLockUntil, nil
+}
+
+// RecordTOTPFailure counts a wrong second factor. The failure that reaches
+// maxFailures locks the second factor until lockUntil and starts the count
+// over.
+func (s *TwoFactor) RecordTOTPFailure(userId uint, maxFailures int, lockUntil time.Time) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]interface{}{
+			"totp_failures":   gorm.Expr("CASE WHEN totp_failures + 1 >= ? THEN 0 ELSE totp_failures + 1 END", maxFailures),
+			"totp_lock_until": gorm.Expr("CASE WHEN totp_failures + 1 >= ? THEN ? ELSE totp_lock_until END", maxFailures, lockUntil),
+		}).Error; err != nil {
+		return fmt.Errorf("RecordTOTPFailure: failed to count two factor failure: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TwoFactor) ResetTOTPFailures(userId uint) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Update("totp_failures", 0).Error; err != nil {
+		return fmt.Errorf("ResetTOTPFailures: failed to reset two factor failures: %w", err)
+	}
+	return nil
+}
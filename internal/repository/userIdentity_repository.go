@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"gorm.io/gorm"
+)
+
+type UserIdentity struct {
+	db *gorm.DB
+}
+
+func NewUserIdentity(db *gorm.DB) *UserIdentity {
+	return &UserIdentity{
+		db: db,
+	}
+}
+
+func (s *UserIdentity) GetIdentity(provider string, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+
+	if err := s.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, fmt.Errorf("GetIdentity: failed to get user identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+func (s *UserIdentity) CreateIdentity(identity *entity.UserIdentity) error {
+	if err := s.db.Create(identity).Error; err != nil {
+		return fmt.Errorf("CreateIdentity: failed to create user identity: %w", err)
+	}
+	return nil
+}
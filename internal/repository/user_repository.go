@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/server/entity"
 	"github.com/server/internal/dtos"
@@ -51,7 +52,6 @@ func (s *User) CreateUser(user entity.User) error {
 		return fmt.Errorf("CreateUser: failed to hash password: %w", err)
 	}
 	user.Password = string(hashedPassword)
-	s.logger.Info(user.RefreshToken)
 	if err := s.db.Create(&user).Error; err != nil {
 		return fmt.Errorf("CreateUser: failed to create user: %w", err)
 	}
@@ -62,7 +62,7 @@ func (s *User) CreateUser(user entity.User) error {
 func (s *User) GetUserByLogin(login string) (*entity.User, error) {
 	var user entity.User
 
-	if err := s.db.Select("id, login, email, name, avatar, password, refresh_token").
+	if err := s.db.Select("id, login, email, name, avatar, password, email_verified, pending_email, totp_secret, totp_enabled, role, suspended_at").
 		Where("login = ?", login).
 		First(&user).Error; err != nil {
 		return nil, fmt.Errorf("GetUserByLogin: failed to get user by login: %w", err)
@@ -74,7 +74,7 @@ func (s *User) GetUserByLogin(login string) (*entity.User, error) {
 func (s *User) GetUserByEmail(email string) (*entity.User, error) {
 	var user entity.User
 
-	if err := s.db.Select("id, login, email, name, avatar, refresh_token").
+	if err := s.db.Select("id, login, email, name, avatar, email_verified, pending_email, totp_secret, totp_enabled, role, suspended_at").
 		Where("email = ?", email).
 		First(&user).Error; err != nil {
 		return nil, fmt.Errorf("GetUserByEmail: failed to get user by email: %w", err)
@@ -83,17 +83,126 @@ func (s *User) GetUserByEmail(email string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (s *User) DeleteRefreshToken(login string) error {
+func (s *User) UpdatePassword(userId uint, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("UpdatePassword: failed to hash password: %w", err)
+	}
+
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Update("password", string(hashedPassword)).Error; err != nil {
+		return fmt.Errorf("UpdatePassword: failed to update password: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) GetUserById(id uint) (*entity.User, error) {
 	var user entity.User
 
-	if err := s.db.Where("login = ?", login).First(&user).Error; err != nil {
-		return fmt.Errorf("DeleteRefreshToken: failed to get user by login: %w", err)
+	if err := s.db.Select("id, login, email, name, avatar, password, email_verified, pending_email, totp_secret, totp_enabled, role, suspended_at").
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		return nil, fmt.Errorf("GetUserById: failed to get user by id: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (s *User) SetPendingEmail(userId uint, email string) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Update("pending_email", email).Error; err != nil {
+		return fmt.Errorf("SetPendingEmail: failed to update pending email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) ConfirmEmail(userId uint, email string) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]interface{}{
+			"email":          email,
+			"pending_email":  "",
+			"email_verified": true,
+		}).Error; err != nil {
+		return fmt.Errorf("ConfirmEmail: failed to confirm email: %w", err)
 	}
 
-	user.RefreshToken = ""
+	return nil
+}
 
-	if err := s.db.Save(&user).Error; err != nil {
-		return fmt.Errorf("DeleteRefreshToken: failed save user: %w", err)
+func (s *User) SetTOTPSecret(userId uint, secret string) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]interface{}{
+			"totp_secret":  secret,
+			"totp_enabled": false,
+		}).Error; err != nil {
+		return fmt.Errorf("SetTOTPSecret: failed to save totp secret: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) SetTOTPEnabled(userId uint, enabled bool) error {
+	updateData := map[string]interface{}{
+		"totp_enabled": enabled,
+	}
+	if !enabled {
+		updateData["totp_secret"] = ""
+	}
+
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Updates(updateData).Error; err != nil {
+		return fmt.Errorf("SetTOTPEnabled: failed to update two factor status: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) SearchUsers(query string, limit, offset int) ([]entity.User, int64, error) {
+	var users []entity.User
+	db := s.db.Model(&entity.User{})
+	if query != "" {
+		pattern := "%" + query + "%"
+		db = db.Where("login ILIKE ? OR email ILIKE ? OR name ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("SearchUsers: failed to count users: %w", err)
+	}
+
+	if err := db.Select("id, login, email, name, avatar, email_verified, role, suspended_at, created_at").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("SearchUsers: failed to search users: %w", err)
+	}
+
+	return users, count, nil
+}
+
+func (s *User) SetSuspended(userId uint, suspendedAt *time.Time) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Update("suspended_at", suspendedAt).Error; err != nil {
+		return fmt.Errorf("SetSuspended: failed to update suspension: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) SetRole(userId uint, role string) error {
+	if err := s.db.Model(&entity.User{}).
+		Where("id = ?", userId).
+		Update("role", role).Error; err != nil {
+		return fmt.Errorf("SetRole: failed to update role: %w", err)
 	}
 
 	return nil
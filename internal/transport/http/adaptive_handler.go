@@ -0,0 +1,176 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdaptiveUseCaseInterface interface {
+	UpdateSettings(login string, testId uint, data *dtos.UpdateAdaptiveSettingsRequest) (*dtos.AdaptiveSettingsResponse, error)
+	Calibrate(login string, testId uint) (*dtos.CalibrationResponse, error)
+	NextQuestion(login string, attemptId uint, data *dtos.NextQuestionRequest) (*dtos.NextQuestionResponse, error)
+}
+
+type AdaptiveHandler struct {
+	logger  *zap.Logger
+	usecase AdaptiveUseCaseInterface
+}
+
+func NewAdaptiveHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	testManagerRepo := repository.NewTestManager(db)
+	attemptRepo := repository.NewAttempt(db)
+	userRepo := repository.NewUser(db, logger)
+	access := newTestAccess(db)
+	cacheManager := cachemanager.New(redis.New())
+	handler := &AdaptiveHandler{
+		logger: logger,
+		usecase: usecases.NewAdaptive(
+			repository.NewAdaptive(db),
+			testManagerRepo,
+			attemptRepo,
+			userRepo,
+			usecases.NewAttempt(
+				attemptRepo,
+				testManagerRepo,
+				repository.NewAssignment(db),
+				repository.NewGroup(db),
+				userRepo,
+				access,
+				cacheManager,
+			),
+			access,
+			cacheManager,
+		),
+	}
+
+	router.HandleFunc("/test/{id}/adaptive", middleware.IsAuth(handler.UpdateSettings(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/adaptive/calibrate", middleware.IsAuth(handler.Calibrate(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/attempt/{id}/next-question", middleware.AllowGuest(middleware.IsVerified(handler.NextQuestion()))).Methods(http.MethodPost)
+}
+
+func (h *AdaptiveHandler) UpdateSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateAdaptiveSettingsRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateSettings: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.idAndLogin(w, r, "UpdateSettings")
+		if !ok {
+			return
+		}
+
+		settings, err := h.usecase.UpdateSettings(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("UpdateSettings: failed update adaptive settings", zap.Error(err))
+			errorHandler.HandleError(constants.ErrAdaptiveSettings, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, settings); err != nil {
+			h.logger.Error("UpdateSettings: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdaptiveHandler) Calibrate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.idAndLogin(w, r, "Calibrate")
+		if !ok {
+			return
+		}
+
+		calibration, err := h.usecase.Calibrate(login, testId)
+		if err != nil {
+			h.logger.Error("Calibrate: failed calibrate items", zap.Error(err))
+			errorHandler.HandleError(constants.ErrCalibrateItems, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, calibration); err != nil {
+			h.logger.Error("Calibrate: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdaptiveHandler) NextQuestion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.NextQuestionRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("NextQuestion: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		attemptId, login, ok := h.idAndLogin(w, r, "NextQuestion")
+		if !ok {
+			return
+		}
+
+		next, err := h.usecase.NextQuestion(login, attemptId, &payload)
+		if err != nil {
+			h.logger.Error("NextQuestion: failed get next question", zap.Error(err))
+			errorHandler.HandleError(constants.ErrNextQuestion, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, next); err != nil {
+			h.logger.Error("NextQuestion: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdaptiveHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	cookiesmanager "github.com/server/pkg/cookiesManager"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type AdminUseCaseInterface interface {
+	GetUsers(query string, limit, offset int) ([]entity.User, int64, error)
+	SuspendUser(actorLogin string, userId uint, r *http.Request) error
+	UnsuspendUser(actorLogin string, userId uint, r *http.Request) error
+	ChangeRole(actorLogin string, userId uint, role string, r *http.Request) error
+	UnpublishTest(actorLogin string, testId uint, r *http.Request) error
+	Impersonate(actorLogin string, userId uint, r *http.Request) (string, error)
+	GetAuditLogs(action string, limit, offset int) ([]entity.AuditLog, int64, error)
+}
+
+type AdminHandler struct {
+	logger  *zap.Logger
+	usecase AdminUseCaseInterface
+}
+
+func NewAdminHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	userRepo := repository.NewUser(db, logger)
+	sessionRepo := repository.NewSession(db, logger)
+	jwtService := jwt.NewJwt(logger)
+	cache := cachemanager.New(redis.New())
+	verificationUsecase := usecases.NewEmailVerification(userRepo, jwtService, cache, mailer.New(cfg, logger), cfg)
+	authUsecase := usecases.NewAuth(userRepo, sessionRepo, repository.NewTwoFactor(db), jwtService, verificationUsecase, cfg, logger)
+
+	handler := &AdminHandler{
+		logger: logger,
+		usecase: usecases.NewAdmin(
+			userRepo,
+			sessionRepo,
+			repository.NewTestManager(db),
+			repository.NewAuditLog(db),
+			authUsecase,
+			cache,
+		),
+	}
+
+	router.HandleFunc("/admin/users", middleware.IsAuth(middleware.RequirePermission(handler.GetUsers(), constants.PermissionViewUsers))).Methods(http.MethodGet)
+	router.HandleFunc("/admin/users/{id}/suspend", middleware.IsAuth(middleware.RequirePermission(handler.SuspendUser(), constants.PermissionManageUsers))).Methods(http.MethodPost)
+	router.HandleFunc("/admin/users/{id}/unsuspend", middleware.IsAuth(middleware.RequirePermission(handler.UnsuspendUser(), constants.PermissionManageUsers))).Methods(http.MethodPost)
+	router.HandleFunc("/admin/users/{id}/role", middleware.IsAuth(middleware.RequirePermission(handler.ChangeRole(), constants.PermissionManageUsers))).Methods(http.MethodPut)
+	router.HandleFunc("/admin/users/{id}/impersonate", middleware.IsAuth(middleware.RequirePermission(handler.Impersonate(), constants.PermissionImpersonate))).Methods(http.MethodPost)
+	router.HandleFunc("/admin/tests/{id}/unpublish", middleware.IsAuth(middleware.RequirePermission(handler.UnpublishTest(), constants.PermissionUnpublishTests))).Methods(http.MethodPost)
+	router.HandleFunc("/admin/audit", middleware.IsAuth(middleware.RequirePermission(handler.GetAuditLogs(), constants.PermissionViewAudit))).Methods(http.MethodGet)
+}
+
+func (h *AdminHandler) GetUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+		limit, offset := pageParams(r)
+
+		users, count, err := h.usecase.GetUsers(r.URL.Query().Get("query"), limit, offset)
+		if err != nil {
+			h.logger.Error("GetUsers: failed get users", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetUsers, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToAdminUsersResponse(users, count)); err != nil {
+			h.logger.Error("GetUsers: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdminHandler) SuspendUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		userId, login, ok := h.targetAndActor(w, r, "SuspendUser")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.SuspendUser(login, userId, r); err != nil {
+			h.logger.Error("SuspendUser: failed suspend user", zap.Error(err))
+			errorHandler.HandleError(constants.ErrSuspendUser, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AdminHandler) UnsuspendUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		userId, login, ok := h.targetAndActor(w, r, "UnsuspendUser")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.UnsuspendUser(login, userId, r); err != nil {
+			h.logger.Error("UnsuspendUser: failed unsuspend user", zap.Error(err))
+			errorHandler.HandleError(constants.ErrSuspendUser, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AdminHandler) ChangeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ChangeRoleRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ChangeRole: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		userId, login, ok := h.targetAndActor(w, r, "ChangeRole")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.ChangeRole(login, userId, payload.Role, r); err != nil {
+			h.logger.Error("ChangeRole: failed change role", zap.Error(err))
+			errorHandler.HandleError(constants.ErrChangeRole, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AdminHandler) Impersonate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+		userId, login, ok := h.targetAndActor(w, r, "Impersonate")
+		if !ok {
+			return
+		}
+
+		token, err := h.usecase.Impersonate(login, userId, r)
+		if err != nil {
+			h.logger.Error("Impersonate: failed impersonate user", zap.Error(err))
+			errorHandler.HandleError(constants.ErrImpersonate, http.StatusBadRequest, err)
+			return
+		}
+
+		cookiesmanager.New(r, h.logger).Set("token", token, constants.CACHE_HEALTH_TIME, true, w)
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ImpersonateResponse{Token: token}); err != nil {
+			h.logger.Error("Impersonate: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdminHandler) UnpublishTest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		testId, login, ok := h.targetAndActor(w, r, "UnpublishTest")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.UnpublishTest(login, testId, r); err != nil {
+			h.logger.Error("UnpublishTest: failed unpublish test", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUnpublishTest, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AdminHandler) GetAuditLogs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+		limit, offset := pageParams(r)
+
+		entries, count, err := h.usecase.GetAuditLogs(r.URL.Query().Get("action"), limit, offset)
+		if err != nil {
+			h.logger.Error("GetAuditLogs: failed get audit log", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetAuditLog, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.AuditLogsResponse{Entries: entries, Count: count}); err != nil {
+			h.logger.Error("GetAuditLogs: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AdminHandler) targetAndActor(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	parseId, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return uint(parseId), login, true
+}
+
+func pageParams(r *http.Request) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type APIKeyUseCaseInterface interface {
+	CreateAPIKey(login string, data *dtos.CreateAPIKeyRequest) (*dtos.CreateAPIKeyResponse, error)
+	GetAPIKeys(login string) ([]entity.APIKey, error)
+	RevokeAPIKey(login string, id uint) error
+}
+
+type APIKeyHandler struct {
+	logger  *zap.Logger
+	usecase APIKeyUseCaseInterface
+}
+
+func NewAPIKeyHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &APIKeyHandler{
+		logger:  logger,
+		usecase: usecases.NewAPIKey(repository.NewAPIKey(db), repository.NewUser(db, logger)),
+	}
+
+	router.HandleFunc("/user/apiKeys", middleware.IsAuth(handler.GetAPIKeys())).Methods(http.MethodGet)
+	router.HandleFunc("/user/apiKeys", middleware.IsAuth(middleware.NotImpersonated(handler.CreateAPIKey()))).Methods(http.MethodPost)
+	router.HandleFunc("/user/apiKeys/{id}", middleware.IsAuth(middleware.NotImpersonated(handler.RevokeAPIKey()))).Methods(http.MethodDelete)
+}
+
+func (h *APIKeyHandler) CreateAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.CreateAPIKeyRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("CreateAPIKey: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("CreateAPIKey: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, err := h.usecase.CreateAPIKey(login, &payload)
+		if err != nil {
+			h.logger.Error("CreateAPIKey: failed create api key", zap.Error(err))
+			errorHandler.HandleError(constants.ErrCreateAPIKey, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, result); err != nil {
+			h.logger.Error("CreateAPIKey: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *APIKeyHandler) GetAPIKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetAPIKeys: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		keys, err := h.usecase.GetAPIKeys(login)
+		if err != nil {
+			h.logger.Error("GetAPIKeys: failed get api keys", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetAPIKeys, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToAPIKeyResponses(keys)); err != nil {
+			h.logger.Error("GetAPIKeys: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *APIKeyHandler) RevokeAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		parseId, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			h.logger.Error("RevokeAPIKey: failed parse api key id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("RevokeAPIKey: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.usecase.RevokeAPIKey(login, uint(parseId)); err != nil {
+			h.logger.Error("RevokeAPIKey: failed revoke api key", zap.Error(err))
+			errorHandler.HandleError(constants.ErrRevokeAPIKey, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
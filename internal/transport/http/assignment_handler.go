@@ -0,0 +1,206 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AssignmentUseCaseInterface interface {
+	CreateAssignment(login string, groupId uint, data *dtos.CreateAssignmentRequest) (*entity.Assignment, error)
+	GetGroupAssignments(login string, groupId uint) ([]entity.Assignment, error)
+	DeleteAssignment(login string, id uint) error
+	GetUserAssignments(login string) ([]dtos.UserAssignmentResponse, error)
+	GetReport(login string, id uint) (*dtos.AssignmentReportResponse, error)
+}
+
+type AssignmentHandler struct {
+	logger  *zap.Logger
+	usecase AssignmentUseCaseInterface
+}
+
+func NewAssignmentHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &AssignmentHandler{
+		logger: logger,
+		usecase: usecases.NewAssignment(
+			repository.NewAssignment(db),
+			repository.NewAttempt(db),
+			repository.NewGroup(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+		),
+	}
+
+	router.HandleFunc("/user/assignments", middleware.IsAuth(handler.GetUserAssignments())).Methods(http.MethodGet)
+	router.HandleFunc("/groups/{id}/assignments", middleware.IsAuth(handler.GetGroupAssignments())).Methods(http.MethodGet)
+	router.HandleFunc("/groups/{id}/assignments", middleware.IsAuth(handler.CreateAssignment())).Methods(http.MethodPost)
+	router.HandleFunc("/assignments/{id}", middleware.IsAuth(handler.DeleteAssignment())).Methods(http.MethodDelete)
+	router.HandleFunc("/assignments/{id}/report", middleware.IsAuth(handler.GetReport(), constants.ScopeReadResults)).Methods(http.MethodGet)
+}
+
+func (h *AssignmentHandler) CreateAssignment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.CreateAssignmentRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("CreateAssignment: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		groupId, login, ok := h.idAndLogin(w, r, "CreateAssignment")
+		if !ok {
+			return
+		}
+
+		assignment, err := h.usecase.CreateAssignment(login, groupId, &payload)
+		if err != nil {
+			h.logger.Error("CreateAssignment: failed create assignment", zap.Error(err))
+			errorHandler.HandleError(constants.ErrCreateAssignment, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, dtos.ToAssignmentResponse(assignment)); err != nil {
+			h.logger.Error("CreateAssignment: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AssignmentHandler) GetGroupAssignments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		groupId, login, ok := h.idAndLogin(w, r, "GetGroupAssignments")
+		if !ok {
+			return
+		}
+
+		assignments, err := h.usecase.GetGroupAssignments(login, groupId)
+		if err != nil {
+			h.logger.Error("GetGroupAssignments: failed get assignments", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetAssignments, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToAssignmentResponses(assignments)); err != nil {
+			h.logger.Error("GetGroupAssignments: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AssignmentHandler) DeleteAssignment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		id, login, ok := h.idAndLogin(w, r, "DeleteAssignment")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.DeleteAssignment(login, id); err != nil {
+			h.logger.Error("DeleteAssignment: failed delete assignment", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetAssignments, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AssignmentHandler) GetUserAssignments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetUserAssignments: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		assignments, err := h.usecase.GetUserAssignments(login)
+		if err != nil {
+			h.logger.Error("GetUserAssignments: failed get assignments", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetAssignments, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, assignments); err != nil {
+			h.logger.Error("GetUserAssignments: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AssignmentHandler) GetReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		id, login, ok := h.idAndLogin(w, r, "GetReport")
+		if !ok {
+			return
+		}
+
+		report, err := h.usecase.GetReport(login, id)
+		if err != nil {
+			h.logger.Error("GetReport: failed get assignment report", zap.Error(err))
+			errorHandler.HandleError(constants.ErrAssignmentReport, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, report); err != nil {
+			h.logger.Error("GetReport: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AssignmentHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
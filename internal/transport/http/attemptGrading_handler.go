@@ -0,0 +1,192 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AttemptGradingUseCaseInterface interface {
+	GetQueue(login string, testId uint, questionId *uint) ([]dtos.GradingQueueItem, error)
+	GradeAnswer(login string, answerId uint, data *dtos.GradeAnswerRequest) error
+	BulkGrade(login string, testId uint, data *dtos.BulkGradeRequest) error
+	Finalize(login string, attemptId uint) (*dtos.ValidateResultResponse, error)
+}
+
+type AttemptGradingHandler struct {
+	logger  *zap.Logger
+	usecase AttemptGradingUseCaseInterface
+}
+
+func NewAttemptGradingHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	handler := &AttemptGradingHandler{
+		logger: logger,
+		usecase: usecases.NewAttemptGrading(
+			repository.NewAttempt(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			mailer.New(cfg, logger),
+			logger,
+		),
+	}
+
+	router.HandleFunc("/test/{id}/gradingQueue", middleware.IsAuth(handler.GetQueue(), constants.ScopeReadResults)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/gradingQueue/bulk", middleware.IsAuth(handler.BulkGrade(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/answer/{id}/grade", middleware.IsAuth(handler.GradeAnswer(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/attempt/{id}/finalize", middleware.IsAuth(handler.Finalize(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+}
+
+func (h *AttemptGradingHandler) GetQueue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.idAndLogin(w, r, "GetQueue")
+		if !ok {
+			return
+		}
+
+		var questionId *uint
+		if value := r.URL.Query().Get("question_id"); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				h.logger.Error("GetQueue: failed parse question id", zap.Error(err))
+				errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+				return
+			}
+			id := uint(parsed)
+			questionId = &id
+		}
+
+		queue, err := h.usecase.GetQueue(login, testId, questionId)
+		if err != nil {
+			h.logger.Error("GetQueue: failed get grading queue", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGradeAnswers, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, queue); err != nil {
+			h.logger.Error("GetQueue: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptGradingHandler) GradeAnswer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.GradeAnswerRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("GradeAnswer: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		answerId, login, ok := h.idAndLogin(w, r, "GradeAnswer")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.GradeAnswer(login, answerId, &payload); err != nil {
+			h.logger.Error("GradeAnswer: failed grade answer", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGradeAnswers, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AttemptGradingHandler) BulkGrade() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.BulkGradeRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("BulkGrade: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.idAndLogin(w, r, "BulkGrade")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.BulkGrade(login, testId, &payload); err != nil {
+			h.logger.Error("BulkGrade: failed grade answers", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGradeAnswers, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AttemptGradingHandler) Finalize() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		attemptId, login, ok := h.idAndLogin(w, r, "Finalize")
+		if !ok {
+			return
+		}
+
+		result, err := h.usecase.Finalize(login, attemptId)
+		if err != nil {
+			h.logger.Error("Finalize: failed finalize attempt", zap.Error(err))
+			errorHandler.HandleError(constants.ErrFinalizeAttempt, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, result); err != nil {
+			h.logger.Error("Finalize: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptGradingHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AttemptReviewUseCaseInterface interface {
+	UpdateReviewPolicy(login string, testId uint, data *dtos.UpdateReviewPolicyRequest) error
+	ReleaseReview(login string, testId uint) error
+	GetReview(login string, attemptId uint) (*dtos.AttemptReviewResponse, error)
+}
+
+type AttemptReviewHandler struct {
+	logger  *zap.Logger
+	usecase AttemptReviewUseCaseInterface
+}
+
+func NewAttemptReviewHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &AttemptReviewHandler{
+		logger: logger,
+		usecase: usecases.NewAttemptReview(
+			repository.NewAttempt(db),
+			repository.NewTestManager(db),
+			repository.NewAssignment(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/review", middleware.IsAuth(handler.UpdateReviewPolicy(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/review/release", middleware.IsAuth(handler.ReleaseReview(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/attempt/{id}/review", middleware.AllowGuest(handler.GetReview())).Methods(http.MethodGet)
+}
+
+func (h *AttemptReviewHandler) UpdateReviewPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateReviewPolicyRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateReviewPolicy: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.idAndLogin(w, r, "UpdateReviewPolicy")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.UpdateReviewPolicy(login, testId, &payload); err != nil {
+			h.logger.Error("UpdateReviewPolicy: failed update review policy", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateReviewPolicy, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AttemptReviewHandler) ReleaseReview() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+
+		testId, login, ok := h.idAndLogin(w, r, "ReleaseReview")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.ReleaseReview(login, testId); err != nil {
+			h.logger.Error("ReleaseReview: failed release review", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateReviewPolicy, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AttemptReviewHandler) GetReview() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		attemptId, login, ok := h.idAndLogin(w, r, "GetReview")
+		if !ok {
+			return
+		}
+
+		review, err := h.usecase.GetReview(login, attemptId)
+		if err != nil {
+			h.logger.Error("GetReview: failed get attempt review", zap.Error(err))
+			errorHandler.HandleError(constants.ErrAttemptReview, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, review); err != nil {
+			h.logger.Error("GetReview: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptReviewHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
@@ -0,0 +1,287 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AttemptUseCaseInterface interface {
+	UpdateAttemptPolicy(login string, testId uint, data *dtos.UpdateAttemptPolicyRequest) error
+	StartAttempt(login string, testId uint, assignmentId *uint) (*entity.Attempt, error)
+	GetResults(login string, testId uint) (*dtos.TestResultsResponse, error)
+	GetLeaderboard(login string, testId uint, limit int) ([]dtos.LeaderboardEntry, error)
+	GetGrading(login string, testId uint) (*dtos.GradingResponse, error)
+	UpdateGrading(login string, testId uint, data *dtos.UpdateGradingRequest) (*dtos.GradingResponse, error)
+	ReapplyGrading(login string, testId uint) (*dtos.ReapplyGradingResponse, error)
+}
+
+type AttemptHandler struct {
+	logger  *zap.Logger
+	usecase AttemptUseCaseInterface
+}
+
+func NewAttemptHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &AttemptHandler{
+		logger: logger,
+		usecase: usecases.NewAttempt(
+			repository.NewAttempt(db),
+			repository.NewTestManager(db),
+			repository.NewAssignment(db),
+			repository.NewGroup(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/attemptPolicy", middleware.IsAuth(handler.UpdateAttemptPolicy(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/attempts", middleware.AllowGuest(middleware.IsVerified(handler.StartAttempt()))).Methods(http.MethodPost)
+	router.HandleFunc("/test/{id}/results", middleware.IsAuth(handler.GetResults(), constants.ScopeReadResults)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/leaderboard", middleware.IsAuth(handler.GetLeaderboard(), constants.ScopeReadResults)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/grading", middleware.IsAuth(handler.GetGrading(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/grading", middleware.IsAuth(handler.UpdateGrading(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/grading/reapply", middleware.IsAuth(handler.ReapplyGrading(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+}
+
+func (h *AttemptHandler) UpdateAttemptPolicy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateAttemptPolicyRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateAttemptPolicy: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "UpdateAttemptPolicy")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.UpdateAttemptPolicy(login, testId, &payload); err != nil {
+			h.logger.Error("UpdateAttemptPolicy: failed update attempt policy", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateAttemptPolicy, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AttemptHandler) StartAttempt() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.StartAttemptRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("StartAttempt: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "StartAttempt")
+		if !ok {
+			return
+		}
+
+		attempt, err := h.usecase.StartAttempt(login, testId, payload.AssignmentID)
+		if err != nil {
+			h.logger.Error("StartAttempt: failed start attempt", zap.Error(err))
+			errorHandler.HandleError(constants.ErrStartAttempt, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, attempt); err != nil {
+			h.logger.Error("StartAttempt: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) GetResults() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetResults")
+		if !ok {
+			return
+		}
+
+		results, err := h.usecase.GetResults(login, testId)
+		if err != nil {
+			h.logger.Error("GetResults: failed get test results", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetResults, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, results); err != nil {
+			h.logger.Error("GetResults: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) GetGrading() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetGrading")
+		if !ok {
+			return
+		}
+
+		grading, err := h.usecase.GetGrading(login, testId)
+		if err != nil {
+			h.logger.Error("GetGrading: failed get test grading", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateGrading, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, grading); err != nil {
+			h.logger.Error("GetGrading: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) UpdateGrading() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateGradingRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateGrading: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "UpdateGrading")
+		if !ok {
+			return
+		}
+
+		grading, err := h.usecase.UpdateGrading(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("UpdateGrading: failed update test grading", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateGrading, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, grading); err != nil {
+			h.logger.Error("UpdateGrading: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) ReapplyGrading() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "ReapplyGrading")
+		if !ok {
+			return
+		}
+
+		result, err := h.usecase.ReapplyGrading(login, testId)
+		if err != nil {
+			h.logger.Error("ReapplyGrading: failed reapply test grading", zap.Error(err))
+			errorHandler.HandleError(constants.ErrUpdateGrading, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, result); err != nil {
+			h.logger.Error("ReapplyGrading: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) GetLeaderboard() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetLeaderboard")
+		if !ok {
+			return
+		}
+
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit > maxPageLimit {
+			limit = 0
+		}
+
+		leaderboard, err := h.usecase.GetLeaderboard(login, testId, limit)
+		if err != nil {
+			h.logger.Error("GetLeaderboard: failed get leaderboard", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetLeaderboard, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, leaderboard); err != nil {
+			h.logger.Error("GetLeaderboard: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *AttemptHandler) testAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	testId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse test id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return testId, login, true
+}
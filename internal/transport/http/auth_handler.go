@@ -5,15 +5,19 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
 	"github.com/server/configs"
 	"github.com/server/internal/dtos"
 	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
 	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
 	"github.com/server/pkg/constants"
 	cookiesmanager "github.com/server/pkg/cookiesManager"
 	errorshandler "github.com/server/pkg/errorsHandler"
 	"github.com/server/pkg/json"
 	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
 	"gorm.io/gorm"
 
 	"go.uber.org/zap"
@@ -21,27 +25,43 @@ import (
 
 type AuthUseCaseInterface interface {
 	Login(data *dtos.LoginRequest, w http.ResponseWriter, r *http.Request) (*dtos.LoginResponse, error)
-	Registration(data *dtos.RegistrationRequest) (*dtos.RegistrationResponse, error)
+	Registration(data *dtos.RegistrationRequest, r *http.Request) (*dtos.RegistrationResponse, error)
+	LoginTwoFactor(data *dtos.TwoFactorLoginRequest, r *http.Request) (*dtos.LoginResponse, error)
+}
+
+type EmailVerificationUseCaseInterface interface {
+	VerifyEmail(token string) error
+	ResendVerification(login string) error
+	ChangeEmail(login string, email string) error
 }
 
 type AuthHandler struct {
-	logger      *zap.Logger
-	authUsecase AuthUseCaseInterface
+	logger              *zap.Logger
+	authUsecase         AuthUseCaseInterface
+	verificationUsecase EmailVerificationUseCaseInterface
 }
 
 func NewAuthHandler(router *mux.Router, logger *zap.Logger, db *gorm.DB, cfg *configs.Config) {
 	userRepo := repository.NewUser(db, logger)
-	authRepo := repository.NewAuth(db, logger)
+	sessionRepo := repository.NewSession(db, logger)
+	recoveryRepo := repository.NewTwoFactor(db)
 	jwtService := jwt.NewJwt(logger)
-	authUsecase := usecases.NewAuth(userRepo, authRepo, jwtService, cfg)
+	cache := cachemanager.New(redis.New())
+	verificationUsecase := usecases.NewEmailVerification(userRepo, jwtService, cache, mailer.New(cfg, logger), cfg)
+	authUsecase := usecases.NewAuth(userRepo, sessionRepo, recoveryRepo, jwtService, verificationUsecase, cfg, logger)
 
 	handler := &AuthHandler{
-		logger:      logger,
-		authUsecase: authUsecase,
+		logger:              logger,
+		authUsecase:         authUsecase,
+		verificationUsecase: verificationUsecase,
 	}
 
 	router.HandleFunc("/auth/login", handler.Login()).Methods(http.MethodPost)
+	router.HandleFunc("/auth/login/2fa", handler.LoginTwoFactor()).Methods(http.MethodPost)
 	router.HandleFunc("/auth/registration", handler.Registration()).Methods(http.MethodPost)
+	router.HandleFunc("/auth/email/verify", handler.VerifyEmail()).Methods(http.MethodPost)
+	router.HandleFunc("/user/email/resend", middleware.IsAuth(middleware.NotImpersonated(handler.ResendVerification()))).Methods(http.MethodPost)
+	router.HandleFunc("/user/email", middleware.IsAuth(middleware.NotImpersonated(handler.ChangeEmail()))).Methods(http.MethodPost)
 }
 
 func (h *AuthHandler) Login() http.HandlerFunc {
@@ -65,7 +85,9 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 			return
 		}
 
-		cookie.Set("token", token.Token, time.Minute*15, true, w)
+		if token.Token != "" {
+			cookie.Set("token", token.Token, time.Minute*15, true, w)
+		}
 		if err := jsonUtil.Encode(http.StatusOK, token); err != nil {
 			h.logger.Error("Login: failed encode response body", zap.Error(err))
 			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
@@ -74,6 +96,36 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 	}
 }
 
+func (h *AuthHandler) LoginTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		cookie := cookiesmanager.New(r, h.logger)
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.TwoFactorLoginRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("LoginTwoFactor: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		token, err := h.authUsecase.LoginTwoFactor(&payload, r)
+		if err != nil {
+			h.logger.Error("LoginTwoFactor: failed verify second factor", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTwoFactorCode, http.StatusUnauthorized, err)
+			return
+		}
+
+		cookie.Set("token", token.Token, time.Minute*15, true, w)
+		if err := jsonUtil.Encode(http.StatusOK, token); err != nil {
+			h.logger.Error("LoginTwoFactor: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
 func (h *AuthHandler) Registration() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
@@ -88,7 +140,7 @@ func (h *AuthHandler) Registration() http.HandlerFunc {
 			return
 		}
 
-		result, err := h.authUsecase.Registration(&payload)
+		result, err := h.authUsecase.Registration(&payload, r)
 		if err != nil {
 			h.logger.Error("Registration: failed registration user", zap.Error(err))
 			errorHandler.HandleError(constants.ErrRegistration, http.StatusBadRequest, err)
@@ -102,3 +154,78 @@ func (h *AuthHandler) Registration() http.HandlerFunc {
 		}
 	}
 }
+
+func (h *AuthHandler) VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.VerifyEmailRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("VerifyEmail: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.verificationUsecase.VerifyEmail(payload.Token); err != nil {
+			h.logger.Error("VerifyEmail: failed verify email", zap.Error(err))
+			errorHandler.HandleError(constants.ErrVerifyEmail, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AuthHandler) ResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("ResendVerification: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.verificationUsecase.ResendVerification(login); err != nil {
+			h.logger.Error("ResendVerification: failed resend verification email", zap.Error(err))
+			errorHandler.HandleError(constants.ErrResendVerification, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *AuthHandler) ChangeEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ChangeEmailRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ChangeEmail: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("ChangeEmail: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.verificationUsecase.ChangeEmail(login, payload.Email); err != nil {
+			h.logger.Error("ChangeEmail: failed change email", zap.Error(err))
+			errorHandler.HandleError(constants.EmailExist, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/sandbox"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CodeQuestionUseCaseInterface interface {
+	GetTestCases(login string, questionId uint) (*dtos.CodeTestsResponse, error)
+	SetTestCases(login string, questionId uint, data *dtos.UpdateCodeTestsRequest) (*dtos.CodeTestsResponse, error)
+}
+
+type CodeQuestionHandler struct {
+	logger  *zap.Logger
+	usecase CodeQuestionUseCaseInterface
+}
+
+func NewCodeQuestionHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	handler := &CodeQuestionHandler{
+		logger: logger,
+		usecase: usecases.NewCodeQuestion(
+			repository.NewCodeQuestion(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			newSandbox(cfg),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/question/{id}/codeTests", middleware.IsAuth(handler.GetTestCases(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/question/{id}/codeTests", middleware.IsAuth(handler.SetTestCases(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+}
+
+func (h *CodeQuestionHandler) GetTestCases() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		questionId, login, ok := h.questionAndLogin(w, r, "GetTestCases")
+		if !ok {
+			return
+		}
+
+		rubric, err := h.usecase.GetTestCases(login, questionId)
+		if err != nil {
+			h.logger.Error("GetTestCases: failed get test cases", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageCodeTests, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, rubric); err != nil {
+			h.logger.Error("GetTestCases: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *CodeQuestionHandler) SetTestCases() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateCodeTestsRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("SetTestCases: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		questionId, login, ok := h.questionAndLogin(w, r, "SetTestCases")
+		if !ok {
+			return
+		}
+
+		rubric, err := h.usecase.SetTestCases(login, questionId, &payload)
+		if err != nil {
+			h.logger.Error("SetTestCases: failed set test cases", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageCodeTests, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, rubric); err != nil {
+			h.logger.Error("SetTestCases: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *CodeQuestionHandler) questionAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	questionId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse question id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return questionId, login, true
+}
+
+var (
+	sharedSandbox     *sandbox.Sandbox
+	sharedSandboxOnce sync.Once
+)
+
+// newSandbox sets up the runners code questions can be answered with. Every
+// handler gets the same sandbox so its concurrency bound holds server-wide.
+func newSandbox(cfg *configs.Config) *sandbox.Sandbox {
+	sharedSandboxOnce.Do(func() {
+		sharedSandbox = sandbox.New(
+			constants.SANDBOX_CONCURRENCY,
+			sandbox.NewGo(cfg.SANDBOX_GO_BINARY, cfg.SANDBOX_CACHE_DIR, sandbox.Limits{
+				BuildTimeout:   constants.SANDBOX_BUILD_TIMEOUT,
+				BuildMemoryMB:  constants.SANDBOX_BUILD_MEMORY_MB,
+				BuildProcesses: constants.SANDBOX_BUILD_PROCESSES,
+				RunTimeout:     constants.SANDBOX_RUN_TIMEOUT,
+				MemoryMB:       constants.SANDBOX_MEMORY_MB,
+				Processes:      constants.SANDBOX_PROCESSES,
+				OutputBytes:    constants.SANDBOX_OUTPUT_BYTES,
+			}),
+		)
+	})
+	return sharedSandbox
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	mapjson "github.com/server/pkg/mapJson"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type GroupUseCaseInterface interface {
+	CreateGroup(login string, data *dtos.CreateGroupRequest) (*dtos.GroupResponse, error)
+	GetGroups(login string) ([]dtos.GroupResponse, error)
+	JoinGroup(login string, code string) (*dtos.GroupResponse, error)
+	GetMembers(login string, groupId uint) ([]entity.GroupMember, error)
+	RemoveMember(login string, groupId uint, userId uint) error
+	RegenerateJoinCode(login string, groupId uint) (string, error)
+	DeleteGroup(login string, groupId uint) error
+}
+
+type GroupHandler struct {
+	logger  *zap.Logger
+	usecase GroupUseCaseInterface
+}
+
+func NewGroupHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &GroupHandler{
+		logger:  logger,
+		usecase: usecases.NewGroup(repository.NewGroup(db), repository.NewUser(db, logger)),
+	}
+
+	router.HandleFunc("/groups", middleware.IsAuth(handler.GetGroups())).Methods(http.MethodGet)
+	router.HandleFunc("/groups", middleware.IsAuth(handler.CreateGroup())).Methods(http.MethodPost)
+	router.HandleFunc("/groups/join", middleware.IsAuth(handler.JoinGroup())).Methods(http.MethodPost)
+	router.HandleFunc("/groups/{id}", middleware.IsAuth(handler.DeleteGroup())).Methods(http.MethodDelete)
+	router.HandleFunc("/groups/{id}/joinCode", middleware.IsAuth(handler.RegenerateJoinCode())).Methods(http.MethodPost)
+	router.HandleFunc("/groups/{id}/members", middleware.IsAuth(handler.GetMembers())).Methods(http.MethodGet)
+	router.HandleFunc("/groups/{id}/members/{userId}", middleware.IsAuth(handler.RemoveMember())).Methods(http.MethodDelete)
+}
+
+func (h *GroupHandler) CreateGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.CreateGroupRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("CreateGroup: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("CreateGroup: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		group, err := h.usecase.CreateGroup(login, &payload)
+		if err != nil {
+			h.logger.Error("CreateGroup: failed create group", zap.Error(err))
+			errorHandler.HandleError(constants.ErrCreateGroup, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, group); err != nil {
+			h.logger.Error("CreateGroup: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *GroupHandler) GetGroups() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetGroups: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		groups, err := h.usecase.GetGroups(login)
+		if err != nil {
+			h.logger.Error("GetGroups: failed get groups", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetGroups, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, groups); err != nil {
+			h.logger.Error("GetGroups: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *GroupHandler) JoinGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.JoinGroupRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("JoinGroup: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("JoinGroup: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		group, err := h.usecase.JoinGroup(login, payload.Code)
+		if err != nil {
+			h.logger.Error("JoinGroup: failed join group", zap.Error(err))
+			errorHandler.HandleError(constants.ErrJoinGroup, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, group); err != nil {
+			h.logger.Error("JoinGroup: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *GroupHandler) GetMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		groupId, login, ok := h.groupAndLogin(w, r, "GetMembers")
+		if !ok {
+			return
+		}
+
+		members, err := h.usecase.GetMembers(login, groupId)
+		if err != nil {
+			h.logger.Error("GetMembers: failed get group members", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetGroups, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToGroupMemberResponses(members)); err != nil {
+			h.logger.Error("GetMembers: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *GroupHandler) RemoveMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		groupId, login, ok := h.groupAndLogin(w, r, "RemoveMember")
+		if !ok {
+			return
+		}
+
+		userId, err := parseUintVar(r, "userId")
+		if err != nil {
+			h.logger.Error("RemoveMember: failed parse user id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.RemoveMember(login, groupId, userId); err != nil {
+			h.logger.Error("RemoveMember: failed remove group member", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageGroup, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *GroupHandler) RegenerateJoinCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonResponse := mapjson.New(h.logger, w, r)
+		groupId, login, ok := h.groupAndLogin(w, r, "RegenerateJoinCode")
+		if !ok {
+			return
+		}
+
+		code, err := h.usecase.RegenerateJoinCode(login, groupId)
+		if err != nil {
+			h.logger.Error("RegenerateJoinCode: failed regenerate join code", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageGroup, http.StatusForbidden, err)
+			return
+		}
+
+		jsonResponse.JsonSuccess(code)
+	}
+}
+
+func (h *GroupHandler) DeleteGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		groupId, login, ok := h.groupAndLogin(w, r, "DeleteGroup")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.DeleteGroup(login, groupId); err != nil {
+			h.logger.Error("DeleteGroup: failed delete group", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageGroup, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *GroupHandler) groupAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	groupId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse group id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return groupId, login, true
+}
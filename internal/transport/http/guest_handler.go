@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type GuestUseCaseInterface interface {
+	UpdateGuestAccess(login string, testId uint, data *dtos.UpdateGuestAccessRequest) error
+	StartGuest(testId uint, data *dtos.StartGuestRequest, r *http.Request) (*dtos.GuestSessionResponse, error)
+	Claim(login string, guestToken string) error
+}
+
+type GuestHandler struct {
+	logger  *zap.Logger
+	usecase GuestUseCaseInterface
+}
+
+func NewGuestHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &GuestHandler{
+		logger: logger,
+		usecase: usecases.NewGuest(
+			repository.NewGuest(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			jwt.NewJwt(logger),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/guest/tests/{id}/start", middleware.RateLimit(handler.StartGuest(), "guest-start", constants.GUEST_START_LIMIT, constants.GUEST_START_WINDOW)).Methods(http.MethodPost)
+	router.HandleFunc("/guest/claim", middleware.IsAuth(handler.Claim())).Methods(http.MethodPost)
+	router.HandleFunc("/test/{id}/guestAccess", middleware.IsAuth(handler.UpdateGuestAccess(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+}
+
+func (h *GuestHandler) UpdateGuestAccess() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateGuestAccessRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateGuestAccess: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, err := parseUintVar(r, "id")
+		if err != nil {
+			h.logger.Error("UpdateGuestAccess: failed parse test id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("UpdateGuestAccess: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.usecase.UpdateGuestAccess(login, testId, &payload); err != nil {
+			h.logger.Error("UpdateGuestAccess: failed update guest access", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageGuestAccess, http.StatusBadRequest, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *GuestHandler) StartGuest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.StartGuestRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("StartGuest: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, err := parseUintVar(r, "id")
+		if err != nil {
+			h.logger.Error("StartGuest: failed parse test id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		session, err := h.usecase.StartGuest(testId, &payload, r)
+		if err != nil {
+			h.logger.Error("StartGuest: failed start guest session", zap.Error(err))
+			errorHandler.HandleError(constants.ErrStartGuest, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, session); err != nil {
+			h.logger.Error("StartGuest: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *GuestHandler) Claim() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ClaimGuestRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("Claim: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("Claim: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.usecase.Claim(login, payload.GuestToken); err != nil {
+			h.logger.Error("Claim: failed claim guest attempts", zap.Error(err))
+			errorHandler.HandleError(constants.ErrClaimGuest, http.StatusBadRequest, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
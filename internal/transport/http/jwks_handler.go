@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+)
+
+type JWKSHandler struct {
+	logger *zap.Logger
+}
+
+func NewJWKSHandler(logger *zap.Logger, router *mux.Router) {
+	handler := &JWKSHandler{
+		logger: logger,
+	}
+
+	router.HandleFunc("/.well-known/jwks.json", handler.GetKeys()).Methods(http.MethodGet)
+}
+
+func (h *JWKSHandler) GetKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := jsonUtil.Encode(http.StatusOK, jwt.NewJwt(h.logger).PublicKeys()); err != nil {
+			h.logger.Error("GetKeys: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/server/adapters/storage/postgresql"
+	"github.com/server/configs"
+	"github.com/server/internal/repository"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AllowGuest accepts a guest token from the X-Guest-Token header on routes
+// that guests may use. Requests without it are passed to IsAuth.
+func AllowGuest(next http.Handler, scopes ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guestToken := r.Header.Get("X-Guest-Token")
+		if guestToken == "" {
+			IsAuth(next, scopes...).ServeHTTP(w, r)
+			return
+		}
+
+		log := logger.GetInstance()
+
+		cfg, err := configs.Load(log)
+		if err != nil {
+			log.Error("Failed to load config", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		db, err := postgresql.New(cfg, log)
+		if err != nil {
+			log.Error("Failed to create DB instance", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		claims, err := jwt.NewJwt(log).VerifyPurposeToken(guestToken, constants.GuestPurpose)
+		if err != nil {
+			log.Error("Failed to verify guest token", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		login, _ := claims["login"].(string)
+		user, err := repository.NewUser(db.Connection(), log).GetUserByLogin(login)
+		if err != nil {
+			log.Error("Failed to find guest user", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if user.Role != constants.RoleGuest || user.SuspendedAt != nil {
+			log.Warn("Guest token belongs to a claimed or non guest user", zap.String("login", login))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(jwt.ContextWithLogin(r.Context(), user.Login)))
+	}
+}
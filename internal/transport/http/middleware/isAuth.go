@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/server/adapters/storage/postgresql"
 	"github.com/server/configs"
+	"github.com/server/entity"
 	"github.com/server/internal/repository"
+	"github.com/server/internal/usecases"
 	"github.com/server/pkg/constants"
 	cookiesmanager "github.com/server/pkg/cookiesManager"
 	"github.com/server/pkg/jwt"
@@ -13,7 +17,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func IsAuth(next http.Handler) http.HandlerFunc {
+func IsAuth(next http.Handler, scopes ...string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := logger.GetInstance()
 		JWT := jwt.NewJwt(log)
@@ -36,6 +40,26 @@ func IsAuth(next http.Handler) http.HandlerFunc {
 		connPostgres := db.Connection()
 
 		userRepo := repository.NewUser(connPostgres, log)
+		sessionRepo := repository.NewSession(connPostgres, log)
+
+		if rawKey, ok := bearerAPIKey(r); ok {
+			if len(scopes) == 0 {
+				log.Warn("Api keys are not accepted on this route", zap.String("path", r.URL.Path))
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			apiKeyUsecase := usecases.NewAPIKey(repository.NewAPIKey(connPostgres), userRepo)
+			user, err := apiKeyUsecase.Authenticate(rawKey, scopes)
+			if err != nil {
+				log.Error("Failed to authenticate api key", zap.Error(err))
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(jwt.ContextWithLogin(r.Context(), user.Login)))
+			return
+		}
 
 		login, err := JWT.ExtractUserFromToken(r)
 		if err != nil {
@@ -53,14 +77,37 @@ func IsAuth(next http.Handler) http.HandlerFunc {
 			return
 		}
 
-		if findUserByLogin.RefreshToken == "" {
+		if findUserByLogin.SuspendedAt != nil {
+			deleteTokenCookie(w, r, log)
+			log.Warn("User is suspended", zap.String("login", login))
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		sessionID, err := JWT.ExtractSessionFromToken(r)
+		if err != nil {
+			deleteTokenCookie(w, r, log)
+			log.Error("Failed to extract session from token", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		session, err := sessionRepo.GetSessionById(sessionID)
+		if err != nil {
+			deleteTokenCookie(w, r, log)
+			log.Error("Failed to find session", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if session.UserID != findUserByLogin.ID || session.RefreshToken == "" {
 			deleteTokenCookie(w, r, log)
-			log.Warn("User does not have a refresh token", zap.String("login", login))
+			log.Warn("Session does not belong to user or has no refresh token", zap.String("login", login))
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		accessToken, err := JWT.RefreshAccessToken(findUserByLogin.RefreshToken)
+		accessToken, err := JWT.RefreshAccessToken(session.RefreshToken)
 		if err != nil {
 			deleteTokenCookie(w, r, log)
 			log.Error("Failed to refresh access token", zap.Error(err))
@@ -68,13 +115,50 @@ func IsAuth(next http.Handler) http.HandlerFunc {
 			return
 		}
 
+		if err := sessionRepo.TouchSession(session.ID, time.Now()); err != nil {
+			log.Error("Failed to update session last seen time", zap.Error(err))
+		}
+
 		cookie.Set("token", accessToken, constants.CACHE_HEALTH_TIME, true, w)
 
+		// Everything an admin changes while impersonating is audited
+		// before it happens.
+		if session.ImpersonatorID != nil {
+			if !isReadOnly(r.Method) {
+				if err := repository.NewAuditLog(connPostgres).CreateAuditLog(&entity.AuditLog{
+					ActorID:    *session.ImpersonatorID,
+					Action:     constants.AuditImpersonatedAction,
+					TargetType: "user",
+					TargetID:   findUserByLogin.ID,
+					IP:         usecases.ClientIP(r),
+					Details:    r.Method + " " + r.URL.Path,
+				}); err != nil {
+					log.Error("Failed to audit impersonated request", zap.Error(err))
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+			}
+			r = r.WithContext(contextWithImpersonator(r.Context(), *session.ImpersonatorID))
+		}
+
 		next.ServeHTTP(w, r)
 	}
 }
 
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 func deleteTokenCookie(w http.ResponseWriter, r *http.Request, log *zap.Logger) {
 	cookie := cookiesmanager.New(r, log)
 	cookie.Delete("token", w)
 }
+
+func bearerAPIKey(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	rawKey, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || !strings.HasPrefix(rawKey, constants.API_KEY_PREFIX) {
+		return "", false
+	}
+	return strings.TrimSpace(rawKey), true
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/server/adapters/storage/postgresql"
+	"github.com/server/configs"
+	"github.com/server/internal/repository"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func IsVerified(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.GetInstance()
+
+		cfg, err := configs.Load(log)
+		if err != nil {
+			log.Error("Failed to load config", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if !cfg.REQUIRE_EMAIL_VERIFICATION {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		db, err := postgresql.New(cfg, log)
+		if err != nil {
+			log.Error("Failed to create DB instance", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		userRepo := repository.NewUser(db.Connection(), log)
+
+		login, err := jwt.NewJwt(log).ExtractUserFromToken(r)
+		if err != nil {
+			log.Error("Failed to extract user from token", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := userRepo.GetUserByLogin(login)
+		if err != nil {
+			log.Error("Failed to find user by login", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !user.EmailVerified && user.Role != constants.RoleGuest {
+			log.Warn("User email is not verified", zap.String("login", login))
+			errorshandler.New(log, w, r).HandleError(constants.ErrEmailNotVerified, http.StatusForbidden, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
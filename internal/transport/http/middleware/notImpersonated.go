@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type contextKey string
+
+const impersonatorContextKey contextKey = "impersonator"
+
+func contextWithImpersonator(ctx context.Context, impersonatorId uint) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey, impersonatorId)
+}
+
+// NotImpersonated refuses requests made on a session an admin opened on
+// behalf of the user: credentials and keys are changed by the user only. It
+// must be wrapped by IsAuth.
+func NotImpersonated(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if impersonatorId, ok := r.Context().Value(impersonatorContextKey).(uint); ok {
+			logger.GetInstance().Warn("Impersonated session can not change credentials",
+				zap.Uint("impersonator_id", impersonatorId),
+				zap.String("path", r.URL.Path))
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RateLimit allows at most limit requests per client address within window.
+// The counters live in redis, so the limit is shared between instances.
+func RateLimit(next http.Handler, name string, limit int64, window time.Duration) http.HandlerFunc {
+	return RateLimitBy(next, name, func(r *http.Request) (string, error) {
+		return usecases.ClientIP(r), nil
+	}, limit, window)
+}
+
+// RateLimitBy is RateLimit with the requests counted per the value of key.
+func RateLimitBy(next http.Handler, name string, key func(r *http.Request) (string, error), limit int64, window time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.GetInstance()
+
+		value, err := key(r)
+		if err != nil {
+			log.Error("Failed to get rate limit key", zap.String("limit", name), zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		key := fmt.Sprintf("ratelimit:%s:%s", name, value)
+		count, err := redis.New().Incr(key, window)
+		if err != nil {
+			log.Error("Failed to count request for rate limit", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if count > limit {
+			log.Warn("Rate limit exceeded", zap.String("limit", name), zap.String("key", key))
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(window.Seconds())))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// UserAndTestKey counts the requests of the signed-in user to the test in the
+// id path variable. It must run inside IsAuth.
+func UserAndTestKey(r *http.Request) (string, error) {
+	login, err := jwt.NewJwt(logger.GetInstance()).ExtractUserFromToken(r)
+	if err != nil {
+		return "", fmt.Errorf("UserAndTestKey: %w", err)
+	}
+	return fmt.Sprintf("%s:%s", login, mux.Vars(r)["id"]), nil
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/server/adapters/storage/postgresql"
+	"github.com/server/configs"
+	"github.com/server/internal/repository"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RequirePermission lets the request through only if the global role of the
+// authenticated user grants permission. It must be wrapped by IsAuth.
+func RequirePermission(next http.Handler, permission string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := logger.GetInstance()
+
+		cfg, err := configs.Load(log)
+		if err != nil {
+			log.Error("Failed to load config", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		db, err := postgresql.New(cfg, log)
+		if err != nil {
+			log.Error("Failed to create DB instance", zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		userRepo := repository.NewUser(db.Connection(), log)
+
+		login, err := jwt.NewJwt(log).ExtractUserFromToken(r)
+		if err != nil {
+			log.Error("Failed to extract user from token", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := userRepo.GetUserByLogin(login)
+		if err != nil {
+			log.Error("Failed to find user by login", zap.Error(err))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !HasPermission(user.Role, permission) {
+			log.Warn("Permission denied", zap.String("login", login), zap.String("permission", permission))
+			errorshandler.New(log, w, r).HandleError(constants.ErrAccessDenied, http.StatusForbidden, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+func HasPermission(role string, permission string) bool {
+	return slices.Contains(constants.RolePermissions[role], permission)
+}
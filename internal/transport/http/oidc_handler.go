@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	cookiesmanager "github.com/server/pkg/cookiesManager"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
+	"github.com/server/pkg/oidc"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OIDCUseCaseInterface interface {
+	StartLogin(ctx context.Context, providerName string) (string, string, error)
+	StartLink(ctx context.Context, providerName string, login string) (string, string, error)
+	Callback(ctx context.Context, providerName string, code string, state string, stateCookie string, login string, r *http.Request) (*dtos.LoginResponse, error)
+}
+
+type OIDCHandler struct {
+	logger  *zap.Logger
+	cfg     *configs.Config
+	usecase OIDCUseCaseInterface
+}
+
+func NewOIDCHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	userRepo := repository.NewUser(db, logger)
+	sessionRepo := repository.NewSession(db, logger)
+	recoveryRepo := repository.NewTwoFactor(db)
+	identityRepo := repository.NewUserIdentity(db)
+	jwtService := jwt.NewJwt(logger)
+	cache := cachemanager.New(redis.New())
+	verificationUsecase := usecases.NewEmailVerification(userRepo, jwtService, cache, mailer.New(cfg, logger), cfg)
+	authUsecase := usecases.NewAuth(userRepo, sessionRepo, recoveryRepo, jwtService, verificationUsecase, cfg, logger)
+
+	providers := make([]usecases.OIDCProviderInterface, len(cfg.OIDC_PROVIDERS))
+	for i, providerConfig := range cfg.OIDC_PROVIDERS {
+		providers[i] = oidc.NewProvider(providerConfig, nil)
+	}
+
+	handler := &OIDCHandler{
+		logger:  logger,
+		cfg:     cfg,
+		usecase: usecases.NewOIDC(providers, userRepo, identityRepo, authUsecase, cache),
+	}
+
+	router.HandleFunc("/auth/oidc/{provider}/login", handler.StartLogin()).Methods(http.MethodGet)
+	router.HandleFunc("/auth/oidc/{provider}/link", middleware.IsAuth(middleware.NotImpersonated(handler.StartLink()))).Methods(http.MethodGet)
+	router.HandleFunc("/auth/oidc/{provider}/callback", handler.Callback()).Methods(http.MethodGet)
+}
+
+func (h *OIDCHandler) StartLogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+
+		redirectURL, stateCookie, err := h.usecase.StartLogin(r.Context(), mux.Vars(r)["provider"])
+		if err != nil {
+			h.logger.Error("StartLogin: failed start oidc login", zap.Error(err))
+			errorHandler.HandleError(constants.ErrOIDCLogin, http.StatusBadRequest, err)
+			return
+		}
+
+		setStateCookie(w, stateCookie)
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+	}
+}
+
+func (h *OIDCHandler) StartLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("StartLink: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		redirectURL, stateCookie, err := h.usecase.StartLink(r.Context(), mux.Vars(r)["provider"], login)
+		if err != nil {
+			h.logger.Error("StartLink: failed start oidc link", zap.Error(err))
+			errorHandler.HandleError(constants.ErrOIDCLink, http.StatusBadRequest, err)
+			return
+		}
+
+		setStateCookie(w, stateCookie)
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+	}
+}
+
+func (h *OIDCHandler) Callback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		cookie := cookiesmanager.New(r, h.logger)
+		query := r.URL.Query()
+
+		stateCookie, _ := cookie.Get(oidcStateCookie)
+		cookie.Delete(oidcStateCookie, w)
+
+		if providerError := query.Get("error"); providerError != "" {
+			h.logger.Warn("Callback: provider returned error", zap.String("error", providerError))
+			errorHandler.HandleError(constants.ErrOIDCLogin, http.StatusUnauthorized, nil)
+			return
+		}
+
+		// Only a link needs the user signed in; a login goes without one.
+		signedIn, _ := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+
+		login, err := h.usecase.Callback(r.Context(), mux.Vars(r)["provider"], query.Get("code"), query.Get("state"), stateCookie, signedIn, r)
+		if err != nil {
+			h.logger.Error("Callback: failed oidc login", zap.Error(err))
+			errorHandler.HandleError(constants.ErrOIDCLogin, http.StatusUnauthorized, err)
+			return
+		}
+
+		// The client answers the challenge on /auth/login/2fa like after a
+		// password login.
+		if login.TwoFactorRequired {
+			cookie.Set("two_factor_challenge", login.ChallengeToken, constants.TWO_FACTOR_CHALLENGE_TTL, false, w)
+			http.Redirect(w, r, h.cfg.CLIENT_URL, http.StatusFound)
+			return
+		}
+
+		cookie.Set("token", login.Token, time.Minute*15, true, w)
+		http.Redirect(w, r, h.cfg.CLIENT_URL, http.StatusFound)
+	}
+}
+
+const oidcStateCookie = "oidc_state"
+
+// setStateCookie ties the login to the browser. It has to be sent on the
+// redirect back from the provider, a cross-site top-level navigation, which
+// SameSite=Lax allows.
+func setStateCookie(w http.ResponseWriter, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		MaxAge:   int(constants.OIDC_STATE_TTL.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+}
@@ -0,0 +1,388 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OrganizationUseCaseInterface interface {
+	CreateOrganization(login string, data *dtos.CreateOrganizationRequest) (*entity.Organization, error)
+	GetOrganizations(login string) ([]entity.Organization, error)
+	GetMembers(login string, organizationId uint) ([]entity.OrganizationMember, error)
+	GetTests(login string, organizationId uint, limit, offset int) ([]entity.Test, int64, error)
+	ChangeMemberRole(login string, organizationId uint, userId uint, role string) error
+	RemoveMember(login string, organizationId uint, userId uint) error
+	InviteMember(login string, organizationId uint, data *dtos.InviteMemberRequest) error
+	GetInvites(login string, organizationId uint) ([]entity.OrganizationInvite, error)
+	RevokeInvite(login string, organizationId uint, inviteId uint) error
+	AcceptInvite(login string, token string) (*entity.Organization, error)
+}
+
+type OrganizationHandler struct {
+	logger  *zap.Logger
+	usecase OrganizationUseCaseInterface
+}
+
+func NewOrganizationHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	handler := &OrganizationHandler{
+		logger: logger,
+		usecase: usecases.NewOrganization(
+			repository.NewOrganization(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			mailer.New(cfg, logger),
+			cfg,
+		),
+	}
+
+	router.HandleFunc("/organizations", middleware.IsAuth(handler.GetOrganizations())).Methods(http.MethodGet)
+	router.HandleFunc("/organizations", middleware.IsAuth(middleware.IsVerified(handler.CreateOrganization()))).Methods(http.MethodPost)
+	router.HandleFunc("/organizations/invites/accept", middleware.IsAuth(handler.AcceptInvite())).Methods(http.MethodPost)
+	router.HandleFunc("/organizations/{id}/members", middleware.IsAuth(handler.GetMembers())).Methods(http.MethodGet)
+	router.HandleFunc("/organizations/{id}/members/{userId}", middleware.IsAuth(handler.ChangeMemberRole())).Methods(http.MethodPut)
+	router.HandleFunc("/organizations/{id}/members/{userId}", middleware.IsAuth(handler.RemoveMember())).Methods(http.MethodDelete)
+	router.HandleFunc("/organizations/{id}/invites", middleware.IsAuth(handler.GetInvites())).Methods(http.MethodGet)
+	router.HandleFunc("/organizations/{id}/invites", middleware.IsAuth(handler.InviteMember())).Methods(http.MethodPost)
+	router.HandleFunc("/organizations/{id}/invites/{inviteId}", middleware.IsAuth(handler.RevokeInvite())).Methods(http.MethodDelete)
+	router.HandleFunc("/organizations/{id}/tests", middleware.IsAuth(handler.GetTests(), constants.ScopeReadTests)).Methods(http.MethodGet)
+}
+
+func (h *OrganizationHandler) CreateOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.CreateOrganizationRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("CreateOrganization: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("CreateOrganization: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		organization, err := h.usecase.CreateOrganization(login, &payload)
+		if err != nil {
+			h.logger.Error("CreateOrganization: failed create organization", zap.Error(err))
+			errorHandler.HandleError(constants.ErrCreateOrganization, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, dtos.ToOrganizationResponses([]entity.Organization{*organization})[0]); err != nil {
+			h.logger.Error("CreateOrganization: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) GetOrganizations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetOrganizations: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		organizations, err := h.usecase.GetOrganizations(login)
+		if err != nil {
+			h.logger.Error("GetOrganizations: failed get organizations", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetOrganizations, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToOrganizationResponses(organizations)); err != nil {
+			h.logger.Error("GetOrganizations: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) GetMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		organizationId, login, ok := h.organizationAndLogin(w, r, "GetMembers")
+		if !ok {
+			return
+		}
+
+		members, err := h.usecase.GetMembers(login, organizationId)
+		if err != nil {
+			h.logger.Error("GetMembers: failed get members", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetOrganizations, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToOrganizationMemberResponses(members)); err != nil {
+			h.logger.Error("GetMembers: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) GetTests() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+		limit, offset := pageParams(r)
+
+		organizationId, login, ok := h.organizationAndLogin(w, r, "GetTests")
+		if !ok {
+			return
+		}
+
+		tests, count, err := h.usecase.GetTests(login, organizationId, limit, offset)
+		if err != nil {
+			h.logger.Error("GetTests: failed get organization tests", zap.Error(err))
+			errorHandler.HandleError(constants.ErrorGetAllTests, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.SetGetAllTests(tests, count)); err != nil {
+			h.logger.Error("GetTests: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) ChangeMemberRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ChangeMemberRoleRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ChangeMemberRole: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		organizationId, login, ok := h.organizationAndLogin(w, r, "ChangeMemberRole")
+		if !ok {
+			return
+		}
+
+		userId, err := parseUintVar(r, "userId")
+		if err != nil {
+			h.logger.Error("ChangeMemberRole: failed parse user id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.ChangeMemberRole(login, organizationId, userId, payload.Role); err != nil {
+			h.logger.Error("ChangeMemberRole: failed change member role", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageMembers, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *OrganizationHandler) RemoveMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		organizationId, login, ok := h.organizationAndLogin(w, r, "RemoveMember")
+		if !ok {
+			return
+		}
+
+		userId, err := parseUintVar(r, "userId")
+		if err != nil {
+			h.logger.Error("RemoveMember: failed parse user id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.RemoveMember(login, organizationId, userId); err != nil {
+			h.logger.Error("RemoveMember: failed remove member", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageMembers, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *OrganizationHandler) InviteMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.InviteMemberRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("InviteMember: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		organizationId, login, ok := h.organizationAndLogin(w, r, "InviteMember")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.InviteMember(login, organizationId, &payload); err != nil {
+			h.logger.Error("InviteMember: failed invite member", zap.Error(err))
+			errorHandler.HandleError(constants.ErrInviteMember, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *OrganizationHandler) GetInvites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		organizationId, login, ok := h.organizationAndLogin(w, r, "GetInvites")
+		if !ok {
+			return
+		}
+
+		invites, err := h.usecase.GetInvites(login, organizationId)
+		if err != nil {
+			h.logger.Error("GetInvites: failed get invites", zap.Error(err))
+			errorHandler.HandleError(constants.ErrInviteMember, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToOrganizationInviteResponses(invites)); err != nil {
+			h.logger.Error("GetInvites: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) RevokeInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		organizationId, login, ok := h.organizationAndLogin(w, r, "RevokeInvite")
+		if !ok {
+			return
+		}
+
+		inviteId, err := parseUintVar(r, "inviteId")
+		if err != nil {
+			h.logger.Error("RevokeInvite: failed parse invite id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.RevokeInvite(login, organizationId, inviteId); err != nil {
+			h.logger.Error("RevokeInvite: failed revoke invite", zap.Error(err))
+			errorHandler.HandleError(constants.ErrInviteMember, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *OrganizationHandler) AcceptInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.AcceptInviteRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("AcceptInvite: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("AcceptInvite: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		organization, err := h.usecase.AcceptInvite(login, payload.Token)
+		if err != nil {
+			h.logger.Error("AcceptInvite: failed accept invite", zap.Error(err))
+			errorHandler.HandleError(constants.ErrAcceptInvite, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToOrganizationResponses([]entity.Organization{*organization})[0]); err != nil {
+			h.logger.Error("AcceptInvite: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *OrganizationHandler) organizationAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	organizationId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse organization id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return organizationId, login, true
+}
+
+func parseUintVar(r *http.Request, name string) (uint, error) {
+	value, err := strconv.ParseUint(mux.Vars(r)[name], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(value), nil
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"github.com/server/pkg/mailer"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PasswordUseCaseInterface interface {
+	ForgotPassword(email string) error
+	ResetPassword(data *dtos.ResetPasswordRequest) error
+	ChangePassword(login string, sessionID uint, data *dtos.ChangePasswordRequest) error
+}
+
+type PasswordHandler struct {
+	logger  *zap.Logger
+	usecase PasswordUseCaseInterface
+}
+
+func NewPasswordHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	userRepo := repository.NewUser(db, logger)
+	resetRepo := repository.NewPasswordReset(db)
+	sessionRepo := repository.NewSession(db, logger)
+	cache := cachemanager.New(redis.New())
+	usecase := usecases.NewPassword(userRepo, resetRepo, sessionRepo, cache, mailer.New(cfg, logger), cfg)
+
+	handler := &PasswordHandler{
+		logger:  logger,
+		usecase: usecase,
+	}
+
+	router.HandleFunc("/auth/password/forgot", handler.ForgotPassword()).Methods(http.MethodPost)
+	router.HandleFunc("/auth/password/reset", handler.ResetPassword()).Methods(http.MethodPost)
+	router.HandleFunc("/user/password", middleware.IsAuth(middleware.NotImpersonated(handler.ChangePassword()))).Methods(http.MethodPost)
+}
+
+func (h *PasswordHandler) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ForgotPasswordRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ForgotPassword: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.ForgotPassword(payload.Email); err != nil {
+			h.logger.Error("ForgotPassword: failed create reset token", zap.Error(err))
+			errorHandler.HandleError(constants.ErrForgotPassword, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *PasswordHandler) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ResetPasswordRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ResetPassword: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.ResetPassword(&payload); err != nil {
+			h.logger.Error("ResetPassword: failed reset password", zap.Error(err))
+			errorHandler.HandleError(constants.ErrResetPassword, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *PasswordHandler) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ChangePasswordRequest
+		jsonUtil := json.New(r, h.logger, w)
+		JWT := jwt.NewJwt(h.logger)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ChangePassword: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := JWT.ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("ChangePassword: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		sessionID, err := JWT.ExtractSessionFromToken(r)
+		if err != nil {
+			h.logger.Error("ChangePassword: failed extract session from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.usecase.ChangePassword(login, sessionID, &payload); err != nil {
+			h.logger.Error("ChangePassword: failed change password", zap.Error(err))
+			errorHandler.HandleError(constants.ErrChangePassword, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
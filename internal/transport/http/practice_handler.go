@@ -0,0 +1,193 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PracticeUseCaseInterface interface {
+	UpdateSettings(login string, testId uint, data *dtos.UpdatePracticeRequest) (*dtos.PracticeSettingsResponse, error)
+	GetTestPractice(login string, testId uint) ([]dtos.PracticeQuestion, error)
+	GetDue(login string) ([]dtos.PracticeQuestion, error)
+	Answer(login string, questionId uint, data *dtos.PracticeAnswerRequest) (*dtos.PracticeAnswerResponse, error)
+}
+
+type PracticeHandler struct {
+	logger  *zap.Logger
+	usecase PracticeUseCaseInterface
+}
+
+func NewPracticeHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &PracticeHandler{
+		logger: logger,
+		usecase: usecases.NewPractice(
+			repository.NewPractice(db),
+			repository.NewTestManager(db),
+			repository.NewAttempt(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/practice", middleware.IsAuth(handler.UpdateSettings(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/practice", middleware.IsAuth(handler.GetTestPractice())).Methods(http.MethodGet)
+	router.HandleFunc("/practice/due", middleware.IsAuth(handler.GetDue())).Methods(http.MethodGet)
+	router.HandleFunc("/practice/question/{id}", middleware.IsAuth(handler.Answer())).Methods(http.MethodPost)
+}
+
+func (h *PracticeHandler) UpdateSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdatePracticeRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateSettings: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.idAndLogin(w, r, "UpdateSettings")
+		if !ok {
+			return
+		}
+
+		settings, err := h.usecase.UpdateSettings(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("UpdateSettings: failed update practice settings", zap.Error(err))
+			errorHandler.HandleError(constants.ErrPracticeSettings, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, settings); err != nil {
+			h.logger.Error("UpdateSettings: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *PracticeHandler) GetTestPractice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.idAndLogin(w, r, "GetTestPractice")
+		if !ok {
+			return
+		}
+
+		questions, err := h.usecase.GetTestPractice(login, testId)
+		if err != nil {
+			h.logger.Error("GetTestPractice: failed get practice questions", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetPractice, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, questions); err != nil {
+			h.logger.Error("GetTestPractice: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *PracticeHandler) GetDue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetDue: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		due, err := h.usecase.GetDue(login)
+		if err != nil {
+			h.logger.Error("GetDue: failed get due questions", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetPractice, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, due); err != nil {
+			h.logger.Error("GetDue: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *PracticeHandler) Answer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.PracticeAnswerRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("Answer: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		questionId, login, ok := h.idAndLogin(w, r, "Answer")
+		if !ok {
+			return
+		}
+
+		feedback, err := h.usecase.Answer(login, questionId, &payload)
+		if err != nil {
+			h.logger.Error("Answer: failed check practice answer", zap.Error(err))
+			errorHandler.HandleError(constants.ErrPracticeAnswer, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, feedback); err != nil {
+			h.logger.Error("Answer: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *PracticeHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
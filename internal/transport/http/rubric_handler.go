@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RubricUseCaseInterface interface {
+	GetRubric(login string, questionId uint) (*dtos.RubricResponse, error)
+	SetRubric(login string, questionId uint, data *dtos.UpdateRubricRequest) (*dtos.RubricResponse, error)
+}
+
+type RubricHandler struct {
+	logger  *zap.Logger
+	usecase RubricUseCaseInterface
+}
+
+func NewRubricHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &RubricHandler{
+		logger: logger,
+		usecase: usecases.NewRubric(
+			repository.NewRubric(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/question/{id}/rubric", middleware.IsAuth(handler.GetRubric(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/question/{id}/rubric", middleware.IsAuth(handler.SetRubric(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+}
+
+func (h *RubricHandler) GetRubric() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		questionId, login, ok := h.questionAndLogin(w, r, "GetRubric")
+		if !ok {
+			return
+		}
+
+		rubric, err := h.usecase.GetRubric(login, questionId)
+		if err != nil {
+			h.logger.Error("GetRubric: failed get rubric", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageRubric, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, rubric); err != nil {
+			h.logger.Error("GetRubric: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *RubricHandler) SetRubric() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateRubricRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("SetRubric: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		questionId, login, ok := h.questionAndLogin(w, r, "SetRubric")
+		if !ok {
+			return
+		}
+
+		rubric, err := h.usecase.SetRubric(login, questionId, &payload)
+		if err != nil {
+			h.logger.Error("SetRubric: failed set rubric", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageRubric, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, rubric); err != nil {
+			h.logger.Error("SetRubric: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *RubricHandler) questionAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	questionId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse question id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return questionId, login, true
+}
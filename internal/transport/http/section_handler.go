@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SectionUseCaseInterface interface {
+	SetSections(login string, testId uint, data *dtos.UpdateSectionsRequest) ([]dtos.GetSectionResponse, error)
+	GetState(login string, attemptId uint) (*dtos.SectionStateResponse, error)
+	EnterSection(ctx context.Context, login string, attemptId uint, data *dtos.EnterSectionRequest) (*dtos.SectionStateResponse, error)
+}
+
+type SectionHandler struct {
+	logger  *zap.Logger
+	usecase SectionUseCaseInterface
+}
+
+func NewSectionHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	testManagerRepo := repository.NewTestManager(db)
+	attemptRepo := repository.NewAttempt(db)
+	userRepo := repository.NewUser(db, logger)
+	access := newTestAccess(db)
+	cacheManager := cachemanager.New(redis.New())
+	handler := &SectionHandler{
+		logger: logger,
+		usecase: usecases.NewSection(
+			repository.NewSection(db),
+			testManagerRepo,
+			attemptRepo,
+			userRepo,
+			usecases.NewAttempt(
+				attemptRepo,
+				testManagerRepo,
+				repository.NewAssignment(db),
+				repository.NewGroup(db),
+				userRepo,
+				access,
+				cacheManager,
+			),
+			access,
+			newSandbox(cfg),
+			cacheManager,
+		),
+	}
+
+	router.HandleFunc("/test/{id}/sections", middleware.IsAuth(handler.SetSections(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/attempt/{id}/section", middleware.AllowGuest(handler.GetState())).Methods(http.MethodGet)
+	router.HandleFunc("/attempt/{id}/section", middleware.AllowGuest(middleware.IsVerified(handler.EnterSection()))).Methods(http.MethodPost)
+}
+
+func (h *SectionHandler) SetSections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateSectionsRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("SetSections: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.idAndLogin(w, r, "SetSections")
+		if !ok {
+			return
+		}
+
+		sections, err := h.usecase.SetSections(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("SetSections: failed set sections", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageSections, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, sections); err != nil {
+			h.logger.Error("SetSections: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *SectionHandler) GetState() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		attemptId, login, ok := h.idAndLogin(w, r, "GetState")
+		if !ok {
+			return
+		}
+
+		state, err := h.usecase.GetState(login, attemptId)
+		if err != nil {
+			h.logger.Error("GetState: failed get section state", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetSection, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, state); err != nil {
+			h.logger.Error("GetState: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *SectionHandler) EnterSection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.EnterSectionRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("EnterSection: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		attemptId, login, ok := h.idAndLogin(w, r, "EnterSection")
+		if !ok {
+			return
+		}
+
+		state, err := h.usecase.EnterSection(r.Context(), login, attemptId, &payload)
+		if err != nil {
+			h.logger.Error("EnterSection: failed enter section", zap.Error(err))
+			errorHandler.HandleError(constants.ErrEnterSection, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, state); err != nil {
+			h.logger.Error("EnterSection: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *SectionHandler) idAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	id, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return id, login, true
+}
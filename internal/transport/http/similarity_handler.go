@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/configs"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SimilarityUseCaseInterface interface {
+	GetReport(login string, testId uint) ([]dtos.SimilarityPair, error)
+}
+
+type SimilarityHandler struct {
+	logger  *zap.Logger
+	usecase SimilarityUseCaseInterface
+}
+
+func NewSimilarityHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	handler := &SimilarityHandler{
+		logger: logger,
+		usecase: usecases.NewSimilarity(
+			repository.NewSimilarity(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cfg.SIMILARITY_THRESHOLD,
+		),
+	}
+
+	router.HandleFunc("/test/{id}/similarity-report", middleware.IsAuth(handler.GetReport(), constants.ScopeReadResults)).Methods(http.MethodGet)
+}
+
+func (h *SimilarityHandler) GetReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, err := parseUintVar(r, "id")
+		if err != nil {
+			h.logger.Error("GetReport: failed parse test id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("GetReport: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		report, err := h.usecase.GetReport(login, testId)
+		if err != nil {
+			h.logger.Error("GetReport: failed get similarity report", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetResults, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, report); err != nil {
+			h.logger.Error("GetReport: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
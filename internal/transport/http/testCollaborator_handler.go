@@ -0,0 +1,147 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TestCollaboratorUseCaseInterface interface {
+	GetCollaborators(login string, testId uint) ([]entity.TestCollaborator, error)
+	AddCollaborator(login string, testId uint, data *dtos.AddCollaboratorRequest) error
+	RemoveCollaborator(login string, testId uint, userId uint) error
+}
+
+type TestCollaboratorHandler struct {
+	logger  *zap.Logger
+	usecase TestCollaboratorUseCaseInterface
+}
+
+func NewTestCollaboratorHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &TestCollaboratorHandler{
+		logger: logger,
+		usecase: usecases.NewTestCollaborator(
+			repository.NewTestCollaborator(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			repository.NewOrganization(db),
+			repository.NewTestSharing(db),
+			repository.NewAssignment(db),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/collaborators", middleware.IsAuth(handler.GetCollaborators())).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/collaborators", middleware.IsAuth(handler.AddCollaborator())).Methods(http.MethodPost)
+	router.HandleFunc("/test/{id}/collaborators/{userId}", middleware.IsAuth(handler.RemoveCollaborator())).Methods(http.MethodDelete)
+}
+
+func (h *TestCollaboratorHandler) GetCollaborators() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetCollaborators")
+		if !ok {
+			return
+		}
+
+		collaborators, err := h.usecase.GetCollaborators(login, testId)
+		if err != nil {
+			h.logger.Error("GetCollaborators: failed get collaborators", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetCollaborators, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.ToCollaboratorResponses(collaborators)); err != nil {
+			h.logger.Error("GetCollaborators: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestCollaboratorHandler) AddCollaborator() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.AddCollaboratorRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("AddCollaborator: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "AddCollaborator")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.AddCollaborator(login, testId, &payload); err != nil {
+			h.logger.Error("AddCollaborator: failed add collaborator", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageCollaborators, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestCollaboratorHandler) RemoveCollaborator() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		testId, login, ok := h.testAndLogin(w, r, "RemoveCollaborator")
+		if !ok {
+			return
+		}
+
+		userId, err := parseUintVar(r, "userId")
+		if err != nil {
+			h.logger.Error("RemoveCollaborator: failed parse user id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.RemoveCollaborator(login, testId, userId); err != nil {
+			h.logger.Error("RemoveCollaborator: failed remove collaborator", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageCollaborators, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestCollaboratorHandler) testAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	testId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse test id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return testId, login, true
+}
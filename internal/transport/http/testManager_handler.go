@@ -24,7 +24,7 @@ type UserRepoInterface interface {
 
 type TestMangerUseCaseInterface interface {
 	GetAllTests(userID uint, limit, offset int) ([]entity.Test, int64, error)
-	GetTestById(id uint, userLogin string) (*entity.Test, string, error)
+	GetTestById(id uint, userLogin string, attemptId *uint) (*entity.Test, string, error)
 	CreateTest(data entity.Test) error
 	DeleteTest(id uint, login string) error
 	ChangeActiveStatus(status bool, testId uint, userLogin string) error
@@ -40,7 +40,16 @@ type TestManagerHandler struct {
 func NewTestManagerHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
 	testManagerRepo := repository.NewTestManager(db)
 	userRepo := repository.NewUser(db, logger)
-	service := usecases.NewTestManager(testManagerRepo, userRepo, logger)
+	service := usecases.NewTestManager(
+		testManagerRepo,
+		userRepo,
+		repository.NewOrganization(db),
+		repository.NewTestCollaborator(db),
+		repository.NewTestSharing(db),
+		repository.NewAssignment(db),
+		repository.NewAttempt(db),
+		logger,
+	)
 	handler := &TestManagerHandler{
 		logger:   logger,
 		db:       db,
@@ -48,11 +57,11 @@ func NewTestManagerHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router)
 		userRepo: userRepo,
 	}
 
-	router.HandleFunc("/test/getById/{id}", middleware.IsAuth(handler.GetTestById())).Methods(http.MethodGet)
-	router.HandleFunc("/test/getAll", middleware.IsAuth(handler.GetAll())).Methods(http.MethodPost)
-	router.HandleFunc("/test/create", middleware.IsAuth(handler.CreateTest())).Methods(http.MethodPost)
-	router.HandleFunc("/test/delete/{id}", middleware.IsAuth(handler.DeleteTest())).Methods(http.MethodDelete)
-	router.HandleFunc("/test/changeActive", middleware.IsAuth(handler.ChangeActiveTestStatus())).Methods(http.MethodPut)
+	router.HandleFunc("/test/getById/{id}", middleware.AllowGuest(handler.GetTestById(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/getAll", middleware.IsAuth(handler.GetAll(), constants.ScopeReadTests)).Methods(http.MethodPost)
+	router.HandleFunc("/test/create", middleware.IsAuth(middleware.IsVerified(handler.CreateTest()), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/test/delete/{id}", middleware.IsAuth(handler.DeleteTest(), constants.ScopeWriteTests)).Methods(http.MethodDelete)
+	router.HandleFunc("/test/changeActive", middleware.IsAuth(handler.ChangeActiveTestStatus(), constants.ScopeWriteTests)).Methods(http.MethodPut)
 }
 
 func (s *TestManagerHandler) GetAll() http.HandlerFunc {
@@ -102,7 +111,19 @@ func (s *TestManagerHandler) GetTestById() http.HandlerFunc {
 			return
 		}
 
-		getTest, role, err := s.service.GetTestById(uint(parseId), userLogin)
+		var attemptId *uint
+		if value := r.URL.Query().Get("attempt_id"); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				s.logger.Error("GetTestById: failed parse attempt id", zap.Error(err))
+				errors.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+				return
+			}
+			id := uint(parsed)
+			attemptId = &id
+		}
+
+		getTest, role, err := s.service.GetTestById(uint(parseId), userLogin, attemptId)
 		if err != nil {
 			s.logger.Error("GetTestById: failed get test by id", zap.Error(err))
 			errors.HandleError(constants.GetTestByIdError, http.StatusNotFound, err)
@@ -127,9 +148,9 @@ func (s *TestManagerHandler) CreateTest() http.HandlerFunc {
 		var payload dtos.CreateTestRequest
 		json := json.New(r, s.logger, w)
 
-		if err := json.Decode(&payload); err != nil {
-			s.logger.Error("CreateTest: failed decode request body", zap.Error(err))
-			errors.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		if err := json.DecodeAndValidationBody(&payload); err != nil {
+			s.logger.Error("CreateTest: failed decode and validation request body", zap.Error(err))
+			errors.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
 			return
 		}
 
@@ -216,3 +237,13 @@ func (s *TestManagerHandler) ChangeActiveTestStatus() http.HandlerFunc {
 		w.WriteHeader(http.StatusAccepted)
 	}
 }
+
+// newTestAccess builds the role and access mode resolver shared by test related handlers.
+func newTestAccess(db *gorm.DB) *usecases.TestAccess {
+	return usecases.NewTestAccess(
+		repository.NewOrganization(db),
+		repository.NewTestCollaborator(db),
+		repository.NewTestSharing(db),
+		repository.NewAssignment(db),
+	)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TestReportUseCaseInterface interface {
+	ExportResults(login string, testId uint) ([][]string, error)
+	ItemAnalytics(login string, testId uint) ([]dtos.ItemAnalytics, error)
+}
+
+type TestReportHandler struct {
+	logger  *zap.Logger
+	usecase TestReportUseCaseInterface
+}
+
+func NewTestReportHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &TestReportHandler{
+		logger: logger,
+		usecase: usecases.NewTestReport(
+			repository.NewAttempt(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/results/export", middleware.IsAuth(handler.ExportResults(), constants.ScopeReadResults)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/analytics/items", middleware.IsAuth(handler.ItemAnalytics(), constants.ScopeReadResults)).Methods(http.MethodGet)
+}
+
+func (h *TestReportHandler) ExportResults() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+
+		testId, login, ok := h.testAndLogin(w, r, "ExportResults")
+		if !ok {
+			return
+		}
+
+		rows, err := h.usecase.ExportResults(login, testId)
+		if err != nil {
+			h.logger.Error("ExportResults: failed export results", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetResults, http.StatusForbidden, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"test-%d-results.csv\"", testId))
+		w.WriteHeader(http.StatusOK)
+
+		if err := csv.NewWriter(w).WriteAll(rows); err != nil {
+			h.logger.Error("ExportResults: failed write csv", zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *TestReportHandler) ItemAnalytics() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "ItemAnalytics")
+		if !ok {
+			return
+		}
+
+		items, err := h.usecase.ItemAnalytics(login, testId)
+		if err != nil {
+			h.logger.Error("ItemAnalytics: failed get item analytics", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetResults, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, items); err != nil {
+			h.logger.Error("ItemAnalytics: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestReportHandler) testAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	testId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse test id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return testId, login, true
+}
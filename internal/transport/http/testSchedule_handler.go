@@ -0,0 +1,184 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TestScheduleUseCaseInterface interface {
+	UpdateWindow(login string, testId uint, data *dtos.UpdateTestWindowRequest) error
+	ScheduleChange(login string, testId uint, data *dtos.ScheduleChangeRequest) (*entity.ScheduledChange, error)
+	GetScheduledChanges(login string, testId uint) ([]entity.ScheduledChange, error)
+	CancelScheduledChange(login string, testId uint, changeId uint) error
+}
+
+type TestScheduleHandler struct {
+	logger  *zap.Logger
+	usecase TestScheduleUseCaseInterface
+}
+
+func NewTestScheduleHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	handler := &TestScheduleHandler{
+		logger: logger,
+		usecase: usecases.NewTestSchedule(
+			repository.NewScheduledChange(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+		),
+	}
+
+	router.HandleFunc("/test/{id}/window", middleware.IsAuth(handler.UpdateWindow(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/schedule", middleware.IsAuth(handler.GetScheduledChanges(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/schedule", middleware.IsAuth(handler.ScheduleChange(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/test/{id}/schedule/{changeId}", middleware.IsAuth(handler.CancelScheduledChange(), constants.ScopeWriteTests)).Methods(http.MethodDelete)
+}
+
+func (h *TestScheduleHandler) UpdateWindow() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateTestWindowRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateWindow: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "UpdateWindow")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.UpdateWindow(login, testId, &payload); err != nil {
+			h.logger.Error("UpdateWindow: failed update test window", zap.Error(err))
+			errorHandler.HandleError(constants.ErrChangeTestWindow, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestScheduleHandler) ScheduleChange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.ScheduleChangeRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("ScheduleChange: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "ScheduleChange")
+		if !ok {
+			return
+		}
+
+		change, err := h.usecase.ScheduleChange(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("ScheduleChange: failed schedule change", zap.Error(err))
+			errorHandler.HandleError(constants.ErrScheduleChange, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, change); err != nil {
+			h.logger.Error("ScheduleChange: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestScheduleHandler) GetScheduledChanges() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetScheduledChanges")
+		if !ok {
+			return
+		}
+
+		changes, err := h.usecase.GetScheduledChanges(login, testId)
+		if err != nil {
+			h.logger.Error("GetScheduledChanges: failed get scheduled changes", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetScheduledChanges, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, changes); err != nil {
+			h.logger.Error("GetScheduledChanges: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestScheduleHandler) CancelScheduledChange() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		testId, login, ok := h.testAndLogin(w, r, "CancelScheduledChange")
+		if !ok {
+			return
+		}
+
+		changeId, err := parseUintVar(r, "changeId")
+		if err != nil {
+			h.logger.Error("CancelScheduledChange: failed parse change id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.CancelScheduledChange(login, testId, changeId); err != nil {
+			h.logger.Error("CancelScheduledChange: failed cancel scheduled change", zap.Error(err))
+			errorHandler.HandleError(constants.ErrScheduleChange, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestScheduleHandler) testAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	testId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse test id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return testId, login, true
+}
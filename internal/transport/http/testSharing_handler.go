@@ -0,0 +1,385 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TestSharingUseCaseInterface interface {
+	GetAccess(login string, testId uint) (*dtos.TestAccessResponse, error)
+	UpdateAccess(login string, testId uint, data *dtos.UpdateTestAccessRequest) (*dtos.TestAccessResponse, error)
+	GetInvitees(login string, testId uint) ([]entity.TestInvitee, error)
+	SetInvitees(login string, testId uint, data *dtos.UpdateTestInviteesRequest) ([]entity.TestInvitee, error)
+	CreateInviteLink(login string, testId uint, data *dtos.CreateTestInviteLinkRequest) (*dtos.TestInviteLinkResponse, error)
+	GetInviteLinks(login string, testId uint) ([]dtos.TestInviteLinkResponse, error)
+	RevokeInviteLink(login string, testId uint, linkId uint) error
+	RedeemInviteLink(login string, token string) (uint, error)
+	OpenBySlug(login string, slug string) (uint, error)
+	Unlock(login string, testId uint, code string) error
+}
+
+type TestSharingHandler struct {
+	logger  *zap.Logger
+	usecase TestSharingUseCaseInterface
+}
+
+func NewTestSharingHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
+	handler := &TestSharingHandler{
+		logger: logger,
+		usecase: usecases.NewTestSharing(
+			repository.NewTestSharing(db),
+			repository.NewTestManager(db),
+			repository.NewUser(db, logger),
+			newTestAccess(db),
+			cachemanager.New(redis.New()),
+			cfg,
+		),
+	}
+
+	router.HandleFunc("/test/{id}/access", middleware.IsAuth(handler.GetAccess(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/access", middleware.IsAuth(handler.UpdateAccess(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/invitees", middleware.IsAuth(handler.GetInvitees(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/invitees", middleware.IsAuth(handler.SetInvitees(), constants.ScopeWriteTests)).Methods(http.MethodPut)
+	router.HandleFunc("/test/{id}/inviteLinks", middleware.IsAuth(handler.GetInviteLinks(), constants.ScopeReadTests)).Methods(http.MethodGet)
+	router.HandleFunc("/test/{id}/inviteLinks", middleware.IsAuth(handler.CreateInviteLink(), constants.ScopeWriteTests)).Methods(http.MethodPost)
+	router.HandleFunc("/test/{id}/inviteLinks/{linkId}", middleware.IsAuth(handler.RevokeInviteLink(), constants.ScopeWriteTests)).Methods(http.MethodDelete)
+	router.HandleFunc("/test/{id}/unlock", middleware.IsAuth(middleware.RateLimitBy(handler.Unlock(), "test-unlock", middleware.UserAndTestKey, constants.UNLOCK_LIMIT, constants.UNLOCK_WINDOW))).Methods(http.MethodPost)
+	router.HandleFunc("/test/invite/redeem", middleware.IsAuth(handler.RedeemInviteLink())).Methods(http.MethodPost)
+	router.HandleFunc("/test/bySlug/{slug}", middleware.IsAuth(handler.OpenBySlug(), constants.ScopeReadTests)).Methods(http.MethodGet)
+}
+
+func (h *TestSharingHandler) GetAccess() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetAccess")
+		if !ok {
+			return
+		}
+
+		access, err := h.usecase.GetAccess(login, testId)
+		if err != nil {
+			h.logger.Error("GetAccess: failed get test access", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, access); err != nil {
+			h.logger.Error("GetAccess: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) UpdateAccess() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateTestAccessRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("UpdateAccess: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "UpdateAccess")
+		if !ok {
+			return
+		}
+
+		access, err := h.usecase.UpdateAccess(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("UpdateAccess: failed update test access", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, access); err != nil {
+			h.logger.Error("UpdateAccess: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) GetInvitees() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetInvitees")
+		if !ok {
+			return
+		}
+
+		invitees, err := h.usecase.GetInvitees(login, testId)
+		if err != nil {
+			h.logger.Error("GetInvitees: failed get invitees", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, invitees); err != nil {
+			h.logger.Error("GetInvitees: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) SetInvitees() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UpdateTestInviteesRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("SetInvitees: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "SetInvitees")
+		if !ok {
+			return
+		}
+
+		invitees, err := h.usecase.SetInvitees(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("SetInvitees: failed set invitees", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, invitees); err != nil {
+			h.logger.Error("SetInvitees: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) CreateInviteLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.CreateTestInviteLinkRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("CreateInviteLink: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "CreateInviteLink")
+		if !ok {
+			return
+		}
+
+		link, err := h.usecase.CreateInviteLink(login, testId, &payload)
+		if err != nil {
+			h.logger.Error("CreateInviteLink: failed create invite link", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusCreated, link); err != nil {
+			h.logger.Error("CreateInviteLink: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) GetInviteLinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		testId, login, ok := h.testAndLogin(w, r, "GetInviteLinks")
+		if !ok {
+			return
+		}
+
+		links, err := h.usecase.GetInviteLinks(login, testId)
+		if err != nil {
+			h.logger.Error("GetInviteLinks: failed get invite links", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, links); err != nil {
+			h.logger.Error("GetInviteLinks: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) RevokeInviteLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		testId, login, ok := h.testAndLogin(w, r, "RevokeInviteLink")
+		if !ok {
+			return
+		}
+
+		linkId, err := parseUintVar(r, "linkId")
+		if err != nil {
+			h.logger.Error("RevokeInviteLink: failed parse link id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.usecase.RevokeInviteLink(login, testId, linkId); err != nil {
+			h.logger.Error("RevokeInviteLink: failed revoke invite link", zap.Error(err))
+			errorHandler.HandleError(constants.ErrManageTestAccess, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestSharingHandler) Unlock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.UnlockTestRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("Unlock: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		testId, login, ok := h.testAndLogin(w, r, "Unlock")
+		if !ok {
+			return
+		}
+
+		if err := h.usecase.Unlock(login, testId, payload.AccessCode); err != nil {
+			h.logger.Error("Unlock: failed unlock test", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTestAccessCode, http.StatusForbidden, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *TestSharingHandler) RedeemInviteLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.RedeemTestInviteRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("RedeemInviteLink: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("RedeemInviteLink: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		testId, err := h.usecase.RedeemInviteLink(login, payload.Token)
+		if err != nil {
+			h.logger.Error("RedeemInviteLink: failed redeem invite link", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTestInviteLink, http.StatusForbidden, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.OpenTestResponse{TestID: testId}); err != nil {
+			h.logger.Error("RedeemInviteLink: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) OpenBySlug() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("OpenBySlug: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		testId, err := h.usecase.OpenBySlug(login, mux.Vars(r)["slug"])
+		if err != nil {
+			h.logger.Error("OpenBySlug: failed open test by slug", zap.Error(err))
+			errorHandler.HandleError(constants.GetTestByIdError, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, dtos.OpenTestResponse{TestID: testId}); err != nil {
+			h.logger.Error("OpenBySlug: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TestSharingHandler) testAndLogin(w http.ResponseWriter, r *http.Request, method string) (uint, string, bool) {
+	errorHandler := errorshandler.New(h.logger, w, r)
+	testId, err := parseUintVar(r, "id")
+	if err != nil {
+		h.logger.Error(method+": failed parse test id", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+		return 0, "", false
+	}
+
+	login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+	if err != nil {
+		h.logger.Error(method+": failed extract user from token", zap.Error(err))
+		errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+		return 0, "", false
+	}
+
+	return testId, login, true
+}
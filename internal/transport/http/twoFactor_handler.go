@@ -0,0 +1,140 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/dtos"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	errorshandler "github.com/server/pkg/errorsHandler"
+	"github.com/server/pkg/json"
+	"github.com/server/pkg/jwt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TwoFactorUseCaseInterface interface {
+	Enroll(login string) (*dtos.TwoFactorEnrollResponse, error)
+	Confirm(login string, code string) (*dtos.TwoFactorConfirmResponse, error)
+	Disable(login string, data *dtos.TwoFactorDisableRequest) error
+}
+
+type TwoFactorHandler struct {
+	logger  *zap.Logger
+	usecase TwoFactorUseCaseInterface
+}
+
+func NewTwoFactorHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router) {
+	userRepo := repository.NewUser(db, logger)
+	recoveryRepo := repository.NewTwoFactor(db)
+	cache := cachemanager.New(redis.New())
+
+	handler := &TwoFactorHandler{
+		logger:  logger,
+		usecase: usecases.NewTwoFactor(userRepo, recoveryRepo, cache),
+	}
+
+	router.HandleFunc("/user/2fa/enroll", middleware.IsAuth(middleware.NotImpersonated(handler.Enroll()))).Methods(http.MethodPost)
+	router.HandleFunc("/user/2fa/confirm", middleware.IsAuth(middleware.NotImpersonated(handler.Confirm()))).Methods(http.MethodPost)
+	router.HandleFunc("/user/2fa/disable", middleware.IsAuth(middleware.NotImpersonated(handler.Disable()))).Methods(http.MethodPost)
+}
+
+func (h *TwoFactorHandler) Enroll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		jsonUtil := json.New(r, h.logger, w)
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("Enroll: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, err := h.usecase.Enroll(login)
+		if err != nil {
+			h.logger.Error("Enroll: failed start two factor enrollment", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTwoFactorEnroll, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, result); err != nil {
+			h.logger.Error("Enroll: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TwoFactorHandler) Confirm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.TwoFactorConfirmRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("Confirm: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("Confirm: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, err := h.usecase.Confirm(login, payload.Code)
+		if err != nil {
+			h.logger.Error("Confirm: failed confirm two factor enrollment", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTwoFactorCode, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := jsonUtil.Encode(http.StatusOK, result); err != nil {
+			h.logger.Error("Confirm: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *TwoFactorHandler) Disable() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(h.logger, w, r)
+		var payload dtos.TwoFactorDisableRequest
+		jsonUtil := json.New(r, h.logger, w)
+
+		if err := jsonUtil.DecodeAndValidationBody(&payload); err != nil {
+			h.logger.Error("Disable: failed decode and validation request body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(h.logger).ExtractUserFromToken(r)
+		if err != nil {
+			h.logger.Error("Disable: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.usecase.Disable(login, &payload); err != nil {
+			h.logger.Error("Disable: failed disable two factor authentication", zap.Error(err))
+			errorHandler.HandleError(constants.ErrTwoFactorDisable, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -32,13 +33,15 @@ type UserRepoInterfaceV2 interface {
 	GetUserByEmail(email string) (*entity.User, error)
 	UpdateUser(user *dtos.UpdateUserRequest) error
 	CreateUser(user entity.User) error
-	DeleteRefreshToken(login string) error
 }
 
 type UserUseCaseInterface interface {
 	UpdateUserData(user dtos.UpdateUserRequest) error
 	Logout(w http.ResponseWriter, r *http.Request, logger *zap.Logger) error
 	FindUserByLogin(login string) (*entity.User, error)
+	LogoutEverywhere(w http.ResponseWriter, r *http.Request, logger *zap.Logger) error
+	GetSessions(login string) ([]entity.Session, error)
+	RevokeSession(sessionID uint, login string, r *http.Request) error
 }
 
 type User struct {
@@ -53,9 +56,10 @@ type User struct {
 
 func NewUserHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *configs.Config) {
 	repo := repository.NewUser(db, logger)
+	sessionRepo := repository.NewSession(db, logger)
 	rdb := redis.New()
 	cache := cachemanager.New(rdb)
-	usecase := usecases.NewUser(repo, cache)
+	usecase := usecases.NewUser(repo, sessionRepo, repository.NewAuditLog(db), cache)
 	handler := &User{
 		logger:     logger,
 		db:         db,
@@ -70,6 +74,9 @@ func NewUserHandler(logger *zap.Logger, db *gorm.DB, router *mux.Router, cfg *co
 	router.HandleFunc("/user/update", middleware.IsAuth(handler.UpdateUser())).Methods(http.MethodPost)
 	router.HandleFunc("/user/getByLogin", middleware.IsAuth(handler.GetUserByLogin())).Methods(http.MethodPost)
 	router.HandleFunc("/user/logout", middleware.IsAuth(handler.Logout())).Methods(http.MethodGet)
+	router.HandleFunc("/user/sessions", middleware.IsAuth(handler.GetSessions())).Methods(http.MethodGet)
+	router.HandleFunc("/user/sessions", middleware.IsAuth(handler.LogoutEverywhere())).Methods(http.MethodDelete)
+	router.HandleFunc("/user/sessions/{id}", middleware.IsAuth(handler.RevokeSession())).Methods(http.MethodDelete)
 }
 
 func (s *User) GetUserData() http.HandlerFunc {
@@ -169,3 +176,82 @@ func (s *User) Logout() http.HandlerFunc {
 		}
 	}
 }
+
+func (s *User) LogoutEverywhere() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(s.logger, w, r)
+
+		if err := s.usecase.LogoutEverywhere(w, r, s.logger); err != nil {
+			s.logger.Error("LogoutEverywhere: failed logout everywhere", zap.Error(err))
+			errorHandler.HandleError(constants.ErrLogout, http.StatusBadRequest, err)
+			return
+		}
+	}
+}
+
+func (s *User) GetSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(s.logger, w, r)
+		JWT := jwt.NewJwt(s.logger)
+		jsonHelper := json.New(r, s.logger, w)
+
+		login, err := JWT.ExtractUserFromToken(r)
+		if err != nil {
+			s.logger.Error("GetSessions: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		currentSessionID, err := JWT.ExtractSessionFromToken(r)
+		if err != nil {
+			s.logger.Error("GetSessions: failed extract session from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		sessions, err := s.usecase.GetSessions(login)
+		if err != nil {
+			s.logger.Error("GetSessions: failed get user sessions", zap.Error(err))
+			errorHandler.HandleError(constants.ErrGetSessions, http.StatusNotFound, err)
+			return
+		}
+
+		if err := jsonHelper.Encode(http.StatusOK, dtos.ToSessionResponses(sessions, currentSessionID)); err != nil {
+			s.logger.Error("GetSessions: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (s *User) RevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		errorHandler := errorshandler.New(s.logger, w, r)
+		parseId, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			s.logger.Error("RevokeSession: failed parse session id", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusBadRequest, err)
+			return
+		}
+
+		login, err := jwt.NewJwt(s.logger).ExtractUserFromToken(r)
+		if err != nil {
+			s.logger.Error("RevokeSession: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := s.usecase.RevokeSession(uint(parseId), login, r); err != nil {
+			s.logger.Error("RevokeSession: failed revoke session", zap.Error(err))
+			errorHandler.HandleError(constants.ErrRevokeSession, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -1,25 +1,27 @@
 package http
 
 import (
+	"context"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/mux"
-	"github.com/server/entity"
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/configs"
 	"github.com/server/internal/dtos"
 	"github.com/server/internal/repository"
 	"github.com/server/internal/transport/http/middleware"
 	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
 	"github.com/server/pkg/constants"
 	errorshandler "github.com/server/pkg/errorsHandler"
 	"github.com/server/pkg/json"
-	mapjson "github.com/server/pkg/mapJson"
+	"github.com/server/pkg/jwt"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type TestValidatorUseCaseInterface interface {
-	Validate(test *entity.Test) (*float64, error)
+	Validate(ctx context.Context, login string, data *dtos.ValidateResultRequestPayload) (*dtos.ValidateResultResponse, error)
 }
 
 type ValidateResult struct {
@@ -29,16 +31,34 @@ type ValidateResult struct {
 	logger  *zap.Logger
 }
 
-func NewValidateResultHandler(db *gorm.DB, router *mux.Router, logger *zap.Logger) {
+func NewValidateResultHandler(db *gorm.DB, router *mux.Router, logger *zap.Logger, cfg *configs.Config) {
 	testManagerRepo := repository.NewTestManager(db)
+	userRepo := repository.NewUser(db, logger)
+	attemptRepo := repository.NewAttempt(db)
+	access := newTestAccess(db)
 	handler := &ValidateResult{
-		db:      db,
-		router:  router,
-		logger:  logger,
-		service: usecases.NewTestValidator(testManagerRepo),
+		db:     db,
+		router: router,
+		logger: logger,
+		service: usecases.NewTestValidator(
+			testManagerRepo,
+			userRepo,
+			attemptRepo,
+			usecases.NewAttempt(
+				attemptRepo,
+				testManagerRepo,
+				repository.NewAssignment(db),
+				repository.NewGroup(db),
+				userRepo,
+				access,
+				cachemanager.New(redis.New()),
+			),
+			access,
+			newSandbox(cfg),
+		),
 	}
 
-	handler.router.HandleFunc("/api/test/validate", middleware.IsAuth(handler.ValidateResult())).Methods(http.MethodPost)
+	handler.router.HandleFunc("/api/test/validate", middleware.AllowGuest(middleware.IsVerified(handler.ValidateResult()))).Methods(http.MethodPost)
 }
 
 func (s *ValidateResult) ValidateResult() http.HandlerFunc {
@@ -48,7 +68,6 @@ func (s *ValidateResult) ValidateResult() http.HandlerFunc {
 		var payload dtos.ValidateResultRequestPayload
 		errorHandler := errorshandler.New(s.logger, w, r)
 		jsonDecodeAndEncode := json.New(r, s.logger, w)
-		jsonResponse := mapjson.New(s.logger, w, r)
 
 		if err := jsonDecodeAndEncode.Decode(&payload); err != nil {
 			s.logger.Error("ValidateResult: failed decode and validation body", zap.Error(err))
@@ -56,13 +75,24 @@ func (s *ValidateResult) ValidateResult() http.HandlerFunc {
 			return
 		}
 
-		result, err := s.service.Validate(payload.Test)
+		login, err := jwt.NewJwt(s.logger).ExtractUserFromToken(r)
+		if err != nil {
+			s.logger.Error("ValidateResult: failed extract user from token", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, err := s.service.Validate(r.Context(), login, &payload)
 		if err != nil {
 			s.logger.Error("ValidateResult: failed validate test result", zap.Error(err))
 			errorHandler.HandleError(constants.ErrTestValidation, http.StatusBadRequest, err)
 			return
 		}
 
-		jsonResponse.JsonSuccess(strconv.FormatFloat(*result, 'f', 2, 64))
+		if err := jsonDecodeAndEncode.Encode(http.StatusOK, result); err != nil {
+			s.logger.Error("ValidateResult: failed encode response body", zap.Error(err))
+			errorHandler.HandleError(constants.InternalServerError, http.StatusInternalServerError, err)
+			return
+		}
 	}
 }
@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"github.com/server/adapters/storage/redis"
+	"github.com/server/internal/repository"
+	"github.com/server/internal/usecases"
+	cachemanager "github.com/server/pkg/cacheManager"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/scheduler"
+)
+
+// FillJobs registers background jobs that run alongside the http server.
+func (s *api) FillJobs() *scheduler.Scheduler {
+	jobs := scheduler.New(s.log)
+
+	access := usecases.NewTestAccess(
+		repository.NewOrganization(s.db),
+		repository.NewTestCollaborator(s.db),
+		repository.NewTestSharing(s.db),
+		repository.NewAssignment(s.db),
+	)
+
+	testSchedule := usecases.NewTestSchedule(
+		repository.NewScheduledChange(s.db),
+		repository.NewTestManager(s.db),
+		repository.NewUser(s.db, s.log),
+		access,
+		cachemanager.New(redis.New()),
+	)
+	jobs.Add("scheduled test changes", constants.SCHEDULED_CHANGES_INTERVAL, testSchedule.ApplyDueChanges)
+
+	similarity := usecases.NewSimilarity(
+		repository.NewSimilarity(s.db),
+		repository.NewTestManager(s.db),
+		repository.NewUser(s.db, s.log),
+		access,
+		s.cfg.SIMILARITY_THRESHOLD,
+	)
+	jobs.Add("answer similarity", constants.SIMILARITY_CHECK_INTERVAL, similarity.DetectSimilarity)
+
+	attemptRepo := repository.NewAttempt(s.db)
+	userRepo := repository.NewUser(s.db, s.log)
+	testManagerRepo := repository.NewTestManager(s.db)
+	cacheManager := cachemanager.New(redis.New())
+	adaptive := usecases.NewAdaptive(
+		repository.NewAdaptive(s.db),
+		testManagerRepo,
+		attemptRepo,
+		userRepo,
+		usecases.NewAttempt(
+			attemptRepo,
+			testManagerRepo,
+			repository.NewAssignment(s.db),
+			repository.NewGroup(s.db),
+			userRepo,
+			access,
+			cacheManager,
+		),
+		access,
+		cacheManager,
+	)
+	jobs.Add("item calibration", constants.ITEM_CALIBRATION_INTERVAL, adaptive.CalibrateAll)
+
+	return jobs
+}
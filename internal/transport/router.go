@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -8,6 +9,7 @@ import (
 	"github.com/server/configs"
 	delivery "github.com/server/internal/transport/http"
 	"github.com/server/internal/transport/http/middleware"
+	"github.com/server/internal/usecases"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -28,11 +30,17 @@ func New(db *gorm.DB, logger *zap.Logger, cfg *configs.Config) *api {
 		router: router,
 		db:     db,
 		log:    logger,
+		cfg:    cfg,
 	}
 }
 
 func (s *api) RunApp() error {
+	usecases.SetTrustedProxies(s.cfg.TRUSTED_PROXIES)
 	s.FillEndpoints()
+	jobs := s.FillJobs()
+	jobs.Start(context.Background())
+	defer jobs.Stop()
+
 	handler := middleware.DefaultCORSMiddleware()(s.router)
 	middleware.TraceLogger(handler)
 	s.log.Info("Server started")
@@ -42,7 +50,30 @@ func (s *api) RunApp() error {
 func (s *api) FillEndpoints() {
 	delivery.NewAuthHandler(s.router, s.log, s.db, s.cfg)
 	delivery.NewTestManagerHandler(s.log, s.db, s.router)
-	delivery.NewValidateResultHandler(s.db, s.router, s.log)
+	delivery.NewValidateResultHandler(s.db, s.router, s.log, s.cfg)
 	delivery.NewUserHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewPasswordHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewTwoFactorHandler(s.log, s.db, s.router)
+	delivery.NewOIDCHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewJWKSHandler(s.log, s.router)
+	delivery.NewAPIKeyHandler(s.log, s.db, s.router)
+	delivery.NewAdminHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewOrganizationHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewTestCollaboratorHandler(s.log, s.db, s.router)
+	delivery.NewGroupHandler(s.log, s.db, s.router)
+	delivery.NewAssignmentHandler(s.log, s.db, s.router)
+	delivery.NewTestScheduleHandler(s.log, s.db, s.router)
+	delivery.NewAttemptHandler(s.log, s.db, s.router)
+	delivery.NewTestSharingHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewGuestHandler(s.log, s.db, s.router)
+	delivery.NewAttemptReviewHandler(s.log, s.db, s.router)
+	delivery.NewAttemptGradingHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewRubricHandler(s.log, s.db, s.router)
+	delivery.NewCodeQuestionHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewTestReportHandler(s.log, s.db, s.router)
+	delivery.NewSimilarityHandler(s.log, s.db, s.router, s.cfg)
+	delivery.NewAdaptiveHandler(s.log, s.db, s.router)
+	delivery.NewPracticeHandler(s.log, s.db, s.router)
+	delivery.NewSectionHandler(s.log, s.db, s.router, s.cfg)
 	s.router.Handle("/metrics", promhttp.Handler())
 }
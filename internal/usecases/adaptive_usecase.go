@@ -0,0 +1,468 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/irt"
+)
+
+type AdaptiveRepoInterface interface {
+	GetAdaptiveTestIds() ([]uint, error)
+	UpdateItemParameters(questions []entity.Question, calibratedAt time.Time) error
+	SaveAdaptiveStep(attemptId uint, currentQuestionId *uint, answers []entity.AttemptAnswer, ability float64, abilitySE float64, nextQuestionId *uint, submittedAt *time.Time) error
+}
+
+type AdaptiveTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdateAdaptiveSettings(testId uint, adaptive bool, model string, maxItems int, targetSE float64) error
+	IncrementCountUserPast(testId uint, count int) error
+}
+
+type AdaptiveAttemptRepoInterface interface {
+	GetAttemptById(id uint) (*entity.Attempt, error)
+	GetAttemptAnswers(attemptId uint) ([]entity.AttemptAnswer, error)
+	GetTestAnswers(testId uint) ([]entity.AttemptAnswer, error)
+}
+
+type Adaptive struct {
+	adaptiveRepo AdaptiveRepoInterface
+	testRepo     AdaptiveTestRepoInterface
+	attemptRepo  AdaptiveAttemptRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	attempts     AttemptOpenerInterface
+	access       TestAccessInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewAdaptive(
+	adaptiveRepo AdaptiveRepoInterface,
+	testRepo AdaptiveTestRepoInterface,
+	attemptRepo AdaptiveAttemptRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	attempts AttemptOpenerInterface,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *Adaptive {
+	return &Adaptive{
+		adaptiveRepo: adaptiveRepo,
+		testRepo:     testRepo,
+		attemptRepo:  attemptRepo,
+		userRepo:     userRepo,
+		attempts:     attempts,
+		access:       access,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *Adaptive) UpdateSettings(login string, testId uint, data *dtos.UpdateAdaptiveSettingsRequest) (*dtos.AdaptiveSettingsResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateSettings: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("UpdateSettings: user %s can not edit test %d", login, testId)
+	}
+
+	if data.Adaptive && len(test.Sections) > 0 {
+		return nil, fmt.Errorf("UpdateSettings: test %d has sections", testId)
+	}
+
+	if data.Adaptive && len(adaptiveItems(test)) == 0 {
+		return nil, fmt.Errorf("UpdateSettings: test %d has no choice or numeric questions", testId)
+	}
+
+	if err := s.testRepo.UpdateAdaptiveSettings(test.ID, data.Adaptive, data.Model, data.MaxItems, data.TargetSE); err != nil {
+		return nil, fmt.Errorf("UpdateSettings: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("UpdateSettings: failed to invalidate test cache: %w", err)
+	}
+
+	return &dtos.AdaptiveSettingsResponse{
+		TestID:   test.ID,
+		Adaptive: data.Adaptive,
+		Model:    data.Model,
+		MaxItems: data.MaxItems,
+		TargetSE: data.TargetSE,
+	}, nil
+}
+
+// Calibrate fits the item parameters of a test right away instead of
+// waiting for the calibration job.
+func (s *Adaptive) Calibrate(login string, testId uint) (*dtos.CalibrationResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("Calibrate: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("Calibrate: user %s can not edit test %d", login, testId)
+	}
+
+	items, err := s.calibrate(test, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("Calibrate: %w", err)
+	}
+
+	return &dtos.CalibrationResponse{
+		TestID: test.ID,
+		Model:  test.AdaptiveModel,
+		Items:  items,
+	}, nil
+}
+
+// CalibrateAll refits the item parameters of every adaptive test.
+func (s *Adaptive) CalibrateAll(ctx context.Context) error {
+	ids, err := s.adaptiveRepo.GetAdaptiveTestIds()
+	if err != nil {
+		return fmt.Errorf("CalibrateAll: %w", err)
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		test, err := s.testRepo.GetTestById(id)
+		if err != nil {
+			return fmt.Errorf("CalibrateAll: %w", err)
+		}
+
+		if _, err := s.calibrate(test, now); err != nil {
+			return fmt.Errorf("CalibrateAll: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// calibrate fits the choice and numeric questions of a test to the answers
+// of its submitted attempts, adaptive or not. Questions with fewer than
+// ITEM_CALIBRATION_MIN_RESPONSES answers keep their parameters.
+func (s *Adaptive) calibrate(test *entity.Test, now time.Time) ([]dtos.ItemParameters, error) {
+	answers, err := s.attemptRepo.GetTestAnswers(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("calibrate: %w", err)
+	}
+
+	questions := adaptiveItems(test)
+	columns := make(map[uint]int, len(questions))
+	for i, question := range questions {
+		columns[question.ID] = i
+	}
+
+	byAttempt := make(map[uint][]entity.AttemptAnswer)
+	var order []uint
+	for _, answer := range answers {
+		if _, ok := columns[answer.QuestionID]; !ok {
+			continue
+		}
+		if _, ok := byAttempt[answer.AttemptID]; !ok {
+			order = append(order, answer.AttemptID)
+		}
+		byAttempt[answer.AttemptID] = append(byAttempt[answer.AttemptID], answer)
+	}
+
+	counts := make([]int, len(questions))
+	responses := make([][]int8, 0, len(order))
+	for _, attemptId := range order {
+		row := make([]int8, len(questions))
+		for i := range row {
+			row[i] = irt.Missing
+		}
+
+		asked, correct := answerCorrectness(byAttempt[attemptId])
+		for _, questionId := range asked {
+			column := columns[questionId]
+			row[column] = 0
+			if correct[questionId] {
+				row[column] = 1
+			}
+			counts[column]++
+		}
+		responses = append(responses, row)
+	}
+
+	var fitted []irt.Item
+	if len(responses) > 0 {
+		fitted = irt.Calibrate(responses, len(questions), test.AdaptiveModel == constants.AdaptiveModel2PL)
+	}
+
+	var updated []entity.Question
+	items := make([]dtos.ItemParameters, len(questions))
+	for i, question := range questions {
+		if counts[i] >= constants.ITEM_CALIBRATION_MIN_RESPONSES {
+			question.Difficulty = fitted[i].Difficulty
+			question.Discrimination = fitted[i].Discrimination
+			question.CalibratedAt = &now
+			updated = append(updated, question)
+		}
+
+		items[i] = dtos.ItemParameters{
+			QuestionID:     question.ID,
+			Name:           question.Name,
+			Difficulty:     question.Difficulty,
+			Discrimination: question.Discrimination,
+			Responses:      counts[i],
+			CalibratedAt:   question.CalibratedAt,
+		}
+	}
+
+	if len(updated) == 0 {
+		return items, nil
+	}
+
+	if err := s.adaptiveRepo.UpdateItemParameters(updated, now); err != nil {
+		return nil, fmt.Errorf("calibrate: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("calibrate: failed to invalidate test cache: %w", err)
+	}
+
+	return items, nil
+}
+
+// NextQuestion takes the answer to the question the attempt was asked last,
+// estimates the ability of the taker and asks the most informative question
+// not asked yet. The attempt is submitted once the standard error reaches
+// the target of the test, the maximum number of questions was asked or no
+// question is left.
+func (s *Adaptive) NextQuestion(login string, attemptId uint, data *dtos.NextQuestionRequest) (*dtos.NextQuestionResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: failed get user by login: %w", err)
+	}
+
+	found, err := s.attemptRepo.GetAttemptById(attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(found.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	if !test.Adaptive {
+		return nil, fmt.Errorf("NextQuestion: test %d is not adaptive", test.ID)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	now := time.Now()
+	if !canViewPrivateTest(role) && !isTestAvailable(test, now) {
+		return nil, fmt.Errorf("NextQuestion: test is closed")
+	}
+
+	attempt, err := s.attempts.OpenAttempt(user, test, &attemptId, nil, now)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	answers, err := s.attemptRepo.GetAttemptAnswers(attempt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	questions := questionsById(test)
+
+	if data.QuestionID == 0 && attempt.CurrentQuestionID != nil {
+		question, ok := questions[*attempt.CurrentQuestionID]
+		if !ok {
+			return nil, fmt.Errorf("NextQuestion: question %d was removed from test %d", *attempt.CurrentQuestionID, test.ID)
+		}
+		asked, _ := answerCorrectness(answers)
+		return nextQuestionResponse(attempt, &question, len(asked), attempt.Ability, attempt.AbilitySE), nil
+	}
+
+	var given []entity.AttemptAnswer
+	if data.QuestionID != 0 {
+		if attempt.CurrentQuestionID == nil || *attempt.CurrentQuestionID != data.QuestionID {
+			return nil, fmt.Errorf("NextQuestion: question %d is not the current question of attempt %d", data.QuestionID, attempt.ID)
+		}
+
+		given, err = gradeAdaptiveAnswer(questions[data.QuestionID], attempt, data, now)
+		if err != nil {
+			return nil, fmt.Errorf("NextQuestion: %w", err)
+		}
+		answers = append(answers, given...)
+	}
+
+	asked, correct := answerCorrectness(answers)
+	items := make([]irt.Item, len(asked))
+	results := make([]bool, len(asked))
+	for i, questionId := range asked {
+		items[i] = itemOf(test, questions[questionId])
+		results[i] = correct[questionId]
+	}
+	ability, abilitySE := irt.Estimate(items, results)
+
+	var next *entity.Question
+	limited := test.AdaptiveMaxItems > 0 && len(asked) >= test.AdaptiveMaxItems
+	precise := test.AdaptiveTargetSE > 0 && len(asked) > 0 && abilitySE <= test.AdaptiveTargetSE
+	if !limited && !precise {
+		next = mostInformative(test, asked, ability)
+	}
+
+	if next == nil {
+		if err := s.adaptiveRepo.SaveAdaptiveStep(attempt.ID, attempt.CurrentQuestionID, given, ability, abilitySE, nil, &now); err != nil {
+			return nil, fmt.Errorf("NextQuestion: %w", err)
+		}
+
+		if err := s.testRepo.IncrementCountUserPast(test.ID, int(test.CountUserPast)); err != nil {
+			return nil, fmt.Errorf("NextQuestion: failed to increment count user past: %w", err)
+		}
+
+		return nextQuestionResponse(attempt, nil, len(asked), &ability, &abilitySE), nil
+	}
+
+	if err := s.adaptiveRepo.SaveAdaptiveStep(attempt.ID, attempt.CurrentQuestionID, given, ability, abilitySE, &next.ID, nil); err != nil {
+		return nil, fmt.Errorf("NextQuestion: %w", err)
+	}
+
+	return nextQuestionResponse(attempt, next, len(asked), &ability, &abilitySE), nil
+}
+
+func (s *Adaptive) testAndRole(login string, testId uint) (*entity.Test, *entity.User, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed get test by id: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return test, user, role, nil
+}
+
+// gradeAdaptiveAnswer grades the answer to one question the way Validate
+// does: one row per variant of a choice question, one for a number.
+func gradeAdaptiveAnswer(question entity.Question, attempt *entity.Attempt, data *dtos.NextQuestionRequest, now time.Time) ([]entity.AttemptAnswer, error) {
+	if question.Type == constants.QuestionNumeric {
+		return []entity.AttemptAnswer{gradeNumeric(question, attempt, data.Value, now)}, nil
+	}
+
+	for _, variantId := range data.Selected {
+		if !slices.ContainsFunc(question.Variants, func(variant entity.Variant) bool { return variant.ID == variantId }) {
+			return nil, fmt.Errorf("gradeAdaptiveAnswer: variant %d does not belong to question %d", variantId, question.ID)
+		}
+	}
+
+	answers := make([]entity.AttemptAnswer, len(question.Variants))
+	for i, variant := range question.Variants {
+		selected := slices.Contains(data.Selected, variant.ID)
+		correct := variant.IsCorrect == selected
+		points := 0.0
+		if correct {
+			points = 1
+		}
+		answers[i] = entity.AttemptAnswer{
+			AttemptID:  attempt.ID,
+			QuestionID: question.ID,
+			VariantID:  variant.ID,
+			Selected:   selected,
+			Correct:    correct,
+			Points:     &points,
+		}
+	}
+
+	return answers, nil
+}
+
+// answerCorrectness returns the questions in the order they were answered
+// and whether each was answered right. A choice question is right when
+// every variant was marked right.
+func answerCorrectness(answers []entity.AttemptAnswer) ([]uint, map[uint]bool) {
+	var asked []uint
+	correct := make(map[uint]bool)
+	for _, answer := range answers {
+		right, seen := correct[answer.QuestionID]
+		if !seen {
+			asked = append(asked, answer.QuestionID)
+			right = true
+		}
+		correct[answer.QuestionID] = right && answer.Correct
+	}
+	return asked, correct
+}
+
+// adaptiveItems are the questions of a test an adaptive attempt can ask.
+// Text and code answers are not graded right away, so they are left out.
+func adaptiveItems(test *entity.Test) []entity.Question {
+	var items []entity.Question
+	for _, question := range test.Questions {
+		if question.Type == constants.QuestionNumeric || (question.Type == constants.QuestionChoice && len(question.Variants) > 0) {
+			items = append(items, question)
+		}
+	}
+	return items
+}
+
+func itemOf(test *entity.Test, question entity.Question) irt.Item {
+	item := irt.Item{
+		Discrimination: question.Discrimination,
+		Difficulty:     question.Difficulty,
+	}
+	if test.AdaptiveModel != constants.AdaptiveModel2PL || item.Discrimination <= 0 {
+		item.Discrimination = 1
+	}
+	return item
+}
+
+func mostInformative(test *entity.Test, asked []uint, ability float64) *entity.Question {
+	var best *entity.Question
+	bestInformation := -1.0
+	for _, question := range adaptiveItems(test) {
+		if slices.Contains(asked, question.ID) {
+			continue
+		}
+
+		information := irt.Information(ability, itemOf(test, question))
+		if information > bestInformation {
+			best = &question
+			bestInformation = information
+		}
+	}
+	return best
+}
+
+func nextQuestionResponse(attempt *entity.Attempt, question *entity.Question, answered int, ability *float64, abilitySE *float64) *dtos.NextQuestionResponse {
+	response := &dtos.NextQuestionResponse{
+		AttemptID: attempt.ID,
+		Finished:  question == nil,
+		Answered:  answered,
+	}
+	if ability != nil {
+		response.Ability = *ability
+	}
+	if abilitySE != nil {
+		response.StandardError = *abilitySE
+	}
+
+	if question != nil {
+		rendered := dtos.MapQuestionToGetQuestionResponse(&renderQuestions([]entity.Question{*question}, attempt)[0])
+		response.Question = &rendered
+	}
+
+	return response
+}
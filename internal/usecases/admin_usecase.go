@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/pkg/constants"
+)
+
+type AdminUserRepoInterface interface {
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserById(id uint) (*entity.User, error)
+	SearchUsers(query string, limit, offset int) ([]entity.User, int64, error)
+	SetSuspended(userId uint, suspendedAt *time.Time) error
+	SetRole(userId uint, role string) error
+}
+
+type AdminSessionRepoInterface interface {
+	DeleteSessionsByUserId(userId uint) error
+}
+
+type AdminTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	ChangeActiveStatus(status bool, testId uint) error
+}
+
+type AuditLogRepoInterface interface {
+	CreateAuditLog(entry *entity.AuditLog) error
+	GetAuditLogs(action string, limit, offset int) ([]entity.AuditLog, int64, error)
+}
+
+type ImpersonatorInterface interface {
+	StartImpersonatedSession(user *entity.User, impersonatorID uint, r *http.Request) (string, string, error)
+}
+
+type Admin struct {
+	userRepo     AdminUserRepoInterface
+	sessionRepo  AdminSessionRepoInterface
+	testRepo     AdminTestRepoInterface
+	auditRepo    AuditLogRepoInterface
+	impersonator ImpersonatorInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewAdmin(
+	userRepo AdminUserRepoInterface,
+	sessionRepo AdminSessionRepoInterface,
+	testRepo AdminTestRepoInterface,
+	auditRepo AuditLogRepoInterface,
+	impersonator ImpersonatorInterface,
+	cacheManager CacheManagerV2Interface,
+) *Admin {
+	return &Admin{
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		testRepo:     testRepo,
+		auditRepo:    auditRepo,
+		impersonator: impersonator,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *Admin) GetUsers(query string, limit, offset int) ([]entity.User, int64, error) {
+	users, count, err := s.userRepo.SearchUsers(query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetUsers: %w", err)
+	}
+	return users, count, nil
+}
+
+func (s *Admin) SuspendUser(actorLogin string, userId uint, r *http.Request) error {
+	actor, target, err := s.actorAndTarget(actorLogin, userId)
+	if err != nil {
+		return fmt.Errorf("SuspendUser: %w", err)
+	}
+
+	if actor.ID == target.ID {
+		return fmt.Errorf("SuspendUser: admins can not suspend themselves")
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetSuspended(target.ID, &now); err != nil {
+		return fmt.Errorf("SuspendUser: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSessionsByUserId(target.ID); err != nil {
+		return fmt.Errorf("SuspendUser: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", target.Login)); err != nil {
+		return fmt.Errorf("SuspendUser: failed delete user from cache: %w", err)
+	}
+
+	return s.audit(actor, constants.AuditUserSuspended, "user", target.ID, "", r)
+}
+
+func (s *Admin) UnsuspendUser(actorLogin string, userId uint, r *http.Request) error {
+	actor, target, err := s.actorAndTarget(actorLogin, userId)
+	if err != nil {
+		return fmt.Errorf("UnsuspendUser: %w", err)
+	}
+
+	if err := s.userRepo.SetSuspended(target.ID, nil); err != nil {
+		return fmt.Errorf("UnsuspendUser: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", target.Login)); err != nil {
+		return fmt.Errorf("UnsuspendUser: failed delete user from cache: %w", err)
+	}
+
+	return s.audit(actor, constants.AuditUserUnsuspended, "user", target.ID, "", r)
+}
+
+func (s *Admin) ChangeRole(actorLogin string, userId uint, role string, r *http.Request) error {
+	actor, target, err := s.actorAndTarget(actorLogin, userId)
+	if err != nil {
+		return fmt.Errorf("ChangeRole: %w", err)
+	}
+
+	if _, ok := constants.RolePermissions[role]; !ok {
+		return fmt.Errorf("ChangeRole: unknown role %q", role)
+	}
+
+	if actor.ID == target.ID {
+		return fmt.Errorf("ChangeRole: admins can not change their own role")
+	}
+
+	if err := s.userRepo.SetRole(target.ID, role); err != nil {
+		return fmt.Errorf("ChangeRole: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", target.Login)); err != nil {
+		return fmt.Errorf("ChangeRole: failed delete user from cache: %w", err)
+	}
+
+	return s.audit(actor, constants.AuditUserRoleChanged, "user", target.ID, fmt.Sprintf("%s -> %s", target.Role, role), r)
+}
+
+func (s *Admin) UnpublishTest(actorLogin string, testId uint, r *http.Request) error {
+	actor, err := s.userRepo.GetUserByLogin(actorLogin)
+	if err != nil {
+		return fmt.Errorf("UnpublishTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return fmt.Errorf("UnpublishTest: %w", err)
+	}
+
+	if err := s.testRepo.ChangeActiveStatus(false, test.ID); err != nil {
+		return fmt.Errorf("UnpublishTest: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("UnpublishTest: failed delete test from cache: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("tests:user:%d:*", test.UserID)); err != nil {
+		return fmt.Errorf("UnpublishTest: failed delete tests from cache: %w", err)
+	}
+
+	return s.audit(actor, constants.AuditTestUnpublished, "test", test.ID, test.Name, r)
+}
+
+// Impersonate opens a session as the target user. The session keeps the admin id
+// and every call is written to the audit log before the token is handed out.
+func (s *Admin) Impersonate(actorLogin string, userId uint, r *http.Request) (string, error) {
+	actor, target, err := s.actorAndTarget(actorLogin, userId)
+	if err != nil {
+		return "", fmt.Errorf("Impersonate: %w", err)
+	}
+
+	if actor.ID == target.ID {
+		return "", fmt.Errorf("Impersonate: can not impersonate yourself")
+	}
+
+	if target.Role == constants.RoleAdmin {
+		return "", fmt.Errorf("Impersonate: admins can not be impersonated")
+	}
+
+	if err := s.audit(actor, constants.AuditUserImpersonate, "user", target.ID, target.Login, r); err != nil {
+		return "", fmt.Errorf("Impersonate: %w", err)
+	}
+
+	token, _, err := s.impersonator.StartImpersonatedSession(target, actor.ID, r)
+	if err != nil {
+		return "", fmt.Errorf("Impersonate: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *Admin) GetAuditLogs(action string, limit, offset int) ([]entity.AuditLog, int64, error) {
+	entries, count, err := s.auditRepo.GetAuditLogs(action, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetAuditLogs: %w", err)
+	}
+	return entries, count, nil
+}
+
+func (s *Admin) actorAndTarget(actorLogin string, userId uint) (*entity.User, *entity.User, error) {
+	actor, err := s.userRepo.GetUserByLogin(actorLogin)
+	if err != nil {
+		return nil, nil, fmt.Errorf("actorAndTarget: failed get user by login: %w", err)
+	}
+
+	target, err := s.userRepo.GetUserById(userId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("actorAndTarget: failed get user by id: %w", err)
+	}
+
+	return actor, target, nil
+}
+
+func (s *Admin) audit(actor *entity.User, action string, targetType string, targetId uint, details string, r *http.Request) error {
+	if err := s.auditRepo.CreateAuditLog(&entity.AuditLog{
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		IP:         ClientIP(r),
+		Details:    details,
+	}); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
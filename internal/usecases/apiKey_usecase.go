@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+)
+
+type APIKeyRepoInterface interface {
+	CreateAPIKey(key *entity.APIKey) error
+	GetAPIKeysByUserId(userId uint) ([]entity.APIKey, error)
+	GetAPIKeyByHash(hash string) (*entity.APIKey, error)
+	TouchAPIKey(id uint, lastUsedAt time.Time) error
+	DeleteAPIKey(id uint, userId uint) error
+}
+
+type APIKeyUserRepoInterface interface {
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserById(id uint) (*entity.User, error)
+}
+
+type APIKey struct {
+	apiKeyRepo APIKeyRepoInterface
+	userRepo   APIKeyUserRepoInterface
+}
+
+func NewAPIKey(apiKeyRepo APIKeyRepoInterface, userRepo APIKeyUserRepoInterface) *APIKey {
+	return &APIKey{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *APIKey) CreateAPIKey(login string, data *dtos.CreateAPIKeyRequest) (*dtos.CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAPIKey: failed get user by login: %w", err)
+	}
+
+	secret, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAPIKey: failed generate key: %w", err)
+	}
+	rawKey := constants.API_KEY_PREFIX + secret
+
+	key := &entity.APIKey{
+		UserID:  user.ID,
+		Name:    data.Name,
+		Prefix:  rawKey[:len(constants.API_KEY_PREFIX)+8],
+		KeyHash: securetoken.Hash(rawKey),
+		Scopes:  strings.Join(data.Scopes, " "),
+	}
+	if data.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, data.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.CreateAPIKey(key); err != nil {
+		return nil, fmt.Errorf("CreateAPIKey: %w", err)
+	}
+
+	return &dtos.CreateAPIKeyResponse{
+		Key:            rawKey,
+		APIKeyResponse: dtos.ToAPIKeyResponse(key),
+	}, nil
+}
+
+func (s *APIKey) GetAPIKeys(login string) ([]entity.APIKey, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetAPIKeys: failed get user by login: %w", err)
+	}
+
+	keys, err := s.apiKeyRepo.GetAPIKeysByUserId(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAPIKeys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *APIKey) RevokeAPIKey(login string, id uint) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("RevokeAPIKey: failed get user by login: %w", err)
+	}
+
+	if err := s.apiKeyRepo.DeleteAPIKey(id, user.ID); err != nil {
+		return fmt.Errorf("RevokeAPIKey: %w", err)
+	}
+
+	return nil
+}
+
+func (s *APIKey) Authenticate(rawKey string, requiredScopes []string) (*entity.User, error) {
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(securetoken.Hash(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("Authenticate: unknown api key: %w", err)
+	}
+
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, fmt.Errorf("Authenticate: api key expired")
+	}
+
+	scopes := strings.Fields(key.Scopes)
+	for _, scope := range requiredScopes {
+		if !slices.Contains(scopes, scope) {
+			return nil, fmt.Errorf("Authenticate: api key has no %s scope", scope)
+		}
+	}
+
+	user, err := s.userRepo.GetUserById(key.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("Authenticate: failed get key owner: %w", err)
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, fmt.Errorf("Authenticate: key owner is suspended")
+	}
+
+	if err := s.apiKeyRepo.TouchAPIKey(key.ID, time.Now()); err != nil {
+		return nil, fmt.Errorf("Authenticate: %w", err)
+	}
+
+	return user, nil
+}
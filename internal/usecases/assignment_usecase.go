@@ -0,0 +1,293 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+)
+
+type AssignmentRepoInterface interface {
+	CreateAssignment(assignment *entity.Assignment) error
+	GetAssignmentById(id uint) (*entity.Assignment, error)
+	GetAssignmentsByGroupId(groupId uint) ([]entity.Assignment, error)
+	GetAssignmentsByUserId(userId uint) ([]entity.Assignment, error)
+	DeleteAssignment(id uint) error
+}
+
+type AttemptRepoInterface interface {
+	CreateAttempt(attempt *entity.Attempt) error
+	GetAttemptById(id uint) (*entity.Attempt, error)
+	GetOpenAttempt(userId uint, testId uint, assignmentId *uint) (*entity.Attempt, error)
+	GetLastSubmittedAttempt(userId uint, testId uint) (*entity.Attempt, error)
+	CountTestAttempts(userId uint, testId uint) (int64, error)
+	FinishAttempt(id uint, score *float64, grade entity.AttemptGrade, answers []entity.AttemptAnswer, pendingReview bool, submittedAt time.Time) error
+	GetAttemptAnswers(attemptId uint) ([]entity.AttemptAnswer, error)
+	UpdateAttemptGrades(grades map[uint]entity.AttemptGrade) error
+	GetSubmittedAttemptsByTestId(testId uint) ([]entity.Attempt, error)
+	CountAssignmentAttempts(userId uint, assignmentId uint) (int64, error)
+	GetAttemptsByAssignmentId(assignmentId uint) ([]entity.Attempt, error)
+	GetUserAttemptsByAssignmentIds(userId uint, assignmentIds []uint) ([]entity.Attempt, error)
+}
+
+type AssignmentGroupRepoInterface interface {
+	GetGroupById(id uint) (*entity.Group, error)
+	IsMember(groupId uint, userId uint) (bool, error)
+	GetMembers(groupId uint) ([]entity.GroupMember, error)
+}
+
+type AssignmentTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+}
+
+type Assignment struct {
+	assignmentRepo AssignmentRepoInterface
+	attemptRepo    AttemptRepoInterface
+	groupRepo      AssignmentGroupRepoInterface
+	testRepo       AssignmentTestRepoInterface
+	userRepo       UserRepoInterfaceGetByLogin
+	access         TestAccessInterface
+}
+
+func NewAssignment(
+	assignmentRepo AssignmentRepoInterface,
+	attemptRepo AttemptRepoInterface,
+	groupRepo AssignmentGroupRepoInterface,
+	testRepo AssignmentTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+) *Assignment {
+	return &Assignment{
+		assignmentRepo: assignmentRepo,
+		attemptRepo:    attemptRepo,
+		groupRepo:      groupRepo,
+		testRepo:       testRepo,
+		userRepo:       userRepo,
+		access:         access,
+	}
+}
+
+// CreateAssignment assigns a test to a group. Only the group owner may assign,
+// and only tests they can edit.
+func (s *Assignment) CreateAssignment(login string, groupId uint, data *dtos.CreateAssignmentRequest) (*entity.Assignment, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssignment: failed get user by login: %w", err)
+	}
+
+	group, err := s.groupRepo.GetGroupById(groupId)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssignment: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		return nil, fmt.Errorf("CreateAssignment: user %s is not the owner of group %d", login, groupId)
+	}
+
+	test, err := s.testRepo.GetTestById(data.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssignment: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssignment: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("CreateAssignment: user %s can not assign test %d", login, test.ID)
+	}
+
+	opensAt := time.Now()
+	if data.OpensAt != nil {
+		opensAt = *data.OpensAt
+	}
+
+	if data.DueAt != nil && !data.DueAt.After(opensAt) {
+		return nil, fmt.Errorf("CreateAssignment: due date must be after open date")
+	}
+
+	assignment := &entity.Assignment{
+		GroupID:      group.ID,
+		TestID:       test.ID,
+		AssignedByID: user.ID,
+		OpensAt:      opensAt,
+		DueAt:        data.DueAt,
+		MaxAttempts:  data.MaxAttempts,
+		Test:         *test,
+	}
+	if err := s.assignmentRepo.CreateAssignment(assignment); err != nil {
+		return nil, fmt.Errorf("CreateAssignment: %w", err)
+	}
+
+	return assignment, nil
+}
+
+func (s *Assignment) GetGroupAssignments(login string, groupId uint) ([]entity.Assignment, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupAssignments: failed get user by login: %w", err)
+	}
+
+	group, err := s.groupRepo.GetGroupById(groupId)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupAssignments: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		isMember, err := s.groupRepo.IsMember(group.ID, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetGroupAssignments: %w", err)
+		}
+		if !isMember {
+			return nil, fmt.Errorf("GetGroupAssignments: user %s is not a member of group %d", login, groupId)
+		}
+	}
+
+	assignments, err := s.assignmentRepo.GetAssignmentsByGroupId(group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupAssignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func (s *Assignment) DeleteAssignment(login string, id uint) error {
+	assignment, _, err := s.ownedAssignment(login, id)
+	if err != nil {
+		return fmt.Errorf("DeleteAssignment: %w", err)
+	}
+
+	if err := s.assignmentRepo.DeleteAssignment(assignment.ID); err != nil {
+		return fmt.Errorf("DeleteAssignment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Assignment) GetUserAssignments(login string) ([]dtos.UserAssignmentResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserAssignments: failed get user by login: %w", err)
+	}
+
+	assignments, err := s.assignmentRepo.GetAssignmentsByUserId(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserAssignments: %w", err)
+	}
+
+	ids := make([]uint, len(assignments))
+	for i, assignment := range assignments {
+		ids[i] = assignment.ID
+	}
+
+	attempts, err := s.attemptRepo.GetUserAttemptsByAssignmentIds(user.ID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserAssignments: %w", err)
+	}
+
+	byAssignment := make(map[uint][]entity.Attempt, len(assignments))
+	for _, attempt := range attempts {
+		byAssignment[*attempt.AssignmentID] = append(byAssignment[*attempt.AssignmentID], attempt)
+	}
+
+	now := time.Now()
+	result := make([]dtos.UserAssignmentResponse, len(assignments))
+	for i := range assignments {
+		userAttempts := byAssignment[assignments[i].ID]
+		result[i] = dtos.UserAssignmentResponse{
+			AssignmentResponse: dtos.ToAssignmentResponse(&assignments[i]),
+			Status:             assignmentStatus(&assignments[i], submittedCount(userAttempts), now),
+			AttemptsUsed:       len(userAttempts),
+			Score:              officialScore(assignments[i].Test.GradingPolicy, userAttempts),
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Assignment) GetReport(login string, id uint) (*dtos.AssignmentReportResponse, error) {
+	assignment, group, err := s.ownedAssignment(login, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	members, err := s.groupRepo.GetMembers(group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	attempts, err := s.attemptRepo.GetAttemptsByAssignmentId(assignment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	byUser := make(map[uint][]entity.Attempt, len(members))
+	for _, attempt := range attempts {
+		byUser[attempt.UserID] = append(byUser[attempt.UserID], attempt)
+	}
+
+	now := time.Now()
+	report := &dtos.AssignmentReportResponse{
+		Assignment: dtos.ToAssignmentResponse(assignment),
+		Total:      len(members),
+		Members:    make([]dtos.AssignmentReportRow, len(members)),
+	}
+	for i, member := range members {
+		userAttempts := byUser[member.UserID]
+		submitted := submittedCount(userAttempts)
+		row := dtos.AssignmentReportRow{
+			UserID:          member.UserID,
+			Login:           member.User.Login,
+			Name:            member.User.Name,
+			Status:          assignmentStatus(assignment, submitted, now),
+			Attempts:        len(userAttempts),
+			Score:           officialScore(assignment.Test.GradingPolicy, userAttempts),
+			LastSubmittedAt: lastSubmittedAt(userAttempts),
+		}
+		if submitted > 0 {
+			report.Completed++
+		}
+		report.Members[i] = row
+	}
+
+	return report, nil
+}
+
+func (s *Assignment) ownedAssignment(login string, id uint) (*entity.Assignment, *entity.Group, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ownedAssignment: failed get user by login: %w", err)
+	}
+
+	assignment, err := s.assignmentRepo.GetAssignmentById(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ownedAssignment: %w", err)
+	}
+
+	group, err := s.groupRepo.GetGroupById(assignment.GroupID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ownedAssignment: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		return nil, nil, fmt.Errorf("ownedAssignment: user %s is not the owner of group %d", login, group.ID)
+	}
+
+	return assignment, group, nil
+}
+
+func assignmentStatus(assignment *entity.Assignment, attempts int, now time.Time) string {
+	switch {
+	case attempts > 0:
+		return constants.AssignmentCompleted
+	case now.Before(assignment.OpensAt):
+		return constants.AssignmentUpcoming
+	case assignment.DueAt != nil && now.After(*assignment.DueAt):
+		return constants.AssignmentOverdue
+	default:
+		return constants.AssignmentPending
+	}
+}
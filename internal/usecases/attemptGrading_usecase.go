@@ -0,0 +1,284 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"go.uber.org/zap"
+)
+
+type GradingAttemptRepoInterface interface {
+	GetAttemptById(id uint) (*entity.Attempt, error)
+	GetAttemptAnswers(attemptId uint) ([]entity.AttemptAnswer, error)
+	GetAnswersByIds(ids []uint) ([]entity.AttemptAnswer, error)
+	GetUngradedAnswers(testId uint, questionId *uint) ([]entity.AttemptAnswer, error)
+	GradeAnswers(answers []entity.AttemptAnswer, gradedById uint, gradedAt time.Time) error
+	FinalizeAttempt(id uint, score *float64, grade entity.AttemptGrade) error
+}
+
+type GradingTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+}
+
+type GradingUserRepoInterface interface {
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserById(id uint) (*entity.User, error)
+}
+
+// AttemptGrading is the manual grading queue for text answers. Graders are
+// the editors of the test.
+type AttemptGrading struct {
+	attemptRepo GradingAttemptRepoInterface
+	testRepo    GradingTestRepoInterface
+	userRepo    GradingUserRepoInterface
+	access      TestAccessInterface
+	mailer      MailerInterface
+	logger      *zap.Logger
+}
+
+func NewAttemptGrading(
+	attemptRepo GradingAttemptRepoInterface,
+	testRepo GradingTestRepoInterface,
+	userRepo GradingUserRepoInterface,
+	access TestAccessInterface,
+	mailer MailerInterface,
+	logger *zap.Logger,
+) *AttemptGrading {
+	return &AttemptGrading{
+		attemptRepo: attemptRepo,
+		testRepo:    testRepo,
+		userRepo:    userRepo,
+		access:      access,
+		mailer:      mailer,
+		logger:      logger,
+	}
+}
+
+func (s *AttemptGrading) GetQueue(login string, testId uint, questionId *uint) ([]dtos.GradingQueueItem, error) {
+	test, _, err := s.graderTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetQueue: %w", err)
+	}
+
+	answers, err := s.attemptRepo.GetUngradedAnswers(test.ID, questionId)
+	if err != nil {
+		return nil, fmt.Errorf("GetQueue: %w", err)
+	}
+
+	questions := questionsById(test)
+	queue := make([]dtos.GradingQueueItem, len(answers))
+	for i, answer := range answers {
+		question := questions[answer.QuestionID]
+		queue[i] = dtos.GradingQueueItem{
+			AnswerID:     answer.ID,
+			AttemptID:    answer.AttemptID,
+			QuestionID:   answer.QuestionID,
+			QuestionName: question.Name,
+			MaxPoints:    question.MaxPoints,
+			Text:         answer.Text,
+			SubmittedAt:  answer.Attempt.SubmittedAt,
+		}
+	}
+
+	return queue, nil
+}
+
+func (s *AttemptGrading) GradeAnswer(login string, answerId uint, data *dtos.GradeAnswerRequest) error {
+	answers, err := s.attemptRepo.GetAnswersByIds([]uint{answerId})
+	if err != nil {
+		return fmt.Errorf("GradeAnswer: %w", err)
+	}
+	if len(answers) == 0 {
+		return fmt.Errorf("GradeAnswer: answer %d not found", answerId)
+	}
+
+	test, user, err := s.graderTest(login, answers[0].Attempt.TestID)
+	if err != nil {
+		return fmt.Errorf("GradeAnswer: %w", err)
+	}
+
+	if err := s.gradeAnswers(test, user, nil, []dtos.AnswerGradeInput{{
+		AnswerID: answerId,
+		Points:   data.Points,
+		Criteria: data.Criteria,
+		Comment:  data.Comment,
+	}}); err != nil {
+		return fmt.Errorf("GradeAnswer: %w", err)
+	}
+
+	return nil
+}
+
+// BulkGrade grades answers of many attempts to the same question at once.
+func (s *AttemptGrading) BulkGrade(login string, testId uint, data *dtos.BulkGradeRequest) error {
+	test, user, err := s.graderTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("BulkGrade: %w", err)
+	}
+
+	if err := s.gradeAnswers(test, user, &data.QuestionID, data.Grades); err != nil {
+		return fmt.Errorf("BulkGrade: %w", err)
+	}
+
+	return nil
+}
+
+// Finalize scores an attempt once all its text answers are graded and lets
+// the taker know.
+func (s *AttemptGrading) Finalize(login string, attemptId uint) (*dtos.ValidateResultResponse, error) {
+	attempt, err := s.attemptRepo.GetAttemptById(attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("Finalize: %w", err)
+	}
+
+	test, _, err := s.graderTest(login, attempt.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("Finalize: %w", err)
+	}
+
+	if !attempt.PendingReview {
+		return nil, fmt.Errorf("Finalize: attempt %d is not waiting for review", attempt.ID)
+	}
+
+	answers, err := s.attemptRepo.GetAttemptAnswers(attempt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Finalize: %w", err)
+	}
+
+	score, pending := scoreAnswers(test, answers)
+	if pending {
+		return nil, fmt.Errorf("Finalize: attempt %d has ungraded answers", attempt.ID)
+	}
+
+	grade := gradeScore(test, score)
+	if err := s.attemptRepo.FinalizeAttempt(attempt.ID, score, grade); err != nil {
+		return nil, fmt.Errorf("Finalize: %w", err)
+	}
+
+	s.notify(test, attempt, score)
+
+	return &dtos.ValidateResultResponse{
+		AttemptID: attempt.ID,
+		Score:     score,
+		Passed:    grade.Passed,
+		Band:      grade.Band,
+		Feedback:  grade.Feedback,
+	}, nil
+}
+
+// notify emails the taker about a finalized attempt. Failures are only
+// logged, the grade is already stored.
+func (s *AttemptGrading) notify(test *entity.Test, attempt *entity.Attempt, score *float64) {
+	taker, err := s.userRepo.GetUserById(attempt.UserID)
+	if err != nil {
+		s.logger.Error("Finalize: failed get attempt user", zap.Error(err))
+		return
+	}
+
+	if taker.Role == constants.RoleGuest || taker.Email == "" {
+		return
+	}
+
+	result := 0.0
+	if score != nil {
+		result = *score
+	}
+
+	body := fmt.Sprintf("Ваша попытка прохождения теста «%s» проверена. Результат: %.2f%%.", test.Name, result)
+	if err := s.mailer.Send(taker.Email, "Попытка проверена", body); err != nil {
+		s.logger.Error("Finalize: failed send grading notification", zap.Error(err))
+	}
+}
+
+func (s *AttemptGrading) gradeAnswers(test *entity.Test, grader *entity.User, questionId *uint, grades []dtos.AnswerGradeInput) error {
+	ids := make([]uint, len(grades))
+	for i, grade := range grades {
+		ids[i] = grade.AnswerID
+	}
+
+	stored, err := s.attemptRepo.GetAnswersByIds(ids)
+	if err != nil {
+		return fmt.Errorf("gradeAnswers: %w", err)
+	}
+
+	byId := make(map[uint]entity.AttemptAnswer, len(stored))
+	for _, answer := range stored {
+		byId[answer.ID] = answer
+	}
+
+	questions := questionsById(test)
+	answers := make([]entity.AttemptAnswer, len(grades))
+	for i, grade := range grades {
+		answer, ok := byId[grade.AnswerID]
+		if !ok || answer.Attempt.TestID != test.ID {
+			return fmt.Errorf("gradeAnswers: answer %d does not belong to test %d", grade.AnswerID, test.ID)
+		}
+
+		if questionId != nil && answer.QuestionID != *questionId {
+			return fmt.Errorf("gradeAnswers: answer %d is not for question %d", answer.ID, *questionId)
+		}
+
+		question, ok := questions[answer.QuestionID]
+		if !ok || question.Type == constants.QuestionChoice {
+			return fmt.Errorf("gradeAnswers: answer %d is not a text or code answer", answer.ID)
+		}
+
+		points := grade.Points
+		var criterionScores []entity.AnswerCriterionScore
+		if len(question.Criteria) > 0 {
+			points, criterionScores, err = scoreRubric(question.Criteria, grade.Criteria)
+			if err != nil {
+				return fmt.Errorf("gradeAnswers: answer %d: %w", answer.ID, err)
+			}
+		} else if points > question.MaxPoints {
+			return fmt.Errorf("gradeAnswers: answer %d can get at most %.2f points", answer.ID, question.MaxPoints)
+		}
+
+		answers[i] = entity.AttemptAnswer{
+			Model:           answer.Model,
+			Points:          &points,
+			Comment:         grade.Comment,
+			CriterionScores: criterionScores,
+		}
+	}
+
+	if err := s.attemptRepo.GradeAnswers(answers, grader.ID, time.Now()); err != nil {
+		return fmt.Errorf("gradeAnswers: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AttemptGrading) graderTest(login string, testId uint) (*entity.Test, *entity.User, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, fmt.Errorf("graderTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("graderTest: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("graderTest: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, nil, fmt.Errorf("graderTest: user %s can not grade test %d", login, testId)
+	}
+
+	return test, user, nil
+}
+
+func questionsById(test *entity.Test) map[uint]entity.Question {
+	questions := make(map[uint]entity.Question, len(test.Questions))
+	for _, question := range test.Questions {
+		questions[question.ID] = question
+	}
+	return questions
+}
@@ -0,0 +1,329 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+)
+
+type AttemptReviewTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdateReviewPolicy(testId uint, policy string, release string) error
+	ReleaseReview(testId uint, releasedAt time.Time) error
+}
+
+type AttemptReview struct {
+	attemptRepo    AttemptRepoInterface
+	testRepo       AttemptReviewTestRepoInterface
+	assignmentRepo AttemptAssignmentRepoInterface
+	userRepo       UserRepoInterfaceGetByLogin
+	access         TestAccessInterface
+	cacheManager   CacheManagerV2Interface
+}
+
+func NewAttemptReview(
+	attemptRepo AttemptRepoInterface,
+	testRepo AttemptReviewTestRepoInterface,
+	assignmentRepo AttemptAssignmentRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *AttemptReview {
+	return &AttemptReview{
+		attemptRepo:    attemptRepo,
+		testRepo:       testRepo,
+		assignmentRepo: assignmentRepo,
+		userRepo:       userRepo,
+		access:         access,
+		cacheManager:   cacheManager,
+	}
+}
+
+func (s *AttemptReview) UpdateReviewPolicy(login string, testId uint, data *dtos.UpdateReviewPolicyRequest) error {
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("UpdateReviewPolicy: %w", err)
+	}
+
+	if err := s.testRepo.UpdateReviewPolicy(test.ID, data.Policy, data.Release); err != nil {
+		return fmt.Errorf("UpdateReviewPolicy: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("UpdateReviewPolicy: failed delete test from cache: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseReview opens the review of all attempts of a test in manual mode.
+func (s *AttemptReview) ReleaseReview(login string, testId uint) error {
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("ReleaseReview: %w", err)
+	}
+
+	if test.ReviewRelease != constants.ReviewReleaseManual {
+		return fmt.Errorf("ReleaseReview: test %d is not released manually", test.ID)
+	}
+
+	if err := s.testRepo.ReleaseReview(test.ID, time.Now()); err != nil {
+		return fmt.Errorf("ReleaseReview: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("ReleaseReview: failed delete test from cache: %w", err)
+	}
+
+	return nil
+}
+
+// GetReview shows a submitted attempt to its taker as far as the review
+// policy of the test allows. Viewers of the test always get the full review.
+func (s *AttemptReview) GetReview(login string, attemptId uint) (*dtos.AttemptReviewResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetReview: failed get user by login: %w", err)
+	}
+
+	attempt, err := s.attemptRepo.GetAttemptById(attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("GetReview: %w", err)
+	}
+
+	if attempt.SubmittedAt == nil {
+		return nil, fmt.Errorf("GetReview: attempt %d is not submitted", attempt.ID)
+	}
+
+	test, err := s.testRepo.GetTestById(attempt.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReview: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("GetReview: %w", err)
+	}
+
+	policy := constants.ReviewFull
+	if !canViewPrivateTest(role) {
+		if attempt.UserID != user.ID {
+			return nil, fmt.Errorf("GetReview: attempt %d does not belong to user %s", attempt.ID, login)
+		}
+
+		released, err := s.isReleased(test, attempt, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("GetReview: %w", err)
+		}
+
+		if !released || test.ReviewPolicy == "" || test.ReviewPolicy == constants.ReviewNever {
+			return nil, fmt.Errorf("GetReview: review of attempt %d is not available", attempt.ID)
+		}
+		policy = test.ReviewPolicy
+	}
+
+	review := &dtos.AttemptReviewResponse{
+		AttemptID:   attempt.ID,
+		TestID:      test.ID,
+		Policy:      policy,
+		Score:       attempt.Score,
+		Passed:      attempt.Passed,
+		Band:        attempt.Band,
+		Feedback:    attempt.Feedback,
+		SubmittedAt: attempt.SubmittedAt,
+	}
+
+	answers, err := s.attemptRepo.GetAttemptAnswers(attempt.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReview: %w", err)
+	}
+
+	review.Sections = sectionScores(test, answers)
+	if policy == constants.ReviewScore {
+		return review, nil
+	}
+
+	questions := test.Questions
+	if test.Adaptive {
+		questions = answeredQuestions(questions, answers)
+	}
+
+	review.Questions = reviewQuestions(renderQuestions(questions, attempt), answers, policy == constants.ReviewFull)
+
+	return review, nil
+}
+
+// isReleased tells whether the review of the attempt may be shown now. The
+// deadline is the assignment due date for assigned attempts and the closing
+// time of the test otherwise; without a deadline the review stays hidden.
+func (s *AttemptReview) isReleased(test *entity.Test, attempt *entity.Attempt, now time.Time) (bool, error) {
+	deadline := test.ClosesAt
+	if test.ReviewRelease == constants.ReviewReleaseDeadline && attempt.AssignmentID != nil {
+		assignment, err := s.assignmentRepo.GetAssignmentById(*attempt.AssignmentID)
+		if err != nil {
+			return false, fmt.Errorf("isReleased: %w", err)
+		}
+		deadline = assignment.DueAt
+	}
+	return reviewReleased(test, deadline, now), nil
+}
+
+// reviewReleased applies the review release of the test with the given
+// deadline.
+func reviewReleased(test *entity.Test, deadline *time.Time, now time.Time) bool {
+	switch test.ReviewRelease {
+	case constants.ReviewReleaseManual:
+		return test.ReviewReleasedAt != nil
+	case constants.ReviewReleaseDeadline:
+		return deadline != nil && !now.Before(*deadline)
+	default:
+		return true
+	}
+}
+
+func (s *AttemptReview) editableTest(login string, testId uint) (*entity.Test, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("editableTest: user %s can not edit test %d", login, testId)
+	}
+
+	return test, nil
+}
+
+// answeredQuestions keeps the questions the attempt has answers to. An
+// adaptive attempt is asked only a part of the item bank, and the rest of it
+// must not be revealed in the review.
+func answeredQuestions(questions []entity.Question, answers []entity.AttemptAnswer) []entity.Question {
+	answered := make(map[uint]bool, len(answers))
+	for _, answer := range answers {
+		answered[answer.QuestionID] = true
+	}
+
+	var result []entity.Question
+	for _, question := range questions {
+		if answered[question.ID] {
+			result = append(result, question)
+		}
+	}
+	return result
+}
+
+// reviewQuestions marks every question of the test as right or wrong by the
+// stored answers. Correct variants and explanations are only shown in full;
+// a text, code or numeric answer counts as right when it got all its points.
+// Code answers list their test cases, the data of visible cases only in full.
+func reviewQuestions(questions []entity.Question, answers []entity.AttemptAnswer, full bool) []dtos.ReviewQuestion {
+	byVariant := make(map[uint]entity.AttemptAnswer, len(answers))
+	texts := make(map[uint]entity.AttemptAnswer)
+	for _, answer := range answers {
+		if answer.VariantID == 0 {
+			texts[answer.QuestionID] = answer
+			continue
+		}
+		byVariant[answer.VariantID] = answer
+	}
+
+	result := make([]dtos.ReviewQuestion, len(questions))
+	for i, question := range questions {
+		if question.Type != constants.QuestionChoice {
+			text := texts[question.ID]
+			result[i] = dtos.ReviewQuestion{
+				QuestionID: question.ID,
+				Name:       question.Name,
+				Type:       question.Type,
+				Correct:    text.Points != nil && *text.Points >= question.MaxPoints,
+				Answer:     text.Text,
+				Points:     text.Points,
+				MaxPoints:  question.MaxPoints,
+				Comment:    text.Comment,
+				Variants:   []dtos.ReviewVariant{},
+			}
+			if full {
+				result[i].Explanation = question.Explanation
+				result[i].Expected = text.Expected
+			}
+			if question.Type == constants.QuestionCode {
+				result[i].Cases = reviewTestCases(question.TestCases, text.CaseResults, full)
+			}
+			continue
+		}
+
+		answered := false
+		correct := true
+		variants := make([]dtos.ReviewVariant, len(question.Variants))
+		for j, variant := range question.Variants {
+			answer, ok := byVariant[variant.ID]
+			if ok {
+				answered = true
+				correct = correct && answer.Correct
+			}
+
+			variants[j] = dtos.ReviewVariant{
+				ID:       variant.ID,
+				Name:     variant.Name,
+				Selected: ok && answer.Selected,
+			}
+			if full {
+				isCorrect := variant.IsCorrect
+				variants[j].IsCorrect = &isCorrect
+				variants[j].Explanation = variant.Explanation
+			}
+		}
+
+		result[i] = dtos.ReviewQuestion{
+			QuestionID: question.ID,
+			Name:       question.Name,
+			Type:       question.Type,
+			Correct:    answered && correct,
+			Variants:   variants,
+		}
+		if full {
+			result[i].Explanation = question.Explanation
+		}
+	}
+
+	return result
+}
+
+func reviewTestCases(cases []entity.CodeTestCase, results []entity.CodeTestResult, full bool) []dtos.ReviewTestCase {
+	byCase := make(map[uint]entity.CodeTestResult, len(results))
+	for _, result := range results {
+		byCase[result.TestCaseID] = result
+	}
+
+	review := make([]dtos.ReviewTestCase, len(cases))
+	for i, testCase := range cases {
+		result := byCase[testCase.ID]
+		review[i] = dtos.ReviewTestCase{
+			Name:     testCase.Name,
+			Hidden:   testCase.Hidden,
+			Passed:   result.Passed,
+			TimedOut: result.TimedOut,
+		}
+		if full && !testCase.Hidden {
+			review[i].Input = testCase.Input
+			review[i].Expected = testCase.Expected
+			review[i].Output = result.Output
+			review[i].Error = result.Error
+		}
+	}
+
+	return review
+}
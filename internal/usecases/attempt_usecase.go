@@ -0,0 +1,624 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"gorm.io/gorm"
+)
+
+type AttemptTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdateAttemptPolicy(testId uint, maxAttempts int, cooldown int, gradingPolicy string) error
+	ReplaceGrading(testId uint, passMark *float64, bands []entity.GradeBand) error
+}
+
+type AttemptAssignmentRepoInterface interface {
+	GetAssignmentById(id uint) (*entity.Assignment, error)
+}
+
+type AttemptGroupRepoInterface interface {
+	IsMember(groupId uint, userId uint) (bool, error)
+}
+
+type Attempt struct {
+	attemptRepo    AttemptRepoInterface
+	testRepo       AttemptTestRepoInterface
+	assignmentRepo AttemptAssignmentRepoInterface
+	groupRepo      AttemptGroupRepoInterface
+	userRepo       UserRepoInterfaceGetByLogin
+	access         TestAccessInterface
+	cacheManager   CacheManagerV2Interface
+}
+
+func NewAttempt(
+	attemptRepo AttemptRepoInterface,
+	testRepo AttemptTestRepoInterface,
+	assignmentRepo AttemptAssignmentRepoInterface,
+	groupRepo AttemptGroupRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *Attempt {
+	return &Attempt{
+		attemptRepo:    attemptRepo,
+		testRepo:       testRepo,
+		assignmentRepo: assignmentRepo,
+		groupRepo:      groupRepo,
+		userRepo:       userRepo,
+		access:         access,
+		cacheManager:   cacheManager,
+	}
+}
+
+func (s *Attempt) UpdateAttemptPolicy(login string, testId uint, data *dtos.UpdateAttemptPolicyRequest) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return fmt.Errorf("UpdateAttemptPolicy: user %s can not edit test %d", login, testId)
+	}
+
+	if err := s.testRepo.UpdateAttemptPolicy(test.ID, data.MaxAttempts, data.AttemptCooldown, data.GradingPolicy); err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("UpdateAttemptPolicy: failed delete test from cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Attempt) StartAttempt(login string, testId uint, assignmentId *uint) (*entity.Attempt, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("StartAttempt: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("StartAttempt: %w", err)
+	}
+
+	attempt, err := s.Start(user, test, assignmentId, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("StartAttempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+// Start opens a new attempt, or returns the one the user has not submitted yet.
+// Attempt limits and the cooldown apply to passing users only.
+func (s *Attempt) Start(user *entity.User, test *entity.Test, assignmentId *uint, now time.Time) (*entity.Attempt, error) {
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	if !canViewPrivateTest(role) && !isTestAvailable(test, now) {
+		return nil, fmt.Errorf("Start: test is closed")
+	}
+
+	canPass, err := s.access.CanPass(test, user, role)
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+	if !canPass {
+		return nil, fmt.Errorf("Start: access mode %s does not allow user %s", test.AccessMode, user.Login)
+	}
+
+	var assignment *entity.Assignment
+	if assignmentId != nil {
+		assignment, err = s.checkAssignment(*assignmentId, test.ID, user.ID, now)
+		if err != nil {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+	}
+
+	open, err := s.attemptRepo.GetOpenAttempt(user.ID, test.ID, assignmentId)
+	if err == nil {
+		return open, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	if assignment != nil && assignment.MaxAttempts > 0 {
+		used, err := s.attemptRepo.CountAssignmentAttempts(user.ID, assignment.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+		if used >= int64(assignment.MaxAttempts) {
+			return nil, fmt.Errorf("Start: no assignment attempts left")
+		}
+	}
+
+	if !canEditTest(role) {
+		if err := s.checkLimits(test, user.ID, now); err != nil {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+	}
+
+	attempt := &entity.Attempt{
+		TestID:       test.ID,
+		UserID:       user.ID,
+		AssignmentID: assignmentId,
+		StartedAt:    now,
+		Variables:    drawVariables(test),
+	}
+	if len(test.Sections) > 0 {
+		attempt.CurrentSectionID = &test.Sections[0].ID
+		attempt.Sections = []entity.AttemptSection{{
+			SectionID: test.Sections[0].ID,
+			EnteredAt: &now,
+		}}
+	}
+	if err := s.attemptRepo.CreateAttempt(attempt); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+
+		// A concurrent request has started the attempt between the checks
+		// above and the insert; continue that one instead of a second.
+		open, err := s.attemptRepo.GetOpenAttempt(user.ID, test.ID, assignmentId)
+		if err != nil {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+		return open, nil
+	}
+
+	return attempt, nil
+}
+
+// OpenAttempt returns the attempt that answers are submitted for. Without an
+// attempt id a new attempt is started on the spot, so the limits still apply.
+func (s *Attempt) OpenAttempt(user *entity.User, test *entity.Test, attemptId *uint, assignmentId *uint, now time.Time) (*entity.Attempt, error) {
+	if attemptId == nil {
+		return s.Start(user, test, assignmentId, now)
+	}
+
+	attempt, err := s.attemptRepo.GetAttemptById(*attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAttempt: %w", err)
+	}
+
+	if attempt.UserID != user.ID || attempt.TestID != test.ID {
+		return nil, fmt.Errorf("OpenAttempt: attempt %d belongs to another user or test", attempt.ID)
+	}
+
+	if attempt.SubmittedAt != nil {
+		return nil, fmt.Errorf("OpenAttempt: attempt %d is already submitted", attempt.ID)
+	}
+
+	if attempt.AssignmentID != nil {
+		assignment, err := s.assignmentRepo.GetAssignmentById(*attempt.AssignmentID)
+		if err != nil {
+			return nil, fmt.Errorf("OpenAttempt: %w", err)
+		}
+		if assignment.DueAt != nil && now.After(*assignment.DueAt) {
+			return nil, fmt.Errorf("OpenAttempt: assignment is overdue")
+		}
+	}
+
+	return attempt, nil
+}
+
+func (s *Attempt) GetResults(login string, testId uint) (*dtos.TestResultsResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetResults: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetResults: user %s can not view results of test %d", login, testId)
+	}
+
+	attempts, err := s.attemptRepo.GetSubmittedAttemptsByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetResults: %w", err)
+	}
+
+	results := gradeByUser(test, attempts)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Login < results[j].Login
+	})
+
+	return &dtos.TestResultsResponse{
+		TestID:        test.ID,
+		GradingPolicy: test.GradingPolicy,
+		Results:       results,
+	}, nil
+}
+
+func (s *Attempt) GetLeaderboard(login string, testId uint, limit int) ([]dtos.LeaderboardEntry, error) {
+	test, user, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaderboard: %w", err)
+	}
+
+	if !canViewPrivateTest(role) && !isTestAvailable(test, time.Now()) {
+		return nil, fmt.Errorf("GetLeaderboard: test %d is not available", testId)
+	}
+
+	canPass, err := s.access.CanPass(test, user, role)
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaderboard: %w", err)
+	}
+	if !canPass {
+		return nil, fmt.Errorf("GetLeaderboard: access mode %s does not allow user %s", test.AccessMode, login)
+	}
+
+	attempts, err := s.attemptRepo.GetSubmittedAttemptsByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaderboard: %w", err)
+	}
+
+	results := gradeByUser(test, attempts)
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score == nil || results[j].Score == nil {
+			return results[j].Score == nil && results[i].Score != nil
+		}
+		return *results[i].Score > *results[j].Score
+	})
+
+	if limit <= 0 {
+		limit = constants.LEADERBOARD_SIZE
+	}
+
+	leaderboard := make([]dtos.LeaderboardEntry, 0, limit)
+	for i, result := range results {
+		if len(leaderboard) == limit || result.Score == nil {
+			break
+		}
+
+		rank := i + 1
+		if i > 0 && *results[i-1].Score == *result.Score {
+			rank = leaderboard[i-1].Rank
+		}
+
+		leaderboard = append(leaderboard, dtos.LeaderboardEntry{
+			Rank:  rank,
+			Login: result.Login,
+			Name:  result.Name,
+			Score: *result.Score,
+		})
+	}
+
+	return leaderboard, nil
+}
+
+func (s *Attempt) GetGrading(login string, testId uint) (*dtos.GradingResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetGrading: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetGrading: user %s can not view grading of test %d", login, testId)
+	}
+
+	return &dtos.GradingResponse{
+		TestID:   test.ID,
+		PassMark: test.PassMark,
+		Bands:    test.GradeBands,
+	}, nil
+}
+
+// UpdateGrading replaces the pass mark and grade bands of a test. Past
+// attempts keep their grades until ReapplyGrading is called.
+func (s *Attempt) UpdateGrading(login string, testId uint, data *dtos.UpdateGradingRequest) (*dtos.GradingResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateGrading: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("UpdateGrading: user %s can not edit test %d", login, testId)
+	}
+
+	names := make(map[string]bool, len(data.Bands))
+	minScores := make(map[float64]bool, len(data.Bands))
+	bands := make([]entity.GradeBand, 0, len(data.Bands))
+	for _, band := range data.Bands {
+		name := strings.TrimSpace(band.Name)
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("UpdateGrading: duplicate band %s", name)
+		}
+		if minScores[band.MinScore] {
+			return nil, fmt.Errorf("UpdateGrading: duplicate band minimum %.2f", band.MinScore)
+		}
+		names[strings.ToLower(name)] = true
+		minScores[band.MinScore] = true
+
+		bands = append(bands, entity.GradeBand{
+			TestID:   test.ID,
+			Name:     name,
+			MinScore: band.MinScore,
+			Feedback: band.Feedback,
+		})
+	}
+
+	if err := s.testRepo.ReplaceGrading(test.ID, data.PassMark, bands); err != nil {
+		return nil, fmt.Errorf("UpdateGrading: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("UpdateGrading: failed delete test from cache: %w", err)
+	}
+
+	sort.SliceStable(bands, func(i, j int) bool {
+		return bands[i].MinScore > bands[j].MinScore
+	})
+
+	return &dtos.GradingResponse{
+		TestID:   test.ID,
+		PassMark: data.PassMark,
+		Bands:    bands,
+	}, nil
+}
+
+// ReapplyGrading grades every submitted attempt of the test again with the
+// current pass mark and bands. Scores are left as they are.
+func (s *Attempt) ReapplyGrading(login string, testId uint) (*dtos.ReapplyGradingResponse, error) {
+	test, _, role, err := s.testAndRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("ReapplyGrading: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("ReapplyGrading: user %s can not edit test %d", login, testId)
+	}
+
+	attempts, err := s.attemptRepo.GetSubmittedAttemptsByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ReapplyGrading: %w", err)
+	}
+
+	grades := make(map[uint]entity.AttemptGrade)
+	for _, attempt := range attempts {
+		grade := gradeScore(test, attempt.Score)
+		if !sameGrade(grade, attempt.AttemptGrade) {
+			grades[attempt.ID] = grade
+		}
+	}
+
+	if len(grades) > 0 {
+		if err := s.attemptRepo.UpdateAttemptGrades(grades); err != nil {
+			return nil, fmt.Errorf("ReapplyGrading: %w", err)
+		}
+	}
+
+	return &dtos.ReapplyGradingResponse{Updated: len(grades)}, nil
+}
+
+func (s *Attempt) testAndRole(login string, testId uint) (*entity.Test, *entity.User, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("testAndRole: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("testAndRole: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("testAndRole: %w", err)
+	}
+
+	return test, user, role, nil
+}
+
+// checkAssignment makes sure the user belongs to the assigned group and the
+// assignment window is open.
+func (s *Attempt) checkAssignment(assignmentId uint, testId uint, userId uint, now time.Time) (*entity.Assignment, error) {
+	assignment, err := s.assignmentRepo.GetAssignmentById(assignmentId)
+	if err != nil {
+		return nil, fmt.Errorf("checkAssignment: %w", err)
+	}
+
+	if assignment.TestID != testId {
+		return nil, fmt.Errorf("checkAssignment: assignment %d is for another test", assignmentId)
+	}
+
+	isMember, err := s.groupRepo.IsMember(assignment.GroupID, userId)
+	if err != nil {
+		return nil, fmt.Errorf("checkAssignment: %w", err)
+	}
+	if !isMember {
+		return nil, fmt.Errorf("checkAssignment: user is not a member of the assigned group")
+	}
+
+	if now.Before(assignment.OpensAt) {
+		return nil, fmt.Errorf("checkAssignment: assignment is not open yet")
+	}
+
+	if assignment.DueAt != nil && now.After(*assignment.DueAt) {
+		return nil, fmt.Errorf("checkAssignment: assignment is overdue")
+	}
+
+	return assignment, nil
+}
+
+func (s *Attempt) checkLimits(test *entity.Test, userId uint, now time.Time) error {
+	if test.MaxAttempts > 0 {
+		used, err := s.attemptRepo.CountTestAttempts(userId, test.ID)
+		if err != nil {
+			return fmt.Errorf("checkLimits: %w", err)
+		}
+		if used >= int64(test.MaxAttempts) {
+			return fmt.Errorf("checkLimits: no attempts left")
+		}
+	}
+
+	if test.AttemptCooldown > 0 {
+		last, err := s.attemptRepo.GetLastSubmittedAttempt(userId, test.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("checkLimits: %w", err)
+		}
+		if err == nil && now.Before(last.SubmittedAt.Add(time.Duration(test.AttemptCooldown)*time.Second)) {
+			return fmt.Errorf("checkLimits: next attempt is available after cooldown")
+		}
+	}
+
+	return nil
+}
+
+// gradeByUser groups submitted attempts by user and grades each user by the
+// test's policy. Attempts are expected in submission order.
+func gradeByUser(test *entity.Test, attempts []entity.Attempt) []dtos.TestResultRow {
+	byUser := make(map[uint][]entity.Attempt)
+	var order []uint
+	for _, attempt := range attempts {
+		if _, ok := byUser[attempt.UserID]; !ok {
+			order = append(order, attempt.UserID)
+		}
+		byUser[attempt.UserID] = append(byUser[attempt.UserID], attempt)
+	}
+
+	results := make([]dtos.TestResultRow, len(order))
+	for i, userId := range order {
+		userAttempts := byUser[userId]
+		score := officialScore(test.GradingPolicy, userAttempts)
+		grade := gradeScore(test, score)
+		results[i] = dtos.TestResultRow{
+			UserID:          userId,
+			Login:           userAttempts[0].User.Login,
+			Name:            userAttempts[0].User.Name,
+			Attempts:        len(userAttempts),
+			Score:           score,
+			Passed:          grade.Passed,
+			Band:            grade.Band,
+			LastSubmittedAt: lastSubmittedAt(userAttempts),
+			Ability:         lastAbility(userAttempts),
+		}
+	}
+
+	return results
+}
+
+// officialScore picks the grade that counts according to the grading policy.
+// Attempts without a score or not yet submitted are ignored.
+func officialScore(policy string, attempts []entity.Attempt) *float64 {
+	var scores []float64
+	for i := range attempts {
+		if attempts[i].SubmittedAt != nil && attempts[i].Score != nil {
+			scores = append(scores, *attempts[i].Score)
+		}
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	var score float64
+	switch policy {
+	case constants.GradingFirst:
+		score = scores[0]
+	case constants.GradingLast:
+		score = scores[len(scores)-1]
+	case constants.GradingAverage:
+		for _, value := range scores {
+			score += value
+		}
+		score /= float64(len(scores))
+	default:
+		score = scores[0]
+		for _, value := range scores[1:] {
+			if value > score {
+				score = value
+			}
+		}
+	}
+
+	return &score
+}
+
+// gradeScore applies the pass mark and grade bands of the test to a score.
+// Bands are expected ordered by MinScore descending.
+func gradeScore(test *entity.Test, score *float64) entity.AttemptGrade {
+	var grade entity.AttemptGrade
+	if score == nil {
+		return grade
+	}
+
+	if test.PassMark != nil {
+		passed := *score >= *test.PassMark
+		grade.Passed = &passed
+	}
+
+	for _, band := range test.GradeBands {
+		if *score >= band.MinScore {
+			grade.Band = band.Name
+			grade.Feedback = band.Feedback
+			break
+		}
+	}
+
+	return grade
+}
+
+func sameGrade(a, b entity.AttemptGrade) bool {
+	if (a.Passed == nil) != (b.Passed == nil) {
+		return false
+	}
+	if a.Passed != nil && *a.Passed != *b.Passed {
+		return false
+	}
+	return a.Band == b.Band && a.Feedback == b.Feedback
+}
+
+func lastSubmittedAt(attempts []entity.Attempt) *time.Time {
+	var last *time.Time
+	for i := range attempts {
+		if attempts[i].SubmittedAt != nil && (last == nil || attempts[i].SubmittedAt.After(*last)) {
+			last = attempts[i].SubmittedAt
+		}
+	}
+	return last
+}
+
+// lastAbility is the ability estimated by the latest submitted attempt of
+// an adaptive test.
+func lastAbility(attempts []entity.Attempt) *float64 {
+	var last *entity.Attempt
+	for i := range attempts {
+		if attempts[i].SubmittedAt != nil && attempts[i].Ability != nil && (last == nil || attempts[i].SubmittedAt.After(*last.SubmittedAt)) {
+			last = &attempts[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	return last.Ability
+}
+
+func submittedCount(attempts []entity.Attempt) int {
+	count := 0
+	for i := range attempts {
+		if attempts[i].SubmittedAt != nil {
+			count++
+		}
+	}
+	return count
+}
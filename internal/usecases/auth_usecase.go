@@ -2,48 +2,69 @@ package usecases
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
+	jwtlib "github.com/golang-jwt/jwt/v5"
 	"github.com/server/configs"
 	"github.com/server/entity"
 	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserRepoInterface interface {
 	CreateUser(user entity.User) error
-	DeleteRefreshToken(login string) error
 	GetUserByEmail(email string) (*entity.User, error)
 	GetUserByLogin(login string) (*entity.User, error)
 	UpdateUser(user *dtos.UpdateUserRequest) error
 }
 
-type AuthRepoInterface interface {
-	SaveRefreshToken(login string, token string) error
+type SessionRepoInterface interface {
+	CreateSession(session *entity.Session) error
+	SaveRefreshToken(sessionId uint, refreshToken string) error
+}
+
+type EmailVerifierInterface interface {
+	SendVerification(user *entity.User, email string) error
 }
 
 type JWTInterface interface {
-	CreateAccessToken(login string) (string, error)
-	CreateRefreshToken(login string) (string, error)
+	CreateAccessToken(login string, sessionID uint) (string, error)
+	CreateRefreshToken(login string, sessionID uint) (string, error)
+	CreatePurposeToken(purpose string, claims jwtlib.MapClaims, duration time.Duration) (string, error)
+	VerifyPurposeToken(tokenString string, purpose string) (jwtlib.MapClaims, error)
 }
 type Auth struct {
 	userRepo      UserRepoInterface
-	authRepo      AuthRepoInterface
+	sessionRepo   SessionRepoInterface
+	recoveryRepo  SecondFactorRepoInterface
 	tokenProvider JWTInterface
+	emailVerifier EmailVerifierInterface
 	config        *configs.Config
+	logger        *zap.Logger
 }
 
 func NewAuth(
 	userRepo UserRepoInterface,
-	authRepo AuthRepoInterface,
+	sessionRepo SessionRepoInterface,
+	recoveryRepo SecondFactorRepoInterface,
 	tokenProvider JWTInterface,
+	emailVerifier EmailVerifierInterface,
 	config *configs.Config,
+	logger *zap.Logger,
 ) *Auth {
 	return &Auth{
 		userRepo:      userRepo,
-		authRepo:      authRepo,
+		sessionRepo:   sessionRepo,
+		recoveryRepo:  recoveryRepo,
 		tokenProvider: tokenProvider,
+		emailVerifier: emailVerifier,
 		config:        config,
+		logger:        logger,
 	}
 }
 
@@ -57,18 +78,66 @@ func (s *Auth) Login(data *dtos.LoginRequest, w http.ResponseWriter, r *http.Req
 		return nil, fmt.Errorf("Login: invalid password: %w", err)
 	}
 
-	token, err := s.tokenProvider.CreateAccessToken(user.Login)
+	response, err := s.CompleteLogin(user, r)
+	if err != nil {
+		return nil, fmt.Errorf("Login: %w", err)
+	}
+
+	return response, nil
+}
+
+// CompleteLogin finishes a login once the user proved the first factor. With
+// two factor authentication on the user gets a challenge to answer instead of
+// a session.
+func (s *Auth) CompleteLogin(user *entity.User, r *http.Request) (*dtos.LoginResponse, error) {
+	if user.TOTPEnabled {
+		challengeToken, err := s.tokenProvider.CreatePurposeToken(constants.TwoFactorChallengePurpose, jwtlib.MapClaims{
+			"login": user.Login,
+		}, constants.TWO_FACTOR_CHALLENGE_TTL)
+		if err != nil {
+			return nil, fmt.Errorf("CompleteLogin: failed to create two factor challenge: %w", err)
+		}
+
+		return &dtos.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
+	}
+
+	token, _, err := s.StartSession(user, r)
+	if err != nil {
+		return nil, fmt.Errorf("CompleteLogin: %w", err)
+	}
+
+	return &dtos.LoginResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+func (s *Auth) LoginTwoFactor(data *dtos.TwoFactorLoginRequest, r *http.Request) (*dtos.LoginResponse, error) {
+	claims, err := s.tokenProvider.VerifyPurposeToken(data.ChallengeToken, constants.TwoFactorChallengePurpose)
 	if err != nil {
-		return nil, fmt.Errorf("Login: failed to create access token: %w", err)
+		return nil, fmt.Errorf("LoginTwoFactor: invalid challenge token: %w", err)
 	}
 
-	refreshToken, err := s.tokenProvider.CreateRefreshToken(user.Login)
+	login, _ := claims["login"].(string)
+	user, err := s.userRepo.GetUserByLogin(login)
 	if err != nil {
-		return nil, fmt.Errorf("Login: failed to create refresh token: %w", err)
+		return nil, fmt.Errorf("LoginTwoFactor: user not found: %w", err)
+	}
+
+	if !user.TOTPEnabled {
+		return nil, fmt.Errorf("LoginTwoFactor: two factor authentication is not enabled")
+	}
+
+	if err := verifySecondFactor(user, data.Code, data.RecoveryCode, s.recoveryRepo); err != nil {
+		return nil, fmt.Errorf("LoginTwoFactor: %w", err)
 	}
 
-	if err := s.authRepo.SaveRefreshToken(user.Login, refreshToken); err != nil {
-		return nil, fmt.Errorf("Login: failed to save refresh token: %w", err)
+	token, _, err := s.StartSession(user, r)
+	if err != nil {
+		return nil, fmt.Errorf("LoginTwoFactor: %w", err)
 	}
 
 	return &dtos.LoginResponse{
@@ -77,18 +146,23 @@ func (s *Auth) Login(data *dtos.LoginRequest, w http.ResponseWriter, r *http.Req
 	}, nil
 }
 
-func (s *Auth) Registration(data *dtos.RegistrationRequest) (*dtos.RegistrationResponse, error) {
+func (s *Auth) Registration(data *dtos.RegistrationRequest, r *http.Request) (*dtos.RegistrationResponse, error) {
 	if err := s.userRepo.CreateUser(data.ToUser()); err != nil {
 		return nil, fmt.Errorf("Registration: failed to register user: %w", err)
 	}
 
-	refreshToken, err := s.tokenProvider.CreateRefreshToken(data.Login)
+	user, err := s.userRepo.GetUserByLogin(data.Login)
 	if err != nil {
-		return nil, fmt.Errorf("Registration: failed to create refresh token: %w", err)
+		return nil, fmt.Errorf("Registration: failed to get created user: %w", err)
+	}
+
+	if err := s.emailVerifier.SendVerification(user, user.Email); err != nil {
+		s.logger.Error("Registration: failed to send verification email", zap.Error(err))
 	}
 
-	if err := s.authRepo.SaveRefreshToken(data.Login, refreshToken); err != nil {
-		return nil, fmt.Errorf("Registration: failed to save refresh token: %w", err)
+	_, refreshToken, err := s.StartSession(user, r)
+	if err != nil {
+		return nil, fmt.Errorf("Registration: %w", err)
 	}
 
 	return &dtos.RegistrationResponse{
@@ -99,3 +173,98 @@ func (s *Auth) Registration(data *dtos.RegistrationRequest) (*dtos.RegistrationR
 		RefreshToken: refreshToken,
 	}, nil
 }
+
+func (s *Auth) StartSession(user *entity.User, r *http.Request) (string, string, error) {
+	return s.startSession(user, newSession(user, r))
+}
+
+// StartImpersonatedSession opens a session for user that is marked as opened by impersonatorID.
+func (s *Auth) StartImpersonatedSession(user *entity.User, impersonatorID uint, r *http.Request) (string, string, error) {
+	session := newSession(user, r)
+	session.ImpersonatorID = &impersonatorID
+	return s.startSession(user, session)
+}
+
+func newSession(user *entity.User, r *http.Request) *entity.Session {
+	return &entity.Session{
+		UserID:     user.ID,
+		UserAgent:  r.UserAgent(),
+		IP:         ClientIP(r),
+		LastSeenAt: time.Now(),
+	}
+}
+
+func (s *Auth) startSession(user *entity.User, session *entity.Session) (string, string, error) {
+	if user.SuspendedAt != nil {
+		return "", "", fmt.Errorf("StartSession: user is suspended")
+	}
+
+	if err := s.sessionRepo.CreateSession(session); err != nil {
+		return "", "", fmt.Errorf("StartSession: failed to create session: %w", err)
+	}
+
+	token, err := s.tokenProvider.CreateAccessToken(user.Login, session.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("StartSession: failed to create access token: %w", err)
+	}
+
+	refreshToken, err := s.tokenProvider.CreateRefreshToken(user.Login, session.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("StartSession: failed to create refresh token: %w", err)
+	}
+
+	if err := s.sessionRepo.SaveRefreshToken(session.ID, refreshToken); err != nil {
+		return "", "", fmt.Errorf("StartSession: failed to save refresh token: %w", err)
+	}
+
+	return token, refreshToken, nil
+}
+
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets the networks whose X-Forwarded-For header ClientIP
+// believes. It is called once on startup, before the server accepts requests.
+func SetTrustedProxies(proxies []*net.IPNet) {
+	trustedProxies = proxies
+}
+
+// ClientIP returns the address of the client. X-Forwarded-For is honoured only
+// when the request came from a trusted proxy, and then the address is the
+// rightmost one not added by a trusted proxy: everything to the left of it is
+// set by the client and can be forged.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" || net.ParseIP(hop) == nil {
+			break
+		}
+		host = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return host
+}
+
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/sandbox"
+)
+
+type CodeQuestionRepoInterface interface {
+	GetQuestionById(id uint) (*entity.Question, error)
+	ReplaceTestCases(question *entity.Question, cases []entity.CodeTestCase) error
+}
+
+type CodeSandboxInterface interface {
+	Languages() []string
+	Supports(language string) bool
+	Run(ctx context.Context, language string, program sandbox.Program, inputs []string) ([]sandbox.Result, error)
+}
+
+type CodeQuestion struct {
+	codeRepo     CodeQuestionRepoInterface
+	testRepo     GradingTestRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	access       TestAccessInterface
+	sandbox      CodeSandboxInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewCodeQuestion(
+	codeRepo CodeQuestionRepoInterface,
+	testRepo GradingTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	sandbox CodeSandboxInterface,
+	cacheManager CacheManagerV2Interface,
+) *CodeQuestion {
+	return &CodeQuestion{
+		codeRepo:     codeRepo,
+		testRepo:     testRepo,
+		userRepo:     userRepo,
+		access:       access,
+		sandbox:      sandbox,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *CodeQuestion) GetTestCases(login string, questionId uint) (*dtos.CodeTestsResponse, error) {
+	question, role, err := s.questionAndRole(login, questionId)
+	if err != nil {
+		return nil, fmt.Errorf("GetTestCases: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetTestCases: user %s can not view test cases of question %d", login, questionId)
+	}
+
+	return s.response(question, question.TestCases), nil
+}
+
+// SetTestCases replaces the test cases of a code question together with its
+// language and harness. The question is then worth what its cases are.
+func (s *CodeQuestion) SetTestCases(login string, questionId uint, data *dtos.UpdateCodeTestsRequest) (*dtos.CodeTestsResponse, error) {
+	question, role, err := s.questionAndRole(login, questionId)
+	if err != nil {
+		return nil, fmt.Errorf("SetTestCases: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("SetTestCases: user %s can not edit question %d", login, questionId)
+	}
+
+	if question.Type != constants.QuestionCode {
+		return nil, fmt.Errorf("SetTestCases: question %d is not a code question", question.ID)
+	}
+
+	if !s.sandbox.Supports(data.Language) {
+		return nil, fmt.Errorf("SetTestCases: language %s is not supported", data.Language)
+	}
+
+	question.Language = data.Language
+	question.Harness = data.Harness
+	question.Starter = data.Starter
+	question.MaxPoints = 0
+
+	cases := make([]entity.CodeTestCase, len(data.Cases))
+	for i, input := range data.Cases {
+		cases[i] = entity.CodeTestCase{
+			QuestionID: question.ID,
+			Name:       strings.TrimSpace(input.Name),
+			Input:      input.Input,
+			Expected:   input.Expected,
+			Hidden:     input.Hidden,
+			Points:     input.Points,
+			Position:   i,
+		}
+		question.MaxPoints += input.Points
+	}
+
+	if err := s.codeRepo.ReplaceTestCases(question, cases); err != nil {
+		return nil, fmt.Errorf("SetTestCases: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", question.TestID)); err != nil {
+		return nil, fmt.Errorf("SetTestCases: failed delete test from cache: %w", err)
+	}
+
+	return s.response(question, cases), nil
+}
+
+func (s *CodeQuestion) response(question *entity.Question, cases []entity.CodeTestCase) *dtos.CodeTestsResponse {
+	return &dtos.CodeTestsResponse{
+		QuestionID: question.ID,
+		Language:   question.Language,
+		Harness:    question.Harness,
+		Starter:    question.Starter,
+		MaxPoints:  question.MaxPoints,
+		Languages:  s.sandbox.Languages(),
+		Cases:      cases,
+	}
+}
+
+func (s *CodeQuestion) questionAndRole(login string, questionId uint) (*entity.Question, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: failed get user by login: %w", err)
+	}
+
+	question, err := s.codeRepo.GetQuestionById(questionId)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(question.TestID)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	return question, role, nil
+}
+
+// gradeCode runs a code answer on every test case of its question and sums
+// the points of the passed ones. A case passes when the program exits cleanly
+// in time and prints the expected output. Source that does not build fails
+// every case; an error means the sandbox could not run it at all.
+func gradeCode(ctx context.Context, runner CodeSandboxInterface, question entity.Question, source string) (float64, []entity.CodeTestResult, error) {
+	inputs := make([]string, len(question.TestCases))
+	for i, testCase := range question.TestCases {
+		inputs[i] = testCase.Input
+	}
+
+	runs, err := runner.Run(ctx, question.Language, sandbox.Program{Source: source, Harness: question.Harness}, inputs)
+	var buildErr *sandbox.BuildError
+	if errors.As(err, &buildErr) {
+		runs = make([]sandbox.Result, len(inputs))
+		for i := range runs {
+			runs[i] = sandbox.Result{Error: buildErr.Output, ExitCode: -1}
+		}
+	} else if err != nil {
+		return 0, nil, fmt.Errorf("gradeCode: %w", err)
+	}
+
+	var points float64
+	results := make([]entity.CodeTestResult, len(question.TestCases))
+	for i, testCase := range question.TestCases {
+		run := runs[i]
+		passed := !run.TimedOut && run.ExitCode == 0 && strings.TrimSpace(run.Output) == strings.TrimSpace(testCase.Expected)
+		if passed {
+			points += testCase.Points
+		}
+
+		results[i] = entity.CodeTestResult{
+			TestCaseID: testCase.ID,
+			Passed:     passed,
+			Output:     storableText(run.Output),
+			Error:      storableText(run.Error),
+			TimedOut:   run.TimedOut,
+			DurationMs: run.Duration.Milliseconds(),
+		}
+	}
+
+	return points, results, nil
+}
+
+// storableText drops what postgres refuses to keep in a text column.
+func storableText(value string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(value, "�"), "\x00", "")
+}
@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/pkg/constants"
+	"gorm.io/gorm"
+)
+
+type PurposeTokenInterface interface {
+	CreatePurposeToken(purpose string, claims jwtlib.MapClaims, duration time.Duration) (string, error)
+	VerifyPurposeToken(tokenString string, purpose string) (jwtlib.MapClaims, error)
+}
+
+type EmailVerificationUserRepoInterface interface {
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserByEmail(email string) (*entity.User, error)
+	SetPendingEmail(userId uint, email string) error
+	ConfirmEmail(userId uint, email string) error
+}
+
+type EmailVerification struct {
+	userRepo      EmailVerificationUserRepoInterface
+	tokenProvider PurposeTokenInterface
+	cacheManager  CacheManagerV2Interface
+	mailer        MailerInterface
+	config        *configs.Config
+}
+
+func NewEmailVerification(
+	userRepo EmailVerificationUserRepoInterface,
+	tokenProvider PurposeTokenInterface,
+	cacheManager CacheManagerV2Interface,
+	mailer MailerInterface,
+	config *configs.Config,
+) *EmailVerification {
+	return &EmailVerification{
+		userRepo:      userRepo,
+		tokenProvider: tokenProvider,
+		cacheManager:  cacheManager,
+		mailer:        mailer,
+		config:        config,
+	}
+}
+
+func (s *EmailVerification) SendVerification(user *entity.User, email string) error {
+	token, err := s.tokenProvider.CreatePurposeToken(constants.EmailVerificationPurpose, jwtlib.MapClaims{
+		"login": user.Login,
+		"email": email,
+	}, constants.EMAIL_VERIFICATION_TOKEN_TTL)
+	if err != nil {
+		return fmt.Errorf("SendVerification: failed create verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/email/verify?token=%s", s.config.CLIENT_URL, url.QueryEscape(token))
+	body := fmt.Sprintf("Чтобы подтвердить адрес почты, перейдите по ссылке: %s", link)
+	if err := s.mailer.Send(email, "Подтверждение почты", body); err != nil {
+		return fmt.Errorf("SendVerification: failed send verification email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *EmailVerification) VerifyEmail(token string) error {
+	claims, err := s.tokenProvider.VerifyPurposeToken(token, constants.EmailVerificationPurpose)
+	if err != nil {
+		return fmt.Errorf("VerifyEmail: invalid verification token: %w", err)
+	}
+
+	login, _ := claims["login"].(string)
+	email, _ := claims["email"].(string)
+
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("VerifyEmail: failed get user by login: %w", err)
+	}
+
+	if email == "" || (email != user.PendingEmail && email != user.Email) {
+		return fmt.Errorf("VerifyEmail: verification token is outdated")
+	}
+
+	if err := s.userRepo.ConfirmEmail(user.ID, email); err != nil {
+		return fmt.Errorf("VerifyEmail: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", login)); err != nil {
+		return fmt.Errorf("VerifyEmail: failed delete user from cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *EmailVerification) ResendVerification(login string) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("ResendVerification: failed get user by login: %w", err)
+	}
+
+	switch {
+	case user.PendingEmail != "":
+		err = s.SendVerification(user, user.PendingEmail)
+	case !user.EmailVerified:
+		err = s.SendVerification(user, user.Email)
+	default:
+		return fmt.Errorf("ResendVerification: email already verified")
+	}
+	if err != nil {
+		return fmt.Errorf("ResendVerification: %w", err)
+	}
+
+	return nil
+}
+
+func (s *EmailVerification) ChangeEmail(login string, email string) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("ChangeEmail: failed get user by login: %w", err)
+	}
+
+	if _, err := s.userRepo.GetUserByEmail(email); err == nil {
+		return fmt.Errorf("ChangeEmail: email already in use")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("ChangeEmail: failed check email: %w", err)
+	}
+
+	if err := s.userRepo.SetPendingEmail(user.ID, email); err != nil {
+		return fmt.Errorf("ChangeEmail: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", login)); err != nil {
+		return fmt.Errorf("ChangeEmail: failed delete user from cache: %w", err)
+	}
+
+	if err := s.SendVerification(user, email); err != nil {
+		return fmt.Errorf("ChangeEmail: %w", err)
+	}
+
+	return nil
+}
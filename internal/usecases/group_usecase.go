@@ -0,0 +1,203 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	securetoken "github.com/server/pkg/secureToken"
+)
+
+type GroupRepoInterface interface {
+	CreateGroup(group *entity.Group) error
+	GetGroupById(id uint) (*entity.Group, error)
+	GetGroupByJoinCode(code string) (*entity.Group, error)
+	GetGroupsByUserId(userId uint) ([]entity.Group, error)
+	UpdateJoinCode(groupId uint, code string) error
+	DeleteGroup(id uint) error
+	AddMember(member *entity.GroupMember) error
+	IsMember(groupId uint, userId uint) (bool, error)
+	GetMembers(groupId uint) ([]entity.GroupMember, error)
+	DeleteMember(groupId uint, userId uint) error
+}
+
+type Group struct {
+	groupRepo GroupRepoInterface
+	userRepo  UserRepoInterfaceGetByLogin
+}
+
+func NewGroup(groupRepo GroupRepoInterface, userRepo UserRepoInterfaceGetByLogin) *Group {
+	return &Group{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *Group) CreateGroup(login string, data *dtos.CreateGroupRequest) (*dtos.GroupResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("CreateGroup: failed get user by login: %w", err)
+	}
+
+	code, err := newJoinCode()
+	if err != nil {
+		return nil, fmt.Errorf("CreateGroup: %w", err)
+	}
+
+	group := &entity.Group{
+		Name:     data.Name,
+		OwnerID:  user.ID,
+		JoinCode: code,
+	}
+	if err := s.groupRepo.CreateGroup(group); err != nil {
+		return nil, fmt.Errorf("CreateGroup: %w", err)
+	}
+
+	return &dtos.ToGroupResponses([]entity.Group{*group}, user.ID)[0], nil
+}
+
+func (s *Group) GetGroups(login string) ([]dtos.GroupResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroups: failed get user by login: %w", err)
+	}
+
+	groups, err := s.groupRepo.GetGroupsByUserId(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroups: %w", err)
+	}
+
+	return dtos.ToGroupResponses(groups, user.ID), nil
+}
+
+func (s *Group) JoinGroup(login string, code string) (*dtos.GroupResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("JoinGroup: failed get user by login: %w", err)
+	}
+
+	group, err := s.groupRepo.GetGroupByJoinCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, fmt.Errorf("JoinGroup: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		if err := s.groupRepo.AddMember(&entity.GroupMember{
+			GroupID: group.ID,
+			UserID:  user.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("JoinGroup: %w", err)
+		}
+	}
+
+	return &dtos.ToGroupResponses([]entity.Group{*group}, user.ID)[0], nil
+}
+
+func (s *Group) GetMembers(login string, groupId uint) ([]entity.GroupMember, error) {
+	user, group, err := s.userAndGroup(login, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("GetMembers: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		isMember, err := s.groupRepo.IsMember(group.ID, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetMembers: %w", err)
+		}
+		if !isMember {
+			return nil, fmt.Errorf("GetMembers: user %s is not a member of group %d", login, groupId)
+		}
+	}
+
+	members, err := s.groupRepo.GetMembers(group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetMembers: %w", err)
+	}
+
+	return members, nil
+}
+
+// RemoveMember lets the owner remove anyone and members leave on their own.
+func (s *Group) RemoveMember(login string, groupId uint, userId uint) error {
+	user, group, err := s.userAndGroup(login, groupId)
+	if err != nil {
+		return fmt.Errorf("RemoveMember: %w", err)
+	}
+
+	if group.OwnerID != user.ID && user.ID != userId {
+		return fmt.Errorf("RemoveMember: user %s can not remove members of group %d", login, groupId)
+	}
+
+	if err := s.groupRepo.DeleteMember(group.ID, userId); err != nil {
+		return fmt.Errorf("RemoveMember: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Group) RegenerateJoinCode(login string, groupId uint) (string, error) {
+	group, err := s.ownedGroup(login, groupId)
+	if err != nil {
+		return "", fmt.Errorf("RegenerateJoinCode: %w", err)
+	}
+
+	code, err := newJoinCode()
+	if err != nil {
+		return "", fmt.Errorf("RegenerateJoinCode: %w", err)
+	}
+
+	if err := s.groupRepo.UpdateJoinCode(group.ID, code); err != nil {
+		return "", fmt.Errorf("RegenerateJoinCode: %w", err)
+	}
+
+	return code, nil
+}
+
+func (s *Group) DeleteGroup(login string, groupId uint) error {
+	group, err := s.ownedGroup(login, groupId)
+	if err != nil {
+		return fmt.Errorf("DeleteGroup: %w", err)
+	}
+
+	if err := s.groupRepo.DeleteGroup(group.ID); err != nil {
+		return fmt.Errorf("DeleteGroup: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Group) ownedGroup(login string, groupId uint) (*entity.Group, error) {
+	user, group, err := s.userAndGroup(login, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("ownedGroup: %w", err)
+	}
+
+	if group.OwnerID != user.ID {
+		return nil, fmt.Errorf("ownedGroup: user %s is not the owner of group %d", login, groupId)
+	}
+
+	return group, nil
+}
+
+func (s *Group) userAndGroup(login string, groupId uint) (*entity.User, *entity.Group, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, fmt.Errorf("userAndGroup: failed get user by login: %w", err)
+	}
+
+	group, err := s.groupRepo.GetGroupById(groupId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("userAndGroup: %w", err)
+	}
+
+	return user, group, nil
+}
+
+func newJoinCode() (string, error) {
+	code, err := securetoken.Generate(4)
+	if err != nil {
+		return "", fmt.Errorf("newJoinCode: failed generate join code: %w", err)
+	}
+	return strings.ToUpper(code), nil
+}
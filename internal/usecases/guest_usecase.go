@@ -0,0 +1,220 @@
+package usecases
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+)
+
+type GuestRepoInterface interface {
+	CreateGuest(guest *entity.Guest) error
+	GetGuestByUserId(userId uint) (*entity.Guest, error)
+	ClaimGuest(guest *entity.Guest, userId uint, claimedAt time.Time) error
+}
+
+type GuestUserRepoInterface interface {
+	CreateUser(user entity.User) error
+	GetUserByLogin(login string) (*entity.User, error)
+}
+
+type GuestTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdateGuestAccess(testId uint, allowGuests bool, guestInfo string) error
+}
+
+type Guest struct {
+	guestRepo     GuestRepoInterface
+	testRepo      GuestTestRepoInterface
+	userRepo      GuestUserRepoInterface
+	access        TestAccessInterface
+	tokenProvider PurposeTokenInterface
+	cacheManager  CacheManagerV2Interface
+}
+
+func NewGuest(
+	guestRepo GuestRepoInterface,
+	testRepo GuestTestRepoInterface,
+	userRepo GuestUserRepoInterface,
+	access TestAccessInterface,
+	tokenProvider PurposeTokenInterface,
+	cacheManager CacheManagerV2Interface,
+) *Guest {
+	return &Guest{
+		guestRepo:     guestRepo,
+		testRepo:      testRepo,
+		userRepo:      userRepo,
+		access:        access,
+		tokenProvider: tokenProvider,
+		cacheManager:  cacheManager,
+	}
+}
+
+func (s *Guest) UpdateGuestAccess(login string, testId uint, data *dtos.UpdateGuestAccessRequest) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("UpdateGuestAccess: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return fmt.Errorf("UpdateGuestAccess: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return fmt.Errorf("UpdateGuestAccess: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return fmt.Errorf("UpdateGuestAccess: user %s can not edit test %d", login, testId)
+	}
+
+	if err := s.testRepo.UpdateGuestAccess(test.ID, data.AllowGuests, data.GuestInfo); err != nil {
+		return fmt.Errorf("UpdateGuestAccess: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("UpdateGuestAccess: failed delete test from cache: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("tests:user:%d:*", test.UserID)); err != nil {
+		return fmt.Errorf("UpdateGuestAccess: failed delete tests from cache: %w", err)
+	}
+
+	return nil
+}
+
+// StartGuest creates a placeholder user for an anonymous test taker and issues
+// a short-lived guest token for it. Attempts of the guest are stored as usual.
+func (s *Guest) StartGuest(testId uint, data *dtos.StartGuestRequest, r *http.Request) (*dtos.GuestSessionResponse, error) {
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("StartGuest: %w", err)
+	}
+
+	if !test.AllowGuests || (test.AccessMode != "" && test.AccessMode != constants.AccessPublic) {
+		return nil, fmt.Errorf("StartGuest: test %d does not allow guests", test.ID)
+	}
+
+	if !isTestAvailable(test, time.Now()) {
+		return nil, fmt.Errorf("StartGuest: test %d is closed", test.ID)
+	}
+
+	name := strings.TrimSpace(data.Name)
+	email := strings.ToLower(strings.TrimSpace(data.Email))
+	switch test.GuestInfo {
+	case constants.GuestInfoName:
+		if name == "" {
+			return nil, fmt.Errorf("StartGuest: test %d requires guest name", test.ID)
+		}
+	case constants.GuestInfoEmail:
+		if email == "" {
+			return nil, fmt.Errorf("StartGuest: test %d requires guest email", test.ID)
+		}
+	}
+
+	suffix, err := securetoken.Generate(8)
+	if err != nil {
+		return nil, fmt.Errorf("StartGuest: failed generate guest login: %w", err)
+	}
+	login := "guest-" + suffix
+
+	password, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, fmt.Errorf("StartGuest: failed generate password: %w", err)
+	}
+
+	displayName := name
+	if displayName == "" {
+		displayName = login
+	}
+
+	if err := s.userRepo.CreateUser(entity.User{
+		Name:     displayName,
+		Login:    login,
+		Password: password,
+		Email:    fmt.Sprintf("%s@%s", login, constants.GUEST_EMAIL_DOMAIN),
+		Role:     constants.RoleGuest,
+	}); err != nil {
+		return nil, fmt.Errorf("StartGuest: %w", err)
+	}
+
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("StartGuest: failed get guest user: %w", err)
+	}
+
+	if err := s.guestRepo.CreateGuest(&entity.Guest{
+		UserID: user.ID,
+		TestID: test.ID,
+		Name:   name,
+		Email:  email,
+		IP:     ClientIP(r),
+	}); err != nil {
+		return nil, fmt.Errorf("StartGuest: %w", err)
+	}
+
+	token, err := s.tokenProvider.CreatePurposeToken(constants.GuestPurpose, jwtlib.MapClaims{
+		"login":   login,
+		"test_id": test.ID,
+	}, constants.GUEST_TOKEN_TTL)
+	if err != nil {
+		return nil, fmt.Errorf("StartGuest: %w", err)
+	}
+
+	return &dtos.GuestSessionResponse{
+		Token:     token,
+		ExpiresAt: time.Now().Add(constants.GUEST_TOKEN_TTL),
+		TestID:    test.ID,
+	}, nil
+}
+
+// Claim moves the attempts made with a guest token to the signed in user.
+// A guest can be claimed only once.
+func (s *Guest) Claim(login string, guestToken string) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("Claim: failed get user by login: %w", err)
+	}
+
+	if user.Role == constants.RoleGuest {
+		return fmt.Errorf("Claim: guests can not claim attempts")
+	}
+
+	claims, err := s.tokenProvider.VerifyPurposeToken(guestToken, constants.GuestPurpose)
+	if err != nil {
+		return fmt.Errorf("Claim: %w", err)
+	}
+
+	guestLogin, _ := claims["login"].(string)
+	guestUser, err := s.userRepo.GetUserByLogin(guestLogin)
+	if err != nil {
+		return fmt.Errorf("Claim: failed get guest user: %w", err)
+	}
+
+	if guestUser.Role != constants.RoleGuest {
+		return fmt.Errorf("Claim: %s is not a guest", guestLogin)
+	}
+
+	guest, err := s.guestRepo.GetGuestByUserId(guestUser.ID)
+	if err != nil {
+		return fmt.Errorf("Claim: %w", err)
+	}
+
+	if err := s.guestRepo.ClaimGuest(guest, user.ID, time.Now()); err != nil {
+		return fmt.Errorf("Claim: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", guestLogin)); err != nil {
+		return fmt.Errorf("Claim: failed delete user from cache: %w", err)
+	}
+
+	return nil
+}
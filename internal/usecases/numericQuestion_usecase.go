@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/expr"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{([\p{L}_][\p{L}\p{N}_]*)\}`)
+
+// validateNumericQuestion checks that the answer of a numeric question can be
+// computed from its own variables only.
+func validateNumericQuestion(question entity.Question) error {
+	names := make(map[string]bool, len(question.Variables))
+	for _, variable := range question.Variables {
+		if !expr.IsName(variable.Name) {
+			return fmt.Errorf("validateNumericQuestion: %q can not be a variable name", variable.Name)
+		}
+		if names[variable.Name] {
+			return fmt.Errorf("validateNumericQuestion: duplicate variable %s", variable.Name)
+		}
+		if variable.Min > variable.Max {
+			return fmt.Errorf("validateNumericQuestion: variable %s has min above max", variable.Name)
+		}
+		if variable.Decimals < 0 || variable.Decimals > constants.NUMERIC_MAX_DECIMALS {
+			return fmt.Errorf("validateNumericQuestion: variable %s must have 0 to %d decimals", variable.Name, constants.NUMERIC_MAX_DECIMALS)
+		}
+		names[variable.Name] = true
+	}
+
+	if len(question.AnswerExpr) > constants.NUMERIC_MAX_EXPR_LENGTH {
+		return fmt.Errorf("validateNumericQuestion: answer is longer than %d characters", constants.NUMERIC_MAX_EXPR_LENGTH)
+	}
+
+	answer, err := expr.Parse(question.AnswerExpr)
+	if err != nil {
+		return fmt.Errorf("validateNumericQuestion: %w", err)
+	}
+
+	for _, name := range answer.Variables() {
+		if !names[name] {
+			return fmt.Errorf("validateNumericQuestion: answer uses unknown variable %s", name)
+		}
+	}
+
+	return nil
+}
+
+// drawVariables picks the values of every numeric question for a new attempt.
+func drawVariables(test *entity.Test) []entity.AttemptVariable {
+	var values []entity.AttemptVariable
+	for _, question := range test.Questions {
+		if question.Type != constants.QuestionNumeric {
+			continue
+		}
+
+		for _, variable := range question.Variables {
+			scale := math.Pow(10, float64(variable.Decimals))
+			value := math.Round((variable.Min+rand.Float64()*(variable.Max-variable.Min))*scale) / scale
+			values = append(values, entity.AttemptVariable{
+				QuestionID: question.ID,
+				Name:       variable.Name,
+				Value:      math.Min(math.Max(value, variable.Min), variable.Max),
+			})
+		}
+	}
+	return values
+}
+
+func questionValues(attempt *entity.Attempt, questionId uint) map[string]float64 {
+	values := make(map[string]float64)
+	for _, variable := range attempt.Variables {
+		if variable.QuestionID == questionId {
+			values[variable.Name] = variable.Value
+		}
+	}
+	return values
+}
+
+// renderQuestions fills the placeholders of numeric questions with the
+// values of the attempt. The questions are copied, the test is left as is.
+func renderQuestions(questions []entity.Question, attempt *entity.Attempt) []entity.Question {
+	rendered := make([]entity.Question, len(questions))
+	for i, question := range questions {
+		rendered[i] = question
+		if question.Type != constants.QuestionNumeric {
+			continue
+		}
+
+		values := questionValues(attempt, question.ID)
+		rendered[i].Name = renderTemplate(question.Name, values)
+		rendered[i].Description = renderTemplate(question.Description, values)
+	}
+	return rendered
+}
+
+// renderTemplate replaces {name} with the value of the variable. Unknown
+// placeholders are kept as written.
+func renderTemplate(template string, values map[string]float64) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := values[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		return formatNumber(value)
+	})
+}
+
+// gradeNumeric checks a number against the answer of the question for the
+// values of the attempt. When the answer can not be computed the returned
+// answer has no points and waits for a grader.
+func gradeNumeric(question entity.Question, attempt *entity.Attempt, number *float64, now time.Time) entity.AttemptAnswer {
+	answer := entity.AttemptAnswer{
+		AttemptID:  attempt.ID,
+		QuestionID: question.ID,
+		Number:     number,
+	}
+	if number != nil {
+		answer.Text = formatNumber(*number)
+	}
+
+	expected, err := expectedNumber(question, questionValues(attempt, question.ID))
+	if err == nil {
+		answer.Expected = &expected
+	}
+
+	if err != nil && number != nil {
+		return answer
+	}
+
+	points := 0.0
+	if number != nil && math.Abs(*number-expected) <= question.Tolerance+1e-9*math.Max(1, math.Abs(expected)) {
+		answer.Correct = true
+		points = question.MaxPoints
+	}
+	answer.Points = &points
+	answer.GradedAt = &now
+
+	return answer
+}
+
+func expectedNumber(question entity.Question, values map[string]float64) (float64, error) {
+	answer, err := expr.Parse(question.AnswerExpr)
+	if err != nil {
+		return 0, fmt.Errorf("expectedNumber: %w", err)
+	}
+
+	value, err := answer.Eval(values)
+	if err != nil {
+		return 0, fmt.Errorf("expectedNumber: %w", err)
+	}
+
+	return value, nil
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,336 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/oidc"
+	securetoken "github.com/server/pkg/secureToken"
+	"gorm.io/gorm"
+)
+
+type OIDCProviderInterface interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code string, codeVerifier string) (string, error)
+	VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*oidc.Claims, error)
+}
+
+type OIDCUserRepoInterface interface {
+	CreateUser(user entity.User) error
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserByEmail(email string) (*entity.User, error)
+	GetUserById(id uint) (*entity.User, error)
+}
+
+type UserIdentityRepoInterface interface {
+	GetIdentity(provider string, subject string) (*entity.UserIdentity, error)
+	CreateIdentity(identity *entity.UserIdentity) error
+}
+
+type LoginCompleterInterface interface {
+	CompleteLogin(user *entity.User, r *http.Request) (*dtos.LoginResponse, error)
+}
+
+// oidcState is what a login needs back on the callback. LinkUserID is set
+// when a signed in user links the provider to their account.
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	LinkUserID   uint   `json:"link_user_id,omitempty"`
+}
+
+type OIDC struct {
+	providers      map[string]OIDCProviderInterface
+	userRepo       OIDCUserRepoInterface
+	identityRepo   UserIdentityRepoInterface
+	loginCompleter LoginCompleterInterface
+	cacheManager   CacheManagerV2Interface
+}
+
+func NewOIDC(
+	providers []OIDCProviderInterface,
+	userRepo OIDCUserRepoInterface,
+	identityRepo UserIdentityRepoInterface,
+	loginCompleter LoginCompleterInterface,
+	cacheManager CacheManagerV2Interface,
+) *OIDC {
+	providersByName := make(map[string]OIDCProviderInterface, len(providers))
+	for _, provider := range providers {
+		providersByName[provider.Name()] = provider
+	}
+
+	return &OIDC{
+		providers:      providersByName,
+		userRepo:       userRepo,
+		identityRepo:   identityRepo,
+		loginCompleter: loginCompleter,
+		cacheManager:   cacheManager,
+	}
+}
+
+// StartLogin returns the address of the provider to send the browser to and
+// the value of the state cookie the browser has to bring back to Callback.
+func (s *OIDC) StartLogin(ctx context.Context, providerName string) (string, string, error) {
+	redirectURL, stateCookie, err := s.start(ctx, providerName, 0)
+	if err != nil {
+		return "", "", fmt.Errorf("StartLogin: %w", err)
+	}
+	return redirectURL, stateCookie, nil
+}
+
+// StartLink sends a signed in user to the provider to link it to their
+// account. It is the only way to attach a provider to an account whose email
+// is not verified on both sides.
+func (s *OIDC) StartLink(ctx context.Context, providerName string, login string) (string, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return "", "", fmt.Errorf("StartLink: failed get user by login: %w", err)
+	}
+
+	redirectURL, stateCookie, err := s.start(ctx, providerName, user.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("StartLink: %w", err)
+	}
+	return redirectURL, stateCookie, nil
+}
+
+// start saves the state of a new login. The state cookie is a hash of the
+// state: it ties the login to the browser that started it, so a callback
+// link sent to somebody else can not sign them in.
+func (s *OIDC) start(ctx context.Context, providerName string, linkUserId uint) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", fmt.Errorf("start: unknown provider %q", providerName)
+	}
+
+	state, err := securetoken.Generate(16)
+	if err != nil {
+		return "", "", fmt.Errorf("start: failed generate state: %w", err)
+	}
+
+	nonce, err := securetoken.Generate(16)
+	if err != nil {
+		return "", "", fmt.Errorf("start: failed generate nonce: %w", err)
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", "", fmt.Errorf("start: %w", err)
+	}
+
+	if err := s.cacheManager.Set(oidcStateKey(state), oidcState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserId,
+	}, constants.OIDC_STATE_TTL); err != nil {
+		return "", "", fmt.Errorf("start: failed save state: %w", err)
+	}
+
+	redirectURL, err := provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return "", "", fmt.Errorf("start: %w", err)
+	}
+
+	return redirectURL, securetoken.Hash(state), nil
+}
+
+// Callback finishes a login or a link at the provider. The result is the
+// same a password login has, a two factor challenge included. stateCookie is
+// the cookie set by the start of the login, and login is the user signed in
+// in the browser, if any: a link completes only for the user who started it.
+func (s *OIDC) Callback(ctx context.Context, providerName string, code string, state string, stateCookie string, login string, r *http.Request) (*dtos.LoginResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("Callback: unknown provider %q", providerName)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(securetoken.Hash(state)), []byte(stateCookie)) != 1 {
+		return nil, fmt.Errorf("Callback: state was issued to another browser")
+	}
+
+	var saved oidcState
+	if err := s.cacheManager.Get(oidcStateKey(state), &saved); err != nil {
+		return nil, fmt.Errorf("Callback: unknown or expired state: %w", err)
+	}
+	if err := s.cacheManager.Delete(oidcStateKey(state)); err != nil {
+		return nil, fmt.Errorf("Callback: failed delete state: %w", err)
+	}
+	if saved.Provider != providerName {
+		return nil, fmt.Errorf("Callback: state was issued for another provider")
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("Callback: %w", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, saved.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Callback: %w", err)
+	}
+
+	var user *entity.User
+	if saved.LinkUserID != 0 {
+		user, err = s.linkUser(providerName, claims, saved.LinkUserID, login)
+	} else {
+		user, err = s.resolveUser(providerName, claims)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Callback: %w", err)
+	}
+
+	response, err := s.loginCompleter.CompleteLogin(user, r)
+	if err != nil {
+		return nil, fmt.Errorf("Callback: %w", err)
+	}
+
+	return response, nil
+}
+
+// resolveUser finds the account of an identity. An unknown identity is linked
+// to the account with the same email only when both the provider and the
+// account have verified it; an account with an unverified email has to link
+// the provider while signed in.
+func (s *OIDC) resolveUser(providerName string, claims *oidc.Claims) (*entity.User, error) {
+	identity, err := s.identityRepo.GetIdentity(providerName, claims.Subject)
+	if err == nil {
+		return s.userRepo.GetUserById(identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("resolveUser: %w", err)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, fmt.Errorf("resolveUser: provider did not return a verified email")
+	}
+
+	user, err := s.userRepo.GetUserByEmail(claims.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.createUser(claims)
+	} else if err == nil && !user.EmailVerified {
+		return nil, fmt.Errorf("resolveUser: email of user %s is not verified, sign in to link the provider", user.Login)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolveUser: %w", err)
+	}
+
+	if err := s.identityRepo.CreateIdentity(&entity.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, fmt.Errorf("resolveUser: %w", err)
+	}
+
+	return user, nil
+}
+
+// linkUser attaches the identity to the account that started the link, which
+// must still be the one signed in. An identity already linked to another
+// account is refused.
+func (s *OIDC) linkUser(providerName string, claims *oidc.Claims, userId uint, login string) (*entity.User, error) {
+	if login == "" {
+		return nil, fmt.Errorf("linkUser: link was started by user %d, nobody is signed in", userId)
+	}
+
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("linkUser: failed get user by login: %w", err)
+	}
+	if user.ID != userId {
+		return nil, fmt.Errorf("linkUser: link was started by user %d, not by %s", userId, login)
+	}
+
+	identity, err := s.identityRepo.GetIdentity(providerName, claims.Subject)
+	if err == nil {
+		if identity.UserID != user.ID {
+			return nil, fmt.Errorf("linkUser: identity is linked to another user")
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("linkUser: %w", err)
+	}
+
+	if err := s.identityRepo.CreateIdentity(&entity.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, fmt.Errorf("linkUser: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *OIDC) createUser(claims *oidc.Claims) (*entity.User, error) {
+	login, err := s.freeLogin(claims)
+	if err != nil {
+		return nil, fmt.Errorf("createUser: %w", err)
+	}
+
+	password, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, fmt.Errorf("createUser: failed generate password: %w", err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = login
+	}
+
+	if err := s.userRepo.CreateUser(entity.User{
+		Name:          name,
+		Login:         login,
+		Password:      password,
+		Email:         claims.Email,
+		EmailVerified: true,
+	}); err != nil {
+		return nil, fmt.Errorf("createUser: %w", err)
+	}
+
+	return s.userRepo.GetUserByLogin(login)
+}
+
+func (s *OIDC) freeLogin(claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.Split(claims.Email, "@")[0]
+	}
+	base = strings.ToLower(strings.TrimSpace(base))
+
+	login := base
+	for i := 0; i < 5; i++ {
+		_, err := s.userRepo.GetUserByLogin(login)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return login, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("freeLogin: %w", err)
+		}
+
+		suffix, err := securetoken.Generate(2)
+		if err != nil {
+			return "", fmt.Errorf("freeLogin: %w", err)
+		}
+		login = base + "-" + suffix
+	}
+
+	return "", fmt.Errorf("freeLogin: failed to find free login for %q", base)
+}
+
+func oidcStateKey(state string) string {
+	return fmt.Sprintf("oidc:state:%s", state)
+}
@@ -0,0 +1,384 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/oidc"
+	"github.com/server/pkg/oidc/oidctest"
+	"gorm.io/gorm"
+)
+
+type memoryCache struct {
+	values map[string][]byte
+}
+
+func (c *memoryCache) Get(key string, out interface{}) error {
+	value, ok := c.values[key]
+	if !ok {
+		return fmt.Errorf("Get: no value for %s", key)
+	}
+	return json.Unmarshal(value, out)
+}
+
+func (c *memoryCache) Set(key string, value interface{}, ttl time.Duration) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = encoded
+	return nil
+}
+
+func (c *memoryCache) Delete(pattern string) error {
+	delete(c.values, pattern)
+	return nil
+}
+
+type memoryOIDCUsers struct {
+	users []*entity.User
+}
+
+func (r *memoryOIDCUsers) add(user entity.User) *entity.User {
+	user.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, &user)
+	return &user
+}
+
+func (r *memoryOIDCUsers) find(match func(user *entity.User) bool) (*entity.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOIDCUsers) CreateUser(user entity.User) error {
+	r.add(user)
+	return nil
+}
+
+func (r *memoryOIDCUsers) GetUserByLogin(login string) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Login == login })
+}
+
+func (r *memoryOIDCUsers) GetUserByEmail(email string) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Email == email })
+}
+
+func (r *memoryOIDCUsers) GetUserById(id uint) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.ID == id })
+}
+
+type memoryIdentities struct {
+	identities []entity.UserIdentity
+}
+
+func (r *memoryIdentities) GetIdentity(provider string, subject string) (*entity.UserIdentity, error) {
+	for i, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &r.identities[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryIdentities) CreateIdentity(identity *entity.UserIdentity) error {
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+// sessionlessLogin stands in for Auth and reports whom a login was for.
+type sessionlessLogin struct{}
+
+func (sessionlessLogin) CompleteLogin(user *entity.User, r *http.Request) (*dtos.LoginResponse, error) {
+	return &dtos.LoginResponse{User: user, Token: "token-" + user.Login}, nil
+}
+
+// oidcFixture keeps what the browser holds between the start of a login and
+// the callback: the state cookie and the user signed in, if any.
+type oidcFixture struct {
+	server      *oidctest.Server
+	users       *memoryOIDCUsers
+	identities  *memoryIdentities
+	usecase     *OIDC
+	stateCookie string
+	signedIn    string
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+
+	server := oidctest.NewServer("client")
+	t.Cleanup(server.Close)
+
+	f := &oidcFixture{
+		server:     server,
+		users:      &memoryOIDCUsers{},
+		identities: &memoryIdentities{},
+	}
+	f.usecase = NewOIDC(
+		[]OIDCProviderInterface{
+			oidc.NewProvider(server.Config("test"), nil),
+			oidc.NewProvider(server.Config("other"), nil),
+		},
+		f.users,
+		f.identities,
+		sessionlessLogin{},
+		&memoryCache{values: make(map[string][]byte)},
+	)
+	return f
+}
+
+// login runs a login at the provider for identity and returns the code and
+// the state the provider sends back to the callback.
+func (f *oidcFixture) login(t *testing.T, identity oidctest.Identity) (string, string) {
+	t.Helper()
+
+	authURL, stateCookie, err := f.usecase.StartLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	f.stateCookie = stateCookie
+	return f.authorize(t, authURL, identity)
+}
+
+// startLink starts a link for the signed in user and returns the address of
+// the provider.
+func (f *oidcFixture) startLink(t *testing.T, login string) string {
+	t.Helper()
+
+	authURL, stateCookie, err := f.usecase.StartLink(context.Background(), "test", login)
+	if err != nil {
+		t.Fatalf("StartLink: %v", err)
+	}
+	f.stateCookie = stateCookie
+	f.signedIn = login
+	return authURL
+}
+
+func (f *oidcFixture) authorize(t *testing.T, authURL string, identity oidctest.Identity) (string, string) {
+	t.Helper()
+
+	code, state, err := f.server.Authorize(authURL, identity)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return code, state
+}
+
+func (f *oidcFixture) callback(provider string, code string, state string) (*dtos.LoginResponse, error) {
+	return f.usecase.Callback(context.Background(), provider, code, state, f.stateCookie, f.signedIn, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+var bob = oidctest.Identity{
+	Subject:       "bob-subject",
+	Email:         "bob@example.com",
+	EmailVerified: true,
+	Name:          "Bob",
+}
+
+func TestOIDCCallbackCreatesUser(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	code, state := f.login(t, bob)
+	response, err := f.callback("test", code, state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if response.User.Email != bob.Email || !response.User.EmailVerified {
+		t.Errorf("user = %+v, want a verified account for %s", response.User, bob.Email)
+	}
+	if len(f.identities.identities) != 1 || f.identities.identities[0].UserID != response.User.ID {
+		t.Errorf("identities = %+v, want one for user %d", f.identities.identities, response.User.ID)
+	}
+
+	// The next login finds the account by its identity.
+	code, state = f.login(t, bob)
+	again, err := f.callback("test", code, state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if again.User.ID != response.User.ID || len(f.users.users) != 1 {
+		t.Errorf("second login got user %d of %d users, want user %d only", again.User.ID, len(f.users.users), response.User.ID)
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownState(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	code, _ := f.login(t, bob)
+	if _, err := f.callback("test", code, "forged-state"); err == nil {
+		t.Fatal("Callback accepted a state it never issued")
+	}
+}
+
+func TestOIDCCallbackStateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	code, state := f.login(t, bob)
+	if _, err := f.callback("test", code, state); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if _, err := f.callback("test", code, state); err == nil {
+		t.Fatal("Callback accepted a state twice")
+	}
+}
+
+func TestOIDCCallbackRejectsStateOfAnotherBrowser(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	// The attacker starts a login and sends the callback to the victim,
+	// whose browser has no state cookie, or the one of its own login.
+	code, state := f.login(t, bob)
+	for _, stateCookie := range []string{"", "cookie-of-another-login"} {
+		f.stateCookie = stateCookie
+		if _, err := f.callback("test", code, state); err == nil {
+			t.Fatalf("Callback accepted state cookie %q", stateCookie)
+		}
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("Callback created %d users on a failed login", len(f.users.users))
+	}
+}
+
+func TestOIDCCallbackRejectsStateOfAnotherProvider(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	code, state := f.login(t, bob)
+	if _, err := f.callback("other", code, state); err == nil {
+		t.Fatal("Callback accepted a state issued for another provider")
+	}
+}
+
+func TestOIDCCallbackRejectsForeignNonce(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	authURL, stateCookie, err := f.usecase.StartLogin(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	f.stateCookie = stateCookie
+
+	// The provider signs a token for a nonce of another login.
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	query.Set("nonce", "nonce-of-another-login")
+	parsed.RawQuery = query.Encode()
+
+	code, state := f.authorize(t, parsed.String(), bob)
+	if _, err := f.callback("test", code, state); err == nil {
+		t.Fatal("Callback accepted an id token with a foreign nonce")
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("Callback created %d users on a failed login", len(f.users.users))
+	}
+}
+
+func TestOIDCCallbackLinksVerifiedAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	existing := f.users.add(entity.User{Login: "bob", Email: bob.Email, EmailVerified: true})
+
+	code, state := f.login(t, bob)
+	response, err := f.callback("test", code, state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if response.User.ID != existing.ID {
+		t.Errorf("logged in as user %d, want the existing user %d", response.User.ID, existing.ID)
+	}
+	if len(f.identities.identities) != 1 {
+		t.Errorf("identities = %+v, want one", f.identities.identities)
+	}
+}
+
+func TestOIDCCallbackRefusesUnverifiedAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.users.add(entity.User{Login: "bob", Email: bob.Email})
+
+	code, state := f.login(t, bob)
+	if _, err := f.callback("test", code, state); err == nil {
+		t.Fatal("Callback linked an account whose email is not verified")
+	}
+	if len(f.identities.identities) != 0 {
+		t.Errorf("identities = %+v, want none", f.identities.identities)
+	}
+}
+
+func TestOIDCCallbackRefusesUnverifiedClaim(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.users.add(entity.User{Login: "bob", Email: bob.Email, EmailVerified: true})
+
+	unverified := bob
+	unverified.EmailVerified = false
+
+	code, state := f.login(t, unverified)
+	if _, err := f.callback("test", code, state); err == nil {
+		t.Fatal("Callback linked an account by an email the provider did not verify")
+	}
+	if len(f.identities.identities) != 0 {
+		t.Errorf("identities = %+v, want none", f.identities.identities)
+	}
+}
+
+func TestOIDCLinkAttachesSignedInUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.users.add(entity.User{Login: "someone", Email: bob.Email})
+	signedIn := f.users.add(entity.User{Login: "bob", Email: "bob@elsewhere.com"})
+
+	code, state := f.authorize(t, f.startLink(t, signedIn.Login), bob)
+	response, err := f.callback("test", code, state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if response.User.ID != signedIn.ID {
+		t.Errorf("linked user %d, want the signed in user %d", response.User.ID, signedIn.ID)
+	}
+	if len(f.identities.identities) != 1 || f.identities.identities[0].UserID != signedIn.ID {
+		t.Errorf("identities = %+v, want one for user %d", f.identities.identities, signedIn.ID)
+	}
+}
+
+func TestOIDCLinkRefusesIdentityOfAnotherUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	owner := f.users.add(entity.User{Login: "bob", Email: bob.Email, EmailVerified: true})
+	intruder := f.users.add(entity.User{Login: "mallory", Email: "mallory@example.com", EmailVerified: true})
+	f.identities.CreateIdentity(&entity.UserIdentity{UserID: owner.ID, Provider: "test", Subject: bob.Subject})
+
+	code, state := f.authorize(t, f.startLink(t, intruder.Login), bob)
+	_, err := f.callback("test", code, state)
+	if err == nil || !strings.Contains(err.Error(), "another user") {
+		t.Fatalf("Callback = %v, want the identity refused", err)
+	}
+}
+
+func TestOIDCLinkRefusesAnotherSignedInUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	attacker := f.users.add(entity.User{Login: "mallory", Email: "mallory@example.com", EmailVerified: true})
+	f.users.add(entity.User{Login: "bob", Email: "bob@elsewhere.com", EmailVerified: true})
+
+	for _, signedIn := range []string{"", "bob"} {
+		code, state := f.authorize(t, f.startLink(t, attacker.Login), bob)
+		f.signedIn = signedIn
+		if _, err := f.callback("test", code, state); err == nil {
+			t.Fatalf("Callback linked the identity with %q signed in to the account of %s", signedIn, attacker.Login)
+		}
+	}
+	if len(f.identities.identities) != 0 {
+		t.Errorf("identities = %+v, want none", f.identities.identities)
+	}
+}
@@ -0,0 +1,309 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+)
+
+type OrganizationRepoInterface interface {
+	CreateOrganization(organization *entity.Organization, ownerId uint, ownerRole string) error
+	GetOrganizationById(id uint) (*entity.Organization, error)
+	GetOrganizationsByUserId(userId uint) ([]entity.Organization, error)
+	GetMember(organizationId uint, userId uint) (*entity.OrganizationMember, error)
+	GetMembers(organizationId uint) ([]entity.OrganizationMember, error)
+	AddMember(member *entity.OrganizationMember) error
+	UpdateMemberRole(organizationId uint, userId uint, role string) error
+	DeleteMember(organizationId uint, userId uint) error
+	CountMembersByRole(organizationId uint, role string) (int64, error)
+	CreateInvite(invite *entity.OrganizationInvite) error
+	GetInviteByHash(hash string) (*entity.OrganizationInvite, error)
+	GetPendingInvites(organizationId uint) ([]entity.OrganizationInvite, error)
+	MarkInviteAccepted(id uint, acceptedAt time.Time) error
+	DeleteInvite(id uint, organizationId uint) error
+}
+
+type OrganizationTestRepoInterface interface {
+	GetTestsByOrganizationId(organizationId uint, limit, offset int) ([]entity.Test, int64, error)
+}
+
+type Organization struct {
+	organizationRepo OrganizationRepoInterface
+	testRepo         OrganizationTestRepoInterface
+	userRepo         UserRepoInterfaceGetByLogin
+	mailer           MailerInterface
+	config           *configs.Config
+}
+
+func NewOrganization(
+	organizationRepo OrganizationRepoInterface,
+	testRepo OrganizationTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	mailer MailerInterface,
+	config *configs.Config,
+) *Organization {
+	return &Organization{
+		organizationRepo: organizationRepo,
+		testRepo:         testRepo,
+		userRepo:         userRepo,
+		mailer:           mailer,
+		config:           config,
+	}
+}
+
+func (s *Organization) CreateOrganization(login string, data *dtos.CreateOrganizationRequest) (*entity.Organization, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("CreateOrganization: failed get user by login: %w", err)
+	}
+
+	organization := &entity.Organization{Name: data.Name}
+	if err := s.organizationRepo.CreateOrganization(organization, user.ID, constants.OrgRoleOwner); err != nil {
+		return nil, fmt.Errorf("CreateOrganization: %w", err)
+	}
+
+	return organization, nil
+}
+
+func (s *Organization) GetOrganizations(login string) ([]entity.Organization, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetOrganizations: failed get user by login: %w", err)
+	}
+
+	organizations, err := s.organizationRepo.GetOrganizationsByUserId(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetOrganizations: %w", err)
+	}
+
+	return organizations, nil
+}
+
+func (s *Organization) GetMembers(login string, organizationId uint) ([]entity.OrganizationMember, error) {
+	if _, _, err := s.requireRole(login, organizationId, constants.OrgRoleViewer); err != nil {
+		return nil, fmt.Errorf("GetMembers: %w", err)
+	}
+
+	members, err := s.organizationRepo.GetMembers(organizationId)
+	if err != nil {
+		return nil, fmt.Errorf("GetMembers: %w", err)
+	}
+
+	return members, nil
+}
+
+func (s *Organization) GetTests(login string, organizationId uint, limit, offset int) ([]entity.Test, int64, error) {
+	if _, _, err := s.requireRole(login, organizationId, constants.OrgRoleViewer); err != nil {
+		return nil, 0, fmt.Errorf("GetTests: %w", err)
+	}
+
+	tests, count, err := s.testRepo.GetTestsByOrganizationId(organizationId, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetTests: %w", err)
+	}
+
+	return tests, count, nil
+}
+
+func (s *Organization) ChangeMemberRole(login string, organizationId uint, userId uint, role string) error {
+	_, actor, err := s.requireRole(login, organizationId, constants.OrgRoleAdmin)
+	if err != nil {
+		return fmt.Errorf("ChangeMemberRole: %w", err)
+	}
+
+	target, err := s.organizationRepo.GetMember(organizationId, userId)
+	if err != nil {
+		return fmt.Errorf("ChangeMemberRole: %w", err)
+	}
+
+	if (role == constants.OrgRoleOwner || target.Role == constants.OrgRoleOwner) && actor.Role != constants.OrgRoleOwner {
+		return fmt.Errorf("ChangeMemberRole: only owners can grant or revoke the owner role")
+	}
+
+	if target.Role == constants.OrgRoleOwner && role != constants.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(organizationId); err != nil {
+			return fmt.Errorf("ChangeMemberRole: %w", err)
+		}
+	}
+
+	if err := s.organizationRepo.UpdateMemberRole(organizationId, userId, role); err != nil {
+		return fmt.Errorf("ChangeMemberRole: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes userId from the organization. Members may always remove
+// themselves; removing someone else requires the admin role.
+func (s *Organization) RemoveMember(login string, organizationId uint, userId uint) error {
+	user, actor, err := s.requireRole(login, organizationId, constants.OrgRoleViewer)
+	if err != nil {
+		return fmt.Errorf("RemoveMember: %w", err)
+	}
+
+	target, err := s.organizationRepo.GetMember(organizationId, userId)
+	if err != nil {
+		return fmt.Errorf("RemoveMember: %w", err)
+	}
+
+	if user.ID != userId {
+		if constants.OrgRoleRank[actor.Role] < constants.OrgRoleRank[constants.OrgRoleAdmin] {
+			return fmt.Errorf("RemoveMember: organization role %s can not remove members", actor.Role)
+		}
+		if target.Role == constants.OrgRoleOwner && actor.Role != constants.OrgRoleOwner {
+			return fmt.Errorf("RemoveMember: only owners can remove owners")
+		}
+	}
+
+	if target.Role == constants.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(organizationId); err != nil {
+			return fmt.Errorf("RemoveMember: %w", err)
+		}
+	}
+
+	if err := s.organizationRepo.DeleteMember(organizationId, userId); err != nil {
+		return fmt.Errorf("RemoveMember: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Organization) InviteMember(login string, organizationId uint, data *dtos.InviteMemberRequest) error {
+	user, _, err := s.requireRole(login, organizationId, constants.OrgRoleAdmin)
+	if err != nil {
+		return fmt.Errorf("InviteMember: %w", err)
+	}
+
+	organization, err := s.organizationRepo.GetOrganizationById(organizationId)
+	if err != nil {
+		return fmt.Errorf("InviteMember: %w", err)
+	}
+
+	token, err := securetoken.Generate(32)
+	if err != nil {
+		return fmt.Errorf("InviteMember: failed generate invite token: %w", err)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(data.Email))
+	if err := s.organizationRepo.CreateInvite(&entity.OrganizationInvite{
+		OrganizationID: organizationId,
+		Email:          email,
+		Role:           data.Role,
+		TokenHash:      securetoken.Hash(token),
+		InvitedByID:    user.ID,
+		ExpiresAt:      time.Now().Add(constants.ORGANIZATION_INVITE_TTL),
+	}); err != nil {
+		return fmt.Errorf("InviteMember: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/organizations/invite?token=%s", s.config.CLIENT_URL, url.QueryEscape(token))
+	body := fmt.Sprintf("%s приглашает вас в организацию «%s». Чтобы присоединиться, перейдите по ссылке: %s", user.Name, organization.Name, link)
+	if err := s.mailer.Send(email, "Приглашение в организацию", body); err != nil {
+		return fmt.Errorf("InviteMember: failed send invite email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Organization) GetInvites(login string, organizationId uint) ([]entity.OrganizationInvite, error) {
+	if _, _, err := s.requireRole(login, organizationId, constants.OrgRoleAdmin); err != nil {
+		return nil, fmt.Errorf("GetInvites: %w", err)
+	}
+
+	invites, err := s.organizationRepo.GetPendingInvites(organizationId)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvites: %w", err)
+	}
+
+	return invites, nil
+}
+
+func (s *Organization) RevokeInvite(login string, organizationId uint, inviteId uint) error {
+	if _, _, err := s.requireRole(login, organizationId, constants.OrgRoleAdmin); err != nil {
+		return fmt.Errorf("RevokeInvite: %w", err)
+	}
+
+	if err := s.organizationRepo.DeleteInvite(inviteId, organizationId); err != nil {
+		return fmt.Errorf("RevokeInvite: %w", err)
+	}
+
+	return nil
+}
+
+// AcceptInvite adds the current user to the organization. The invite is bound
+// to the email it was sent to, so the account must own that verified address.
+func (s *Organization) AcceptInvite(login string, token string) (*entity.Organization, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("AcceptInvite: failed get user by login: %w", err)
+	}
+
+	invite, err := s.organizationRepo.GetInviteByHash(securetoken.Hash(token))
+	if err != nil {
+		return nil, fmt.Errorf("AcceptInvite: %w", err)
+	}
+
+	if invite.AcceptedAt != nil || time.Now().After(invite.ExpiresAt) {
+		return nil, fmt.Errorf("AcceptInvite: invite is expired or already used")
+	}
+
+	if !strings.EqualFold(invite.Email, user.Email) || !user.EmailVerified {
+		return nil, fmt.Errorf("AcceptInvite: invite was sent to another email")
+	}
+
+	if err := s.organizationRepo.MarkInviteAccepted(invite.ID, time.Now()); err != nil {
+		return nil, fmt.Errorf("AcceptInvite: %w", err)
+	}
+
+	if _, err := s.organizationRepo.GetMember(invite.OrganizationID, user.ID); err == nil {
+		return s.organizationRepo.GetOrganizationById(invite.OrganizationID)
+	}
+
+	if err := s.organizationRepo.AddMember(&entity.OrganizationMember{
+		OrganizationID: invite.OrganizationID,
+		UserID:         user.ID,
+		Role:           invite.Role,
+	}); err != nil {
+		return nil, fmt.Errorf("AcceptInvite: %w", err)
+	}
+
+	return s.organizationRepo.GetOrganizationById(invite.OrganizationID)
+}
+
+func (s *Organization) requireRole(login string, organizationId uint, role string) (*entity.User, *entity.OrganizationMember, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, fmt.Errorf("requireRole: failed get user by login: %w", err)
+	}
+
+	member, err := s.organizationRepo.GetMember(organizationId, user.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("requireRole: user is not an organization member: %w", err)
+	}
+
+	if constants.OrgRoleRank[member.Role] < constants.OrgRoleRank[role] {
+		return nil, nil, fmt.Errorf("requireRole: organization role %s is lower than %s", member.Role, role)
+	}
+
+	return user, member, nil
+}
+
+func (s *Organization) ensureAnotherOwner(organizationId uint) error {
+	owners, err := s.organizationRepo.CountMembersByRole(organizationId, constants.OrgRoleOwner)
+	if err != nil {
+		return fmt.Errorf("ensureAnotherOwner: %w", err)
+	}
+
+	if owners < 2 {
+		return fmt.Errorf("ensureAnotherOwner: organization must keep at least one owner")
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type MailerInterface interface {
+	Send(to string, subject string, body string) error
+}
+
+type PasswordUserRepoInterface interface {
+	GetUserByEmail(email string) (*entity.User, error)
+	GetUserByLogin(login string) (*entity.User, error)
+	GetUserById(id uint) (*entity.User, error)
+	UpdatePassword(userId uint, password string) error
+}
+
+type PasswordResetRepoInterface interface {
+	CreateResetToken(token *entity.PasswordResetToken) error
+	GetResetTokenByHash(hash string) (*entity.PasswordResetToken, error)
+	MarkResetTokenUsed(id uint, usedAt time.Time) error
+	DeleteResetTokensByUserId(userId uint) error
+}
+
+type PasswordSessionRepoInterface interface {
+	DeleteSessionsByUserId(userId uint) error
+	DeleteOtherSessions(userId uint, keepSessionId uint) error
+}
+
+type Password struct {
+	userRepo     PasswordUserRepoInterface
+	resetRepo    PasswordResetRepoInterface
+	sessionRepo  PasswordSessionRepoInterface
+	cacheManager CacheManagerV2Interface
+	mailer       MailerInterface
+	config       *configs.Config
+}
+
+func NewPassword(
+	userRepo PasswordUserRepoInterface,
+	resetRepo PasswordResetRepoInterface,
+	sessionRepo PasswordSessionRepoInterface,
+	cacheManager CacheManagerV2Interface,
+	mailer MailerInterface,
+	config *configs.Config,
+) *Password {
+	return &Password{
+		userRepo:     userRepo,
+		resetRepo:    resetRepo,
+		sessionRepo:  sessionRepo,
+		cacheManager: cacheManager,
+		mailer:       mailer,
+		config:       config,
+	}
+}
+
+func (s *Password) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ForgotPassword: failed get user by email: %w", err)
+	}
+
+	if err := s.resetRepo.DeleteResetTokensByUserId(user.ID); err != nil {
+		return fmt.Errorf("ForgotPassword: failed delete previous reset tokens: %w", err)
+	}
+
+	token, err := securetoken.Generate(32)
+	if err != nil {
+		return fmt.Errorf("ForgotPassword: failed generate reset token: %w", err)
+	}
+
+	if err := s.resetRepo.CreateResetToken(&entity.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: securetoken.Hash(token),
+		ExpiresAt: time.Now().Add(constants.PASSWORD_RESET_TOKEN_TTL),
+	}); err != nil {
+		return fmt.Errorf("ForgotPassword: failed save reset token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/password/reset?token=%s", s.config.CLIENT_URL, url.QueryEscape(token))
+	body := fmt.Sprintf("Чтобы сбросить пароль, перейдите по ссылке: %s\nСсылка действительна %d минут.", link, int(constants.PASSWORD_RESET_TOKEN_TTL.Minutes()))
+	if err := s.mailer.Send(user.Email, "Сброс пароля", body); err != nil {
+		return fmt.Errorf("ForgotPassword: failed send reset email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Password) ResetPassword(data *dtos.ResetPasswordRequest) error {
+	token, err := s.resetRepo.GetResetTokenByHash(securetoken.Hash(data.Token))
+	if err != nil {
+		return fmt.Errorf("ResetPassword: failed get reset token: %w", err)
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return fmt.Errorf("ResetPassword: reset token is expired or already used")
+	}
+
+	if err := s.resetRepo.MarkResetTokenUsed(token.ID, time.Now()); err != nil {
+		return fmt.Errorf("ResetPassword: %w", err)
+	}
+
+	user, err := s.userRepo.GetUserById(token.UserID)
+	if err != nil {
+		return fmt.Errorf("ResetPassword: failed get user by id: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, data.Password); err != nil {
+		return fmt.Errorf("ResetPassword: failed update password: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSessionsByUserId(user.ID); err != nil {
+		return fmt.Errorf("ResetPassword: failed revoke sessions: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", user.Login)); err != nil {
+		return fmt.Errorf("ResetPassword: failed delete user from cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Password) ChangePassword(login string, sessionID uint, data *dtos.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("ChangePassword: failed get user by login: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.CurrentPassword)); err != nil {
+		return fmt.Errorf("ChangePassword: invalid current password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, data.NewPassword); err != nil {
+		return fmt.Errorf("ChangePassword: failed update password: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteOtherSessions(user.ID, sessionID); err != nil {
+		return fmt.Errorf("ChangePassword: failed revoke other sessions: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", login)); err != nil {
+		return fmt.Errorf("ChangePassword: failed delete user from cache: %w", err)
+	}
+
+	return nil
+}
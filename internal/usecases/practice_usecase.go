@@ -0,0 +1,408 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/srs"
+	"gorm.io/gorm"
+)
+
+type PracticeRepoInterface interface {
+	GetQuestionById(id uint) (*entity.Question, error)
+	GetCard(userId uint, questionId uint) (*entity.PracticeCard, error)
+	GetTestCards(userId uint, testId uint) ([]entity.PracticeCard, error)
+	GetDueCards(userId uint, before time.Time) ([]entity.PracticeCard, error)
+	SaveReview(card *entity.PracticeCard, review *entity.PracticeReview) error
+}
+
+type PracticeTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdatePracticeSettings(testId uint, enabled bool) error
+}
+
+type PracticeAttemptRepoInterface interface {
+	CountOpenAttempts(userId uint, testId uint) (int64, error)
+}
+
+type Practice struct {
+	practiceRepo PracticeRepoInterface
+	testRepo     PracticeTestRepoInterface
+	attemptRepo  PracticeAttemptRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	access       TestAccessInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewPractice(
+	practiceRepo PracticeRepoInterface,
+	testRepo PracticeTestRepoInterface,
+	attemptRepo PracticeAttemptRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *Practice {
+	return &Practice{
+		practiceRepo: practiceRepo,
+		testRepo:     testRepo,
+		attemptRepo:  attemptRepo,
+		userRepo:     userRepo,
+		access:       access,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *Practice) UpdateSettings(login string, testId uint, data *dtos.UpdatePracticeRequest) (*dtos.PracticeSettingsResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateSettings: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateSettings: failed get test by id: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateSettings: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("UpdateSettings: user %s can not edit test %d", login, testId)
+	}
+
+	if data.Enabled && hidesQuestions(test) {
+		return nil, fmt.Errorf("UpdateSettings: questions of test %d are not shown at once, it can not be practiced", testId)
+	}
+
+	if err := s.testRepo.UpdatePracticeSettings(test.ID, data.Enabled); err != nil {
+		return nil, fmt.Errorf("UpdateSettings: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("UpdateSettings: failed to invalidate test cache: %w", err)
+	}
+
+	return &dtos.PracticeSettingsResponse{
+		TestID:  test.ID,
+		Enabled: data.Enabled,
+	}, nil
+}
+
+// GetTestPractice lists the questions of a test that can be drilled with how
+// far the user got with each.
+func (s *Practice) GetTestPractice(login string, testId uint) ([]dtos.PracticeQuestion, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetTestPractice: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetTestPractice: failed get test by id: %w", err)
+	}
+
+	allowed, err := s.canPractice(test, user, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("GetTestPractice: %w", err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("GetTestPractice: user %s can not practice test %d", login, testId)
+	}
+
+	cards, err := s.practiceRepo.GetTestCards(user.ID, test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetTestPractice: %w", err)
+	}
+
+	byQuestion := make(map[uint]*entity.PracticeCard, len(cards))
+	for i := range cards {
+		byQuestion[cards[i].QuestionID] = &cards[i]
+	}
+
+	questions := []dtos.PracticeQuestion{}
+	for _, question := range test.Questions {
+		if !isPracticeItem(question) {
+			continue
+		}
+		questions = append(questions, practiceQuestion(test, question, byQuestion[question.ID]))
+	}
+
+	return questions, nil
+}
+
+// GetDue lists the questions due for review by the end of today across all
+// tests the user may still practice.
+func (s *Practice) GetDue(login string) ([]dtos.PracticeQuestion, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetDue: failed get user by login: %w", err)
+	}
+
+	now := time.Now()
+	year, month, day := now.Date()
+	cards, err := s.practiceRepo.GetDueCards(user.ID, time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		return nil, fmt.Errorf("GetDue: %w", err)
+	}
+
+	tests := make(map[uint]*entity.Test)
+	due := []dtos.PracticeQuestion{}
+	for i, card := range cards {
+		test, checked := tests[card.TestID]
+		if !checked {
+			test, err = s.testRepo.GetTestById(card.TestID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("GetDue: %w", err)
+			}
+
+			if test != nil {
+				allowed, err := s.canPractice(test, user, now)
+				if err != nil {
+					return nil, fmt.Errorf("GetDue: %w", err)
+				}
+				if !allowed {
+					test = nil
+				}
+			}
+			tests[card.TestID] = test
+		}
+
+		if test == nil {
+			continue
+		}
+
+		question, ok := questionsById(test)[card.QuestionID]
+		if !ok || !isPracticeItem(question) {
+			continue
+		}
+		due = append(due, practiceQuestion(test, question, &cards[i]))
+	}
+
+	return due, nil
+}
+
+// Answer checks a practice answer right away and schedules the next review
+// of the question. Nothing of it shows up in the results of the test. The
+// feedback tells no more than the released review policy of the test:
+// correctness from score on, the correct variants and explanations in full
+// only. Practice is
+// refused while the user has an open attempt on the test.
+func (s *Practice) Answer(login string, questionId uint, data *dtos.PracticeAnswerRequest) (*dtos.PracticeAnswerResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: failed get user by login: %w", err)
+	}
+
+	found, err := s.practiceRepo.GetQuestionById(questionId)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(found.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: failed get test by id: %w", err)
+	}
+
+	now := time.Now()
+	allowed, err := s.canPractice(test, user, now)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("Answer: user %s can not practice test %d", login, test.ID)
+	}
+
+	question, ok := questionsById(test)[questionId]
+	if !ok || !isPracticeItem(question) {
+		return nil, fmt.Errorf("Answer: question %d can not be practiced", questionId)
+	}
+
+	open, err := s.attemptRepo.CountOpenAttempts(user.ID, test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+	if open > 0 {
+		return nil, fmt.Errorf("Answer: user %s has an open attempt on test %d", login, test.ID)
+	}
+
+	policy, err := s.feedbackPolicy(test, user, now)
+	if err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+	full := policy == constants.ReviewFull
+
+	correct := true
+	variants := make([]dtos.ReviewVariant, len(question.Variants))
+	for i, variant := range question.Variants {
+		selected := slices.Contains(data.Selected, variant.ID)
+		correct = correct && selected == variant.IsCorrect
+		variants[i] = dtos.ReviewVariant{
+			ID:       variant.ID,
+			Name:     variant.Name,
+			Selected: selected,
+		}
+		if full {
+			isCorrect := variant.IsCorrect
+			variants[i].IsCorrect = &isCorrect
+			variants[i].Explanation = variant.Explanation
+		}
+	}
+
+	for _, variantId := range data.Selected {
+		if !slices.ContainsFunc(question.Variants, func(variant entity.Variant) bool { return variant.ID == variantId }) {
+			return nil, fmt.Errorf("Answer: variant %d does not belong to question %d", variantId, question.ID)
+		}
+	}
+
+	card, err := s.practiceRepo.GetCard(user.ID, question.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fresh := srs.New()
+		card = &entity.PracticeCard{
+			UserID:     user.ID,
+			QuestionID: question.ID,
+			EaseFactor: fresh.EaseFactor,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+
+	quality := practiceQuality(correct, data.Quality)
+	scheduled := srs.Review(srs.Card{
+		Repetitions: card.Repetitions,
+		Interval:    card.IntervalDays,
+		EaseFactor:  card.EaseFactor,
+	}, quality)
+
+	card.TestID = test.ID
+	card.Repetitions = scheduled.Repetitions
+	card.IntervalDays = scheduled.Interval
+	card.EaseFactor = scheduled.EaseFactor
+	card.DueAt = srs.Due(scheduled, now)
+	card.LastReviewedAt = &now
+	if quality < srs.PassQuality {
+		card.Lapses++
+	}
+
+	selected := make([]string, len(data.Selected))
+	for i, variantId := range data.Selected {
+		selected[i] = strconv.FormatUint(uint64(variantId), 10)
+	}
+
+	if err := s.practiceRepo.SaveReview(card, &entity.PracticeReview{
+		Selected:   strings.Join(selected, ","),
+		Correct:    correct,
+		Quality:    quality,
+		ReviewedAt: now,
+	}); err != nil {
+		return nil, fmt.Errorf("Answer: %w", err)
+	}
+
+	feedback := &dtos.PracticeAnswerResponse{
+		QuestionID:   question.ID,
+		Variants:     variants,
+		Repetitions:  card.Repetitions,
+		IntervalDays: card.IntervalDays,
+		EaseFactor:   card.EaseFactor,
+		DueAt:        card.DueAt,
+	}
+	if policy != constants.ReviewNever {
+		feedback.Correct = &correct
+	}
+	if full {
+		feedback.Explanation = question.Explanation
+	}
+
+	return feedback, nil
+}
+
+// feedbackPolicy is the review policy practice answers of the user are shown
+// under. Viewers of the test see everything, as in the review of attempts;
+// everybody else gets nothing until reviews of the test are released, with
+// the closing time of the test as the deadline.
+func (s *Practice) feedbackPolicy(test *entity.Test, user *entity.User, now time.Time) (string, error) {
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return "", fmt.Errorf("feedbackPolicy: %w", err)
+	}
+
+	if canViewPrivateTest(role) {
+		return constants.ReviewFull, nil
+	}
+	if test.ReviewPolicy == "" || !reviewReleased(test, test.ClosesAt, now) {
+		return constants.ReviewNever, nil
+	}
+	return test.ReviewPolicy, nil
+}
+
+// canPractice reports whether the user may drill the test. Editors always
+// may, everybody else only when practice is on, the test could be passed and
+// it does not hide a part of its questions from the takers.
+func (s *Practice) canPractice(test *entity.Test, user *entity.User, now time.Time) (bool, error) {
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return false, err
+	}
+
+	if canEditTest(role) {
+		return true, nil
+	}
+
+	if !test.Practice || hidesQuestions(test) || (!canViewPrivateTest(role) && !isTestAvailable(test, now)) {
+		return false, nil
+	}
+
+	return s.access.CanPass(test, user, role)
+}
+
+// hidesQuestions tells whether takers see only a part of the questions of the
+// test: an adaptive test keeps its item bank hidden and a sectioned test the
+// sections not yet entered. Practice would list all of them.
+func hidesQuestions(test *entity.Test) bool {
+	return test.Adaptive || len(test.Sections) > 0
+}
+
+// isPracticeItem tells whether the question can be checked on the spot
+// without values drawn for an attempt, which leaves choice questions.
+func isPracticeItem(question entity.Question) bool {
+	return question.Type == constants.QuestionChoice && len(question.Variants) > 0
+}
+
+// practiceQuality is the SM-2 quality of an answer. A rating of the user is
+// kept within what the answer allows: a wrong answer is always a lapse and a
+// right one never is.
+func practiceQuality(correct bool, rating *int) int {
+	if !correct {
+		if rating == nil {
+			return 1
+		}
+		return min(*rating, srs.PassQuality-1)
+	}
+
+	if rating == nil {
+		return 4
+	}
+	return max(*rating, srs.PassQuality)
+}
+
+func practiceQuestion(test *entity.Test, question entity.Question, card *entity.PracticeCard) dtos.PracticeQuestion {
+	practice := dtos.PracticeQuestion{
+		TestID:   test.ID,
+		TestName: test.Name,
+		Question: dtos.MapQuestionToGetQuestionResponse(&question),
+	}
+	if card != nil {
+		practice.Repetitions = card.Repetitions
+		practice.Lapses = card.Lapses
+		practice.DueAt = &card.DueAt
+	}
+	return practice
+}
@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+)
+
+type RubricRepoInterface interface {
+	GetQuestionById(id uint) (*entity.Question, error)
+	ReplaceRubric(questionId uint, criteria []entity.RubricCriterion, maxPoints float64) error
+}
+
+type Rubric struct {
+	rubricRepo   RubricRepoInterface
+	testRepo     GradingTestRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	access       TestAccessInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewRubric(
+	rubricRepo RubricRepoInterface,
+	testRepo GradingTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *Rubric {
+	return &Rubric{
+		rubricRepo:   rubricRepo,
+		testRepo:     testRepo,
+		userRepo:     userRepo,
+		access:       access,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *Rubric) GetRubric(login string, questionId uint) (*dtos.RubricResponse, error) {
+	question, role, err := s.questionAndRole(login, questionId)
+	if err != nil {
+		return nil, fmt.Errorf("GetRubric: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetRubric: user %s can not view rubric of question %d", login, questionId)
+	}
+
+	return &dtos.RubricResponse{
+		QuestionID: question.ID,
+		MaxPoints:  question.MaxPoints,
+		Criteria:   question.Criteria,
+	}, nil
+}
+
+// SetRubric replaces the rubric of a text question. An empty list of
+// criteria removes the rubric.
+func (s *Rubric) SetRubric(login string, questionId uint, data *dtos.UpdateRubricRequest) (*dtos.RubricResponse, error) {
+	question, role, err := s.questionAndRole(login, questionId)
+	if err != nil {
+		return nil, fmt.Errorf("SetRubric: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("SetRubric: user %s can not edit question %d", login, questionId)
+	}
+
+	if question.Type != constants.QuestionText {
+		return nil, fmt.Errorf("SetRubric: question %d is not a text question", question.ID)
+	}
+
+	names := make(map[string]bool, len(data.Criteria))
+	criteria := make([]entity.RubricCriterion, len(data.Criteria))
+	for i, input := range data.Criteria {
+		name := strings.TrimSpace(input.Name)
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("SetRubric: duplicate criterion %s", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		levels := make([]entity.RubricLevel, len(input.Levels))
+		for j, level := range input.Levels {
+			levels[j] = entity.RubricLevel{
+				Name:        strings.TrimSpace(level.Name),
+				Description: level.Description,
+				Points:      level.Points,
+			}
+		}
+
+		criteria[i] = entity.RubricCriterion{
+			QuestionID:  question.ID,
+			Name:        name,
+			Description: input.Description,
+			Position:    i,
+			Levels:      levels,
+		}
+	}
+
+	maxPoints := question.MaxPoints
+	if len(criteria) > 0 {
+		maxPoints = rubricMaxPoints(criteria)
+	}
+
+	if err := s.rubricRepo.ReplaceRubric(question.ID, criteria, maxPoints); err != nil {
+		return nil, fmt.Errorf("SetRubric: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", question.TestID)); err != nil {
+		return nil, fmt.Errorf("SetRubric: failed delete test from cache: %w", err)
+	}
+
+	return &dtos.RubricResponse{
+		QuestionID: question.ID,
+		MaxPoints:  maxPoints,
+		Criteria:   criteria,
+	}, nil
+}
+
+func (s *Rubric) questionAndRole(login string, questionId uint) (*entity.Question, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: failed get user by login: %w", err)
+	}
+
+	question, err := s.rubricRepo.GetQuestionById(questionId)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(question.TestID)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, "", fmt.Errorf("questionAndRole: %w", err)
+	}
+
+	return question, role, nil
+}
+
+// rubricMaxPoints is the sum of the best level of every criterion.
+func rubricMaxPoints(criteria []entity.RubricCriterion) float64 {
+	var total float64
+	for _, criterion := range criteria {
+		total += criterionMaxPoints(criterion)
+	}
+	return total
+}
+
+func criterionMaxPoints(criterion entity.RubricCriterion) float64 {
+	var best float64
+	for _, level := range criterion.Levels {
+		if level.Points > best {
+			best = level.Points
+		}
+	}
+	return best
+}
+
+// scoreRubric turns per criterion grades into the points of the answer. Every
+// criterion of the rubric must be graded, either with a level or with points
+// up to its best level.
+func scoreRubric(criteria []entity.RubricCriterion, inputs []dtos.CriterionScoreInput) (float64, []entity.AnswerCriterionScore, error) {
+	byId := make(map[uint]dtos.CriterionScoreInput, len(inputs))
+	for _, input := range inputs {
+		if _, ok := byId[input.CriterionID]; ok {
+			return 0, nil, fmt.Errorf("scoreRubric: criterion %d is graded twice", input.CriterionID)
+		}
+		byId[input.CriterionID] = input
+	}
+
+	if len(byId) != len(criteria) {
+		return 0, nil, fmt.Errorf("scoreRubric: every criterion of the rubric must be graded")
+	}
+
+	var total float64
+	scores := make([]entity.AnswerCriterionScore, len(criteria))
+	for i, criterion := range criteria {
+		input, ok := byId[criterion.ID]
+		if !ok {
+			return 0, nil, fmt.Errorf("scoreRubric: criterion %d is not graded", criterion.ID)
+		}
+
+		score := entity.AnswerCriterionScore{CriterionID: criterion.ID}
+		switch {
+		case input.LevelID != nil:
+			found := false
+			for _, level := range criterion.Levels {
+				if level.ID == *input.LevelID {
+					levelId := level.ID
+					score.LevelID = &levelId
+					score.Points = level.Points
+					found = true
+					break
+				}
+			}
+			if !found {
+				return 0, nil, fmt.Errorf("scoreRubric: level %d does not belong to criterion %d", *input.LevelID, criterion.ID)
+			}
+		case input.Points != nil:
+			if *input.Points > criterionMaxPoints(criterion) {
+				return 0, nil, fmt.Errorf("scoreRubric: criterion %d can get at most %.2f points", criterion.ID, criterionMaxPoints(criterion))
+			}
+			score.Points = *input.Points
+		default:
+			return 0, nil, fmt.Errorf("scoreRubric: criterion %d needs a level or points", criterion.ID)
+		}
+
+		total += score.Points
+		scores[i] = score
+	}
+
+	return total, scores, nil
+}
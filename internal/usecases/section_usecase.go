@@ -0,0 +1,394 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+)
+
+type SectionRepoInterface interface {
+	CountOpenAttempts(testId uint) (int64, error)
+	ReplaceSections(testId uint, sections []entity.Section, questionIds [][]uint) error
+	MoveAttempt(attemptId uint, fromSectionId uint, toSectionId uint, states []entity.AttemptSection, answers []entity.AttemptAnswer, questionIds []uint) error
+}
+
+type Section struct {
+	sectionRepo  SectionRepoInterface
+	testRepo     GradingTestRepoInterface
+	attemptRepo  AttemptRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	attempts     AttemptOpenerInterface
+	access       TestAccessInterface
+	sandbox      CodeSandboxInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewSection(
+	sectionRepo SectionRepoInterface,
+	testRepo GradingTestRepoInterface,
+	attemptRepo AttemptRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	attempts AttemptOpenerInterface,
+	access TestAccessInterface,
+	sandbox CodeSandboxInterface,
+	cacheManager CacheManagerV2Interface,
+) *Section {
+	return &Section{
+		sectionRepo:  sectionRepo,
+		testRepo:     testRepo,
+		attemptRepo:  attemptRepo,
+		userRepo:     userRepo,
+		attempts:     attempts,
+		access:       access,
+		sandbox:      sandbox,
+		cacheManager: cacheManager,
+	}
+}
+
+// SetSections replaces the sections of a test. Every question has to be in
+// exactly one section, and the sections can not change while attempts are
+// going through them.
+func (s *Section) SetSections(login string, testId uint, data *dtos.UpdateSectionsRequest) ([]dtos.GetSectionResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("SetSections: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("SetSections: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("SetSections: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("SetSections: user %s can not edit test %d", login, testId)
+	}
+
+	if test.Adaptive && len(data.Sections) > 0 {
+		return nil, fmt.Errorf("SetSections: test %d is adaptive", testId)
+	}
+
+	open, err := s.sectionRepo.CountOpenAttempts(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("SetSections: %w", err)
+	}
+	if open > 0 {
+		return nil, fmt.Errorf("SetSections: test %d has %d open attempts", testId, open)
+	}
+
+	questions := questionsById(test)
+	assigned := make(map[uint]bool, len(questions))
+	sections := make([]entity.Section, len(data.Sections))
+	questionIds := make([][]uint, len(data.Sections))
+	for i, input := range data.Sections {
+		for _, questionId := range input.QuestionIDs {
+			if _, ok := questions[questionId]; !ok {
+				return nil, fmt.Errorf("SetSections: question %d is not in test %d", questionId, testId)
+			}
+			if assigned[questionId] {
+				return nil, fmt.Errorf("SetSections: question %d is in more than one section", questionId)
+			}
+			assigned[questionId] = true
+		}
+
+		sections[i] = entity.Section{
+			TestID:       test.ID,
+			Name:         input.Name,
+			Instructions: input.Instructions,
+			Position:     i,
+			TimeLimit:    input.TimeLimit,
+			AllowBack:    input.AllowBack,
+		}
+		questionIds[i] = input.QuestionIDs
+	}
+
+	if len(sections) > 0 && len(assigned) != len(questions) {
+		return nil, fmt.Errorf("SetSections: %d questions of test %d are not in a section", len(questions)-len(assigned), testId)
+	}
+
+	if err := s.sectionRepo.ReplaceSections(test.ID, sections, questionIds); err != nil {
+		return nil, fmt.Errorf("SetSections: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("SetSections: failed to invalidate test cache: %w", err)
+	}
+
+	response := make([]dtos.GetSectionResponse, len(sections))
+	for i, section := range sections {
+		response[i] = dtos.GetSectionResponse{
+			ID:           section.ID,
+			Name:         section.Name,
+			Instructions: section.Instructions,
+			TimeLimit:    section.TimeLimit,
+			AllowBack:    section.AllowBack,
+		}
+	}
+
+	return response, nil
+}
+
+// GetState shows which section an attempt is in and how much time is left.
+func (s *Section) GetState(login string, attemptId uint) (*dtos.SectionStateResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetState: failed get user by login: %w", err)
+	}
+
+	attempt, err := s.attemptRepo.GetAttemptById(attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("GetState: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(attempt.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("GetState: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("GetState: %w", err)
+	}
+
+	if attempt.UserID != user.ID && !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetState: attempt %d does not belong to user %s", attempt.ID, login)
+	}
+
+	if len(test.Sections) == 0 {
+		return nil, fmt.Errorf("GetState: test %d has no sections", test.ID)
+	}
+
+	return sectionState(test, attempt, time.Now()), nil
+}
+
+// EnterSection moves an attempt on to the next section, or back to an
+// earlier one that allows it. The answers given in the section left are
+// stored unless its time ran out, and a section without back navigation or
+// out of time is closed.
+func (s *Section) EnterSection(ctx context.Context, login string, attemptId uint, data *dtos.EnterSectionRequest) (*dtos.SectionStateResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("EnterSection: failed get user by login: %w", err)
+	}
+
+	found, err := s.attemptRepo.GetAttemptById(attemptId)
+	if err != nil {
+		return nil, fmt.Errorf("EnterSection: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(found.TestID)
+	if err != nil {
+		return nil, fmt.Errorf("EnterSection: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("EnterSection: %w", err)
+	}
+
+	now := time.Now()
+	if !canViewPrivateTest(role) && !isTestAvailable(test, now) {
+		return nil, fmt.Errorf("EnterSection: test is closed")
+	}
+
+	attempt, err := s.attempts.OpenAttempt(user, test, &attemptId, nil, now)
+	if err != nil {
+		return nil, fmt.Errorf("EnterSection: %w", err)
+	}
+
+	current := currentSection(test, attempt)
+	if current == nil {
+		return nil, fmt.Errorf("EnterSection: attempt %d is not in a section", attempt.ID)
+	}
+
+	from, to := -1, -1
+	for i, section := range test.Sections {
+		if section.ID == current.ID {
+			from = i
+		}
+		if section.ID == data.SectionID {
+			to = i
+		}
+	}
+
+	if to < 0 {
+		return nil, fmt.Errorf("EnterSection: section %d is not in test %d", data.SectionID, test.ID)
+	}
+	target := test.Sections[to]
+
+	switch {
+	case to == from:
+		return nil, fmt.Errorf("EnterSection: attempt %d is already in section %d", attempt.ID, target.ID)
+	case to > from+1:
+		return nil, fmt.Errorf("EnterSection: section %d is not the next one", target.ID)
+	case to < from && !target.AllowBack:
+		return nil, fmt.Errorf("EnterSection: section %d does not allow going back", target.ID)
+	}
+
+	entering := attemptSection(attempt, target.ID)
+	if entering.ClosedAt != nil {
+		return nil, fmt.Errorf("EnterSection: section %d is closed", target.ID)
+	}
+	if target.TimeLimit > 0 && entering.SpentSeconds >= target.TimeLimit {
+		return nil, fmt.Errorf("EnterSection: time of section %d is up", target.ID)
+	}
+
+	leaving := attemptSection(attempt, current.ID)
+	var answers []entity.AttemptAnswer
+	var questionIds []uint
+	if sectionInTime(*current, leaving, now) {
+		questions := sectionQuestions(test, current.ID)
+		given := newSubmittedAnswers(data.Test, data.TextAnswers, data.CodeAnswers, data.NumericAnswers)
+		answers = gradeQuestions(ctx, s.sandbox, questions, given, attempt, now)
+		for _, question := range questions {
+			questionIds = append(questionIds, question.ID)
+		}
+	}
+
+	leaving.SpentSeconds = spentSeconds(leaving, now)
+	leaving.EnteredAt = nil
+	if !current.AllowBack || (current.TimeLimit > 0 && leaving.SpentSeconds >= current.TimeLimit) {
+		leaving.ClosedAt = &now
+	}
+	entering.EnteredAt = &now
+
+	states := []entity.AttemptSection{leaving, entering}
+	if err := s.sectionRepo.MoveAttempt(attempt.ID, current.ID, target.ID, states, answers, questionIds); err != nil {
+		return nil, fmt.Errorf("EnterSection: %w", err)
+	}
+
+	attempt.CurrentSectionID = &target.ID
+	attempt.Sections = append(attempt.Sections, states...)
+
+	return sectionState(test, attempt, now), nil
+}
+
+func sectionState(test *entity.Test, attempt *entity.Attempt, now time.Time) *dtos.SectionStateResponse {
+	state := &dtos.SectionStateResponse{
+		AttemptID: attempt.ID,
+		Sections:  make([]dtos.AttemptSectionState, len(test.Sections)),
+	}
+
+	for i, section := range test.Sections {
+		progress := attemptSection(attempt, section.ID)
+		current := attempt.CurrentSectionID != nil && *attempt.CurrentSectionID == section.ID
+		state.Sections[i] = dtos.AttemptSectionState{
+			SectionID:    section.ID,
+			Name:         section.Name,
+			TimeLimit:    section.TimeLimit,
+			AllowBack:    section.AllowBack,
+			SpentSeconds: spentSeconds(progress, now),
+			Current:      current,
+			Closed:       progress.ClosedAt != nil,
+		}
+
+		if !current {
+			continue
+		}
+
+		state.SectionID = section.ID
+		state.Name = section.Name
+		state.Instructions = section.Instructions
+		if section.TimeLimit > 0 {
+			left := max(0, section.TimeLimit-state.Sections[i].SpentSeconds)
+			state.TimeLeft = &left
+		}
+	}
+
+	return state
+}
+
+// sectionScores breaks the answers of an attempt down by the sections of the
+// test. The score of a section is nil while an answer in it is not graded.
+func sectionScores(test *entity.Test, answers []entity.AttemptAnswer) []dtos.SectionScore {
+	if len(test.Sections) == 0 {
+		return nil
+	}
+
+	sectionOf := make(map[uint]uint, len(test.Questions))
+	for _, question := range test.Questions {
+		if question.SectionID != nil {
+			sectionOf[question.ID] = *question.SectionID
+		}
+	}
+
+	scores := make([]dtos.SectionScore, len(test.Sections))
+	for i, section := range test.Sections {
+		var inSection []entity.AttemptAnswer
+		for _, answer := range answers {
+			if sectionOf[answer.QuestionID] == section.ID {
+				inSection = append(inSection, answer)
+			}
+		}
+
+		score, _ := scoreAnswers(test, inSection)
+		scores[i] = dtos.SectionScore{
+			SectionID: section.ID,
+			Name:      section.Name,
+			Score:     score,
+		}
+	}
+
+	return scores
+}
+
+func currentSection(test *entity.Test, attempt *entity.Attempt) *entity.Section {
+	if attempt.CurrentSectionID == nil {
+		return nil
+	}
+	for i := range test.Sections {
+		if test.Sections[i].ID == *attempt.CurrentSectionID {
+			return &test.Sections[i]
+		}
+	}
+	return nil
+}
+
+func sectionQuestions(test *entity.Test, sectionId uint) []entity.Question {
+	var questions []entity.Question
+	for _, question := range test.Questions {
+		if question.SectionID != nil && *question.SectionID == sectionId {
+			questions = append(questions, question)
+		}
+	}
+	return questions
+}
+
+// attemptSection is the progress of an attempt in a section, new when the
+// attempt never entered it. The last state wins when there are several.
+func attemptSection(attempt *entity.Attempt, sectionId uint) entity.AttemptSection {
+	state := entity.AttemptSection{
+		AttemptID: attempt.ID,
+		SectionID: sectionId,
+	}
+	for _, progress := range attempt.Sections {
+		if progress.SectionID == sectionId {
+			state = progress
+		}
+	}
+	return state
+}
+
+func spentSeconds(state entity.AttemptSection, now time.Time) int {
+	if state.EnteredAt == nil {
+		return state.SpentSeconds
+	}
+	return state.SpentSeconds + int(now.Sub(*state.EnteredAt)/time.Second)
+}
+
+// sectionInTime tells whether answers to the section are still taken.
+func sectionInTime(section entity.Section, state entity.AttemptSection, now time.Time) bool {
+	if section.TimeLimit == 0 {
+		return true
+	}
+	limit := time.Duration(section.TimeLimit)*time.Second + constants.SECTION_GRACE_PERIOD
+	return time.Duration(spentSeconds(state, now))*time.Second <= limit
+}
@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"github.com/server/pkg/similarity"
+)
+
+type SimilarityRepoInterface interface {
+	GetUncheckedAnswers(limit int) ([]entity.AttemptAnswer, error)
+	GetQuestionAnswers(questionId uint) ([]entity.AttemptAnswer, error)
+	SaveFlags(flags []entity.SimilarityFlag, checkedIds []uint, checkedAt time.Time) error
+	GetFlagsByTestId(testId uint) ([]entity.SimilarityFlag, error)
+}
+
+// Similarity flags text answers to the same question that share too much
+// wording. Answers are compared with MinHash and candidates are confirmed
+// with the exact Jaccard similarity of their word shingles.
+type Similarity struct {
+	similarityRepo SimilarityRepoInterface
+	testRepo       GradingTestRepoInterface
+	userRepo       UserRepoInterfaceGetByLogin
+	access         TestAccessInterface
+	threshold      float64
+}
+
+func NewSimilarity(
+	similarityRepo SimilarityRepoInterface,
+	testRepo GradingTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	threshold float64,
+) *Similarity {
+	return &Similarity{
+		similarityRepo: similarityRepo,
+		testRepo:       testRepo,
+		userRepo:       userRepo,
+		access:         access,
+		threshold:      threshold,
+	}
+}
+
+type shingledAnswer struct {
+	answer    entity.AttemptAnswer
+	shingles  map[uint64]struct{}
+	signature []uint64
+}
+
+// DetectSimilarity compares new text answers with every other answer to the
+// same question. Answers of the same user are not compared.
+func (s *Similarity) DetectSimilarity(ctx context.Context) error {
+	unchecked, err := s.similarityRepo.GetUncheckedAnswers(constants.SIMILARITY_CHECK_BATCH)
+	if err != nil {
+		return fmt.Errorf("DetectSimilarity: %w", err)
+	}
+
+	byQuestion := make(map[uint][]entity.AttemptAnswer)
+	var order []uint
+	for _, answer := range unchecked {
+		if _, ok := byQuestion[answer.QuestionID]; !ok {
+			order = append(order, answer.QuestionID)
+		}
+		byQuestion[answer.QuestionID] = append(byQuestion[answer.QuestionID], answer)
+	}
+
+	for _, questionId := range order {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		answers, err := s.similarityRepo.GetQuestionAnswers(questionId)
+		if err != nil {
+			return fmt.Errorf("DetectSimilarity: %w", err)
+		}
+
+		shingled := make([]shingledAnswer, len(answers))
+		for i, answer := range answers {
+			shingles := similarity.Shingles(answer.Text, constants.SIMILARITY_SHINGLE_SIZE)
+			shingled[i] = shingledAnswer{
+				answer:    answer,
+				shingles:  shingles,
+				signature: similarity.Signature(shingles, constants.SIMILARITY_SIGNATURE_SIZE),
+			}
+		}
+
+		var flags []entity.SimilarityFlag
+		var checkedIds []uint
+		for _, answer := range byQuestion[questionId] {
+			checkedIds = append(checkedIds, answer.ID)
+			flags = append(flags, s.compare(answer, shingled)...)
+		}
+
+		if err := s.similarityRepo.SaveFlags(flags, checkedIds, time.Now()); err != nil {
+			return fmt.Errorf("DetectSimilarity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Similarity) compare(answer entity.AttemptAnswer, others []shingledAnswer) []entity.SimilarityFlag {
+	var current *shingledAnswer
+	for i := range others {
+		if others[i].answer.ID == answer.ID {
+			current = &others[i]
+			break
+		}
+	}
+	if current == nil {
+		return nil
+	}
+
+	var flags []entity.SimilarityFlag
+	for _, other := range others {
+		if other.answer.ID == answer.ID || other.answer.Attempt.UserID == answer.Attempt.UserID {
+			continue
+		}
+
+		// MinHash only picks candidates, the estimate can be a bit off.
+		if similarity.Estimate(current.signature, other.signature) < s.threshold-0.1 {
+			continue
+		}
+
+		score := similarity.Jaccard(current.shingles, other.shingles)
+		if score < s.threshold {
+			continue
+		}
+
+		first, second := answer.ID, other.answer.ID
+		if first > second {
+			first, second = second, first
+		}
+		flags = append(flags, entity.SimilarityFlag{
+			TestID:         answer.Attempt.TestID,
+			QuestionID:     answer.QuestionID,
+			FirstAnswerID:  first,
+			SecondAnswerID: second,
+			Similarity:     score,
+		})
+	}
+
+	return flags
+}
+
+// GetReport lists the flagged pairs of a test with the passages both answers
+// share, most similar first.
+func (s *Similarity) GetReport(login string, testId uint) ([]dtos.SimilarityPair, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetReport: user %s can not view results of test %d", login, testId)
+	}
+
+	flags, err := s.similarityRepo.GetFlagsByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	questions := questionsById(test)
+	report := make([]dtos.SimilarityPair, len(flags))
+	for i, flag := range flags {
+		firstOverlaps, secondOverlaps := similarity.Overlaps(flag.FirstAnswer.Text, flag.SecondAnswer.Text, constants.SIMILARITY_SHINGLE_SIZE)
+		report[i] = dtos.SimilarityPair{
+			QuestionID:   flag.QuestionID,
+			QuestionName: questions[flag.QuestionID].Name,
+			Similarity:   flag.Similarity,
+			First:        similarAnswer(flag.FirstAnswer, firstOverlaps),
+			Second:       similarAnswer(flag.SecondAnswer, secondOverlaps),
+		}
+	}
+
+	return report, nil
+}
+
+func similarAnswer(answer entity.AttemptAnswer, overlaps []similarity.Span) dtos.SimilarAnswer {
+	return dtos.SimilarAnswer{
+		AnswerID:  answer.ID,
+		AttemptID: answer.AttemptID,
+		Login:     answer.Attempt.User.Login,
+		Name:      answer.Attempt.User.Name,
+		Text:      answer.Text,
+		Overlaps:  overlaps,
+	}
+}
@@ -0,0 +1,166 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/pkg/constants"
+	"gorm.io/gorm"
+)
+
+type OrganizationMemberRepoInterface interface {
+	GetMember(organizationId uint, userId uint) (*entity.OrganizationMember, error)
+}
+
+type TestCollaboratorRepoInterface interface {
+	GetCollaborator(testId uint, userId uint) (*entity.TestCollaborator, error)
+}
+
+type TestGrantRepoInterface interface {
+	HasGrant(testId uint, userId uint) (bool, error)
+	IsInvited(testId uint, login string, email string) (bool, error)
+}
+
+type TestAssignmentCheckerInterface interface {
+	HasUserAssignment(userId uint, testId uint) (bool, error)
+}
+
+// TestAccess resolves what a user may do with a test. The effective role is the
+// highest of authorship, organization membership and the test's own ACL.
+type TestAccess struct {
+	memberRepo       OrganizationMemberRepoInterface
+	collaboratorRepo TestCollaboratorRepoInterface
+	grantRepo        TestGrantRepoInterface
+	assignmentRepo   TestAssignmentCheckerInterface
+}
+
+func NewTestAccess(
+	memberRepo OrganizationMemberRepoInterface,
+	collaboratorRepo TestCollaboratorRepoInterface,
+	grantRepo TestGrantRepoInterface,
+	assignmentRepo TestAssignmentCheckerInterface,
+) *TestAccess {
+	return &TestAccess{
+		memberRepo:       memberRepo,
+		collaboratorRepo: collaboratorRepo,
+		grantRepo:        grantRepo,
+		assignmentRepo:   assignmentRepo,
+	}
+}
+
+// Role returns one of OwnerRole, EditorRole, ViewerRole or PassingRole.
+func (s *TestAccess) Role(test *entity.Test, user *entity.User) (string, error) {
+	if test.OrganizationID == nil && test.UserID == user.ID {
+		return constants.OwnerRole, nil
+	}
+
+	role := constants.PassingRole
+
+	if test.OrganizationID != nil {
+		member, err := s.memberRepo.GetMember(*test.OrganizationID, user.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("Role: %w", err)
+		}
+		if err == nil {
+			role = higherTestRole(role, organizationTestRole(member.Role))
+		}
+	}
+
+	collaborator, err := s.collaboratorRepo.GetCollaborator(test.ID, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("Role: %w", err)
+	}
+	if err == nil {
+		role = higherTestRole(role, collaborator.Role)
+	}
+
+	return role, nil
+}
+
+// CanPass enforces the test's access mode for users with the passing role.
+// Tests assigned to one of the user's groups are always open to them. Guests
+// may pass only public tests that allow them.
+func (s *TestAccess) CanPass(test *entity.Test, user *entity.User, role string) (bool, error) {
+	if user.Role == constants.RoleGuest {
+		return test.AllowGuests && (test.AccessMode == "" || test.AccessMode == constants.AccessPublic), nil
+	}
+
+	if canViewPrivateTest(role) || test.AccessMode == "" || test.AccessMode == constants.AccessPublic {
+		return true, nil
+	}
+
+	if test.AccessMode == constants.AccessInvite {
+		email := ""
+		if user.EmailVerified {
+			email = user.Email
+		}
+		invited, err := s.grantRepo.IsInvited(test.ID, user.Login, email)
+		if err != nil {
+			return false, fmt.Errorf("CanPass: %w", err)
+		}
+		if invited {
+			return true, nil
+		}
+	}
+
+	granted, err := s.grantRepo.HasGrant(test.ID, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("CanPass: %w", err)
+	}
+	if granted {
+		return true, nil
+	}
+
+	assigned, err := s.assignmentRepo.HasUserAssignment(user.ID, test.ID)
+	if err != nil {
+		return false, fmt.Errorf("CanPass: %w", err)
+	}
+
+	return assigned, nil
+}
+
+func organizationTestRole(memberRole string) string {
+	switch memberRole {
+	case constants.OrgRoleOwner, constants.OrgRoleAdmin:
+		return constants.OwnerRole
+	case constants.OrgRoleEditor:
+		return constants.EditorRole
+	default:
+		return constants.ViewerRole
+	}
+}
+
+func higherTestRole(a string, b string) string {
+	if constants.TestRoleRank[b] > constants.TestRoleRank[a] {
+		return b
+	}
+	return a
+}
+
+func canManageTest(role string) bool {
+	return constants.TestRoleRank[role] >= constants.TestRoleRank[constants.OwnerRole]
+}
+
+func canEditTest(role string) bool {
+	return constants.TestRoleRank[role] >= constants.TestRoleRank[constants.EditorRole]
+}
+
+func canViewPrivateTest(role string) bool {
+	return constants.TestRoleRank[role] >= constants.TestRoleRank[constants.ViewerRole]
+}
+
+// isTestAvailable reports whether passing users may open and submit the test at now.
+func isTestAvailable(test *entity.Test, now time.Time) bool {
+	if !test.IsActive {
+		return false
+	}
+	if test.OpensAt != nil && now.Before(*test.OpensAt) {
+		return false
+	}
+	if test.ClosesAt != nil && !now.Before(*test.ClosesAt) {
+		return false
+	}
+	return true
+}
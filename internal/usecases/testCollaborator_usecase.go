@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"fmt"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+)
+
+type TestCollaboratorManagerRepoInterface interface {
+	TestCollaboratorRepoInterface
+	SaveCollaborator(collaborator *entity.TestCollaborator) error
+	GetCollaborators(testId uint) ([]entity.TestCollaborator, error)
+	DeleteCollaborator(testId uint, userId uint) error
+}
+
+type CollaboratorTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+}
+
+type TestCollaborator struct {
+	collaboratorRepo TestCollaboratorManagerRepoInterface
+	testRepo         CollaboratorTestRepoInterface
+	userRepo         UserRepoInterfaceGetByLogin
+	access           TestAccessInterface
+}
+
+func NewTestCollaborator(
+	collaboratorRepo TestCollaboratorManagerRepoInterface,
+	testRepo CollaboratorTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	memberRepo OrganizationMemberRepoInterface,
+	grantRepo TestGrantRepoInterface,
+	assignmentRepo TestAssignmentCheckerInterface,
+) *TestCollaborator {
+	return &TestCollaborator{
+		collaboratorRepo: collaboratorRepo,
+		testRepo:         testRepo,
+		userRepo:         userRepo,
+		access:           NewTestAccess(memberRepo, collaboratorRepo, grantRepo, assignmentRepo),
+	}
+}
+
+func (s *TestCollaborator) GetCollaborators(login string, testId uint) ([]entity.TestCollaborator, error) {
+	_, role, err := s.testWithRole(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetCollaborators: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("GetCollaborators: user %s has no access to test %d", login, testId)
+	}
+
+	collaborators, err := s.collaboratorRepo.GetCollaborators(testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetCollaborators: %w", err)
+	}
+
+	return collaborators, nil
+}
+
+func (s *TestCollaborator) AddCollaborator(login string, testId uint, data *dtos.AddCollaboratorRequest) error {
+	actor, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("AddCollaborator: failed get user by login: %w", err)
+	}
+
+	test, role, err := s.testWithRole(login, testId)
+	if err != nil {
+		return fmt.Errorf("AddCollaborator: %w", err)
+	}
+
+	if !canManageTest(role) {
+		return fmt.Errorf("AddCollaborator: user %s can not share test %d", login, testId)
+	}
+
+	collaborator, err := s.userRepo.GetUserByLogin(data.Login)
+	if err != nil {
+		return fmt.Errorf("AddCollaborator: failed get collaborator by login: %w", err)
+	}
+
+	if collaborator.ID == test.UserID {
+		return fmt.Errorf("AddCollaborator: author is already the owner of the test")
+	}
+
+	if err := s.collaboratorRepo.SaveCollaborator(&entity.TestCollaborator{
+		TestID:    test.ID,
+		UserID:    collaborator.ID,
+		Role:      data.Role,
+		AddedByID: actor.ID,
+	}); err != nil {
+		return fmt.Errorf("AddCollaborator: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCollaborator revokes access. Collaborators may remove themselves.
+func (s *TestCollaborator) RemoveCollaborator(login string, testId uint, userId uint) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("RemoveCollaborator: failed get user by login: %w", err)
+	}
+
+	_, role, err := s.testWithRole(login, testId)
+	if err != nil {
+		return fmt.Errorf("RemoveCollaborator: %w", err)
+	}
+
+	if user.ID != userId && !canManageTest(role) {
+		return fmt.Errorf("RemoveCollaborator: user %s can not change sharing of test %d", login, testId)
+	}
+
+	if err := s.collaboratorRepo.DeleteCollaborator(testId, userId); err != nil {
+		return fmt.Errorf("RemoveCollaborator: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TestCollaborator) testWithRole(login string, testId uint) (*entity.Test, string, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, "", fmt.Errorf("testWithRole: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, "", fmt.Errorf("testWithRole: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, "", fmt.Errorf("testWithRole: %w", err)
+	}
+
+	return test, role, nil
+}
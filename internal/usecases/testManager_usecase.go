@@ -30,15 +30,32 @@ type UserRepoInterfaceGetByLogin interface {
 	GetUserByLogin(login string) (*entity.User, error)
 }
 
+type TestAttemptRepoInterface interface {
+	GetAttemptById(id uint) (*entity.Attempt, error)
+}
+
+type TestAccessInterface interface {
+	Role(test *entity.Test, user *entity.User) (string, error)
+	CanPass(test *entity.Test, user *entity.User, role string) (bool, error)
+}
+
 type TestManager struct {
 	testRepo     TestManagerRepoInterface
 	userRepo     UserRepoInterfaceGetByLogin
+	memberRepo   OrganizationMemberRepoInterface
+	attemptRepo  TestAttemptRepoInterface
+	access       TestAccessInterface
 	cacheManager CacheManagerInterface
 }
 
 func NewTestManager(
 	testRepo TestManagerRepoInterface,
 	userRepo UserRepoInterfaceGetByLogin,
+	memberRepo OrganizationMemberRepoInterface,
+	collaboratorRepo TestCollaboratorRepoInterface,
+	grantRepo TestGrantRepoInterface,
+	assignmentRepo TestAssignmentCheckerInterface,
+	attemptRepo TestAttemptRepoInterface,
 	logger *zap.Logger,
 ) *TestManager {
 	rdb := redis.New()
@@ -46,6 +63,9 @@ func NewTestManager(
 	return &TestManager{
 		testRepo:     testRepo,
 		userRepo:     userRepo,
+		memberRepo:   memberRepo,
+		attemptRepo:  attemptRepo,
+		access:       NewTestAccess(memberRepo, collaboratorRepo, grantRepo, assignmentRepo),
 		cacheManager: cacheManager,
 	}
 }
@@ -76,45 +96,99 @@ func (s *TestManager) GetAllTests(userID uint, limit, offset int) ([]entity.Test
 	return tests, count, nil
 }
 
-func (s *TestManager) GetTestById(id uint, userLogin string) (*entity.Test, string, error) {
+// GetTestById returns the test for the user. With an attempt of the user the
+// numeric questions are rendered with the values drawn for that attempt.
+func (s *TestManager) GetTestById(id uint, userLogin string, attemptId *uint) (*entity.Test, string, error) {
 	cacheKey := fmt.Sprintf("test:%d", id)
-	var cachedTest entity.Test
 
 	user, err := s.userRepo.GetUserByLogin(userLogin)
 	if err != nil {
 		return nil, "", fmt.Errorf("GetTestById: failed get user by login: %w", err)
 	}
 
+	var test *entity.Test
+	var cachedTest entity.Test
 	if err := s.cacheManager.Get(cacheKey, &cachedTest); err == nil {
-		if cachedTest.UserID == user.ID {
-			return &cachedTest, constants.OwnerRole, nil
+		test = &cachedTest
+	} else {
+		test, err = s.testRepo.GetTestById(id)
+		if err != nil {
+			return nil, "", fmt.Errorf("GetTestById: failed get test by id: %w", err)
+		}
+
+		if err := s.cacheManager.Set(cacheKey, test, constants.CACHE_HEALTH_TIME); err != nil {
+			return nil, "", fmt.Errorf("GetTestById: failed set test to redis storage: %w", err)
 		}
-		return &cachedTest, constants.PassingRole, nil
 	}
 
-	test, err := s.testRepo.GetTestById(id)
+	role, err := s.access.Role(test, user)
 	if err != nil {
-		return nil, "", fmt.Errorf("GetTestById: failed get test by id: %w", err)
+		return nil, "", fmt.Errorf("GetTestById: %w", err)
 	}
 
-	if !test.IsActive && user.ID != test.UserID {
-		return nil, "", fmt.Errorf("GetTestById: test is private")
+	if !canViewPrivateTest(role) && !isTestAvailable(test, time.Now()) {
+		return nil, "", fmt.Errorf("GetTestById: test is private or closed")
 	}
 
-	if test.IsActive || user.ID == test.UserID {
-		if err := s.cacheManager.Set(cacheKey, test, constants.CACHE_HEALTH_TIME); err != nil {
-			return nil, "", fmt.Errorf("GetTestById: failed set test to redis storage: %w", err)
+	canPass, err := s.access.CanPass(test, user, role)
+	if err != nil {
+		return nil, "", fmt.Errorf("GetTestById: %w", err)
+	}
+	if !canPass {
+		return nil, "", fmt.Errorf("GetTestById: access mode %s does not allow user %s", test.AccessMode, userLogin)
+	}
+
+	var attempt *entity.Attempt
+	if attemptId != nil {
+		attempt, err = s.attemptRepo.GetAttemptById(*attemptId)
+		if err != nil {
+			return nil, "", fmt.Errorf("GetTestById: %w", err)
 		}
+
+		if attempt.UserID != user.ID || attempt.TestID != test.ID {
+			return nil, "", fmt.Errorf("GetTestById: attempt %d belongs to another user or test", attempt.ID)
+		}
+
+		test.Questions = renderQuestions(test.Questions, attempt)
 	}
 
-	if user.ID != test.UserID {
-		return test, constants.PassingRole, nil
+	// questions of an adaptive test are handed out one by one
+	if test.Adaptive && !canEditTest(role) {
+		test.Questions = nil
+	}
+
+	// and those of a test with sections only for the section the attempt is in
+	if len(test.Sections) > 0 && !canEditTest(role) {
+		var questions []entity.Question
+		if attempt != nil && attempt.SubmittedAt == nil && attempt.CurrentSectionID != nil {
+			questions = sectionQuestions(test, *attempt.CurrentSectionID)
+		}
+		test.Questions = questions
 	}
 
-	return test, constants.OwnerRole, nil
+	return test, role, nil
 }
 
 func (s *TestManager) CreateTest(data entity.Test) error {
+	if data.OrganizationID != nil {
+		member, err := s.memberRepo.GetMember(*data.OrganizationID, data.UserID)
+		if err != nil {
+			return fmt.Errorf("CreateTest: user is not an organization member: %w", err)
+		}
+		if constants.OrgRoleRank[member.Role] < constants.OrgRoleRank[constants.OrgRoleEditor] {
+			return fmt.Errorf("CreateTest: organization role %s can not create tests", member.Role)
+		}
+	}
+
+	for _, question := range data.Questions {
+		if question.Type != constants.QuestionNumeric {
+			continue
+		}
+		if err := validateNumericQuestion(question); err != nil {
+			return fmt.Errorf("CreateTest: question %s: %w", question.Name, err)
+		}
+	}
+
 	if err := s.testRepo.CreateTest(&data); err != nil {
 		return fmt.Errorf("CreateTest: failed to create test: %w", err)
 	}
@@ -132,22 +206,31 @@ func (s *TestManager) DeleteTest(id uint, login string) error {
 		return fmt.Errorf("DeleteTest: failed to get user by login: %w", err)
 	}
 
-	if err := s.deleteTestsFromCache(user.ID); err != nil {
-		return fmt.Errorf("DeleteTest: failed to invalidate cache: %w", err)
-	}
-
 	test, err := s.testRepo.GetTestById(id)
 	if err != nil {
 		return fmt.Errorf("DeleteTest: failed to get test by id: %w", err)
 	}
 
-	if test.UserID != user.ID {
-		return fmt.Errorf("DeleteTest: failed to delete test: %w", err)
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return fmt.Errorf("DeleteTest: %w", err)
+	}
+
+	if !canManageTest(role) {
+		return fmt.Errorf("DeleteTest: user %s can not delete test %d", login, id)
 	}
 
 	if err := s.testRepo.DeleteTest(id); err != nil {
 		return fmt.Errorf("DeleteTest: failed delete test by id: %w", err)
 	}
+
+	if err := s.deleteTestFromCache(id); err != nil {
+		return fmt.Errorf("DeleteTest: failed to invalidate cache: %w", err)
+	}
+
+	if err := s.deleteTestsFromCache(test.UserID); err != nil {
+		return fmt.Errorf("DeleteTest: failed to invalidate cache: %w", err)
+	}
 	return nil
 }
 
@@ -162,15 +245,20 @@ func (s *TestManager) ChangeActiveStatus(status bool, testId uint, userLogin str
 		return fmt.Errorf("ChangeActiveStatus: failed to get user by login:%w", err)
 	}
 
-	if test.UserID != user.ID {
-		return fmt.Errorf("ChangeActiveStatus: user is not author: %w", err)
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return fmt.Errorf("ChangeActiveStatus: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return fmt.Errorf("ChangeActiveStatus: user %s can not edit test %d", userLogin, testId)
 	}
 
 	if err := s.deleteTestFromCache(testId); err != nil {
 		return fmt.Errorf("ChangeActiveStatus: failed to delete test from cache: %w", err)
 	}
 
-	if err := s.deleteTestsFromCache(user.ID); err != nil {
+	if err := s.deleteTestsFromCache(test.UserID); err != nil {
 		return fmt.Errorf("ChangeActiveStatus: failed to delete tests from cache: %w", err)
 	}
 
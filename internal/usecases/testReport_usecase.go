@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+)
+
+type ReportAttemptRepoInterface interface {
+	GetSubmittedAttemptsByTestId(testId uint) ([]entity.Attempt, error)
+	GetTestAnswers(testId uint) ([]entity.AttemptAnswer, error)
+}
+
+// TestReport builds results exports and item analytics for the viewers of a
+// test. Rubric questions are broken down per criterion.
+type TestReport struct {
+	attemptRepo ReportAttemptRepoInterface
+	testRepo    GradingTestRepoInterface
+	userRepo    UserRepoInterfaceGetByLogin
+	access      TestAccessInterface
+}
+
+func NewTestReport(
+	attemptRepo ReportAttemptRepoInterface,
+	testRepo GradingTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+) *TestReport {
+	return &TestReport{
+		attemptRepo: attemptRepo,
+		testRepo:    testRepo,
+		userRepo:    userRepo,
+		access:      access,
+	}
+}
+
+// ExportResults returns one row per submitted attempt, headers first. Every
+// question gets a points column and every rubric criterion one more.
+func (s *TestReport) ExportResults(login string, testId uint) ([][]string, error) {
+	test, err := s.viewableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("ExportResults: %w", err)
+	}
+
+	attempts, err := s.attemptRepo.GetSubmittedAttemptsByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ExportResults: %w", err)
+	}
+
+	answers, err := s.attemptRepo.GetTestAnswers(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ExportResults: %w", err)
+	}
+
+	header := []string{"attempt_id", "login", "name", "submitted_at", "score", "passed", "band"}
+	for i, question := range test.Questions {
+		header = append(header, fmt.Sprintf("Q%d %s", i+1, question.Name))
+		for _, criterion := range question.Criteria {
+			header = append(header, fmt.Sprintf("Q%d %s: %s", i+1, question.Name, criterion.Name))
+		}
+	}
+
+	byAttempt := make(map[uint][]entity.AttemptAnswer)
+	for _, answer := range answers {
+		byAttempt[answer.AttemptID] = append(byAttempt[answer.AttemptID], answer)
+	}
+
+	rows := [][]string{header}
+	for _, attempt := range attempts {
+		row := []string{
+			strconv.FormatUint(uint64(attempt.ID), 10),
+			attempt.User.Login,
+			attempt.User.Name,
+			formatTime(attempt.SubmittedAt),
+			formatPoints(attempt.Score),
+			formatPassed(attempt.Passed),
+			attempt.Band,
+		}
+
+		points, criteria := questionPoints(byAttempt[attempt.ID])
+		for _, question := range test.Questions {
+			row = append(row, formatPoints(points[question.ID]))
+			for _, criterion := range question.Criteria {
+				row = append(row, formatPoints(criteria[criterion.ID]))
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ItemAnalytics shows how every question of the test was answered over all
+// submitted attempts. Ungraded text answers are not counted.
+func (s *TestReport) ItemAnalytics(login string, testId uint) ([]dtos.ItemAnalytics, error) {
+	test, err := s.viewableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("ItemAnalytics: %w", err)
+	}
+
+	answers, err := s.attemptRepo.GetTestAnswers(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ItemAnalytics: %w", err)
+	}
+
+	byAttempt := make(map[uint][]entity.AttemptAnswer)
+	for _, answer := range answers {
+		byAttempt[answer.AttemptID] = append(byAttempt[answer.AttemptID], answer)
+	}
+
+	items := make([]dtos.ItemAnalytics, len(test.Questions))
+	for i, question := range test.Questions {
+		item := dtos.ItemAnalytics{
+			QuestionID: question.ID,
+			Name:       question.Name,
+			Type:       question.Type,
+			MaxPoints:  question.MaxPoints,
+		}
+		if question.Type == constants.QuestionChoice {
+			item.MaxPoints = float64(len(question.Variants))
+		}
+
+		var total float64
+		correct := 0
+		criterionTotals := make(map[uint]float64)
+		criterionCounts := make(map[uint]int)
+		levelCounts := make(map[uint]int)
+		for _, attemptAnswers := range byAttempt {
+			points, criteria := questionPoints(attemptAnswers)
+			if points[question.ID] == nil {
+				continue
+			}
+
+			item.Answers++
+			total += *points[question.ID]
+			if isQuestionCorrect(question, attemptAnswers) {
+				correct++
+			}
+
+			for _, answer := range attemptAnswers {
+				if answer.QuestionID != question.ID {
+					continue
+				}
+				for _, score := range answer.CriterionScores {
+					if score.LevelID != nil {
+						levelCounts[*score.LevelID]++
+					}
+				}
+			}
+			for criterionId, value := range criteria {
+				criterionTotals[criterionId] += *value
+				criterionCounts[criterionId]++
+			}
+		}
+
+		if item.Answers > 0 {
+			item.AveragePoints = average(total, item.Answers)
+			if question.Type == constants.QuestionChoice || question.Type == constants.QuestionNumeric {
+				item.CorrectRate = average(float64(correct), item.Answers)
+			}
+		}
+
+		for _, criterion := range question.Criteria {
+			analytics := dtos.CriterionAnalytics{
+				CriterionID:   criterion.ID,
+				Name:          criterion.Name,
+				MaxPoints:     criterionMaxPoints(criterion),
+				AveragePoints: average(criterionTotals[criterion.ID], criterionCounts[criterion.ID]),
+				Levels:        make([]dtos.LevelCount, len(criterion.Levels)),
+			}
+			for j, level := range criterion.Levels {
+				analytics.Levels[j] = dtos.LevelCount{
+					LevelID: level.ID,
+					Name:    level.Name,
+					Count:   levelCounts[level.ID],
+				}
+			}
+			item.Criteria = append(item.Criteria, analytics)
+		}
+
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+func (s *TestReport) viewableTest(login string, testId uint) (*entity.Test, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("viewableTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("viewableTest: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("viewableTest: %w", err)
+	}
+
+	if !canViewPrivateTest(role) {
+		return nil, fmt.Errorf("viewableTest: user %s can not view results of test %d", login, testId)
+	}
+
+	return test, nil
+}
+
+// questionPoints sums the points of the answers of one attempt per question
+// and per rubric criterion. Questions with an ungraded answer are left out.
+func questionPoints(answers []entity.AttemptAnswer) (map[uint]*float64, map[uint]*float64) {
+	points := make(map[uint]*float64)
+	criteria := make(map[uint]*float64)
+	ungraded := make(map[uint]bool)
+	for _, answer := range answers {
+		if answer.Points == nil {
+			ungraded[answer.QuestionID] = true
+			continue
+		}
+
+		if points[answer.QuestionID] == nil {
+			points[answer.QuestionID] = new(float64)
+		}
+		*points[answer.QuestionID] += *answer.Points
+
+		for _, score := range answer.CriterionScores {
+			value := score.Points
+			criteria[score.CriterionID] = &value
+		}
+	}
+
+	for questionId := range ungraded {
+		delete(points, questionId)
+	}
+
+	return points, criteria
+}
+
+func isQuestionCorrect(question entity.Question, answers []entity.AttemptAnswer) bool {
+	answered := false
+	for _, answer := range answers {
+		if answer.QuestionID != question.ID {
+			continue
+		}
+		answered = true
+		if !answer.Correct {
+			return false
+		}
+	}
+	return answered
+}
+
+func average(total float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	value := total / float64(count)
+	return &value
+}
+
+func formatPoints(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+func formatPassed(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
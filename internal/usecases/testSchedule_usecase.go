@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	"gorm.io/gorm"
+)
+
+type ScheduledChangeRepoInterface interface {
+	CreateScheduledChange(change *entity.ScheduledChange) error
+	GetPendingChangesByTestId(testId uint) ([]entity.ScheduledChange, error)
+	GetDueChanges(now time.Time, limit int) ([]entity.ScheduledChange, error)
+	ApplyChange(id uint, testId uint, active bool, appliedAt time.Time) (bool, error)
+	DeleteScheduledChange(id uint, testId uint) error
+}
+
+type ScheduleTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	UpdateWindow(testId uint, opensAt *time.Time, closesAt *time.Time) error
+}
+
+type TestSchedule struct {
+	changeRepo   ScheduledChangeRepoInterface
+	testRepo     ScheduleTestRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	access       TestAccessInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewTestSchedule(
+	changeRepo ScheduledChangeRepoInterface,
+	testRepo ScheduleTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+) *TestSchedule {
+	return &TestSchedule{
+		changeRepo:   changeRepo,
+		testRepo:     testRepo,
+		userRepo:     userRepo,
+		access:       access,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *TestSchedule) UpdateWindow(login string, testId uint, data *dtos.UpdateTestWindowRequest) error {
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("UpdateWindow: %w", err)
+	}
+
+	if data.OpensAt != nil && data.ClosesAt != nil && !data.ClosesAt.After(*data.OpensAt) {
+		return fmt.Errorf("UpdateWindow: closing time must be after opening time")
+	}
+
+	if err := s.testRepo.UpdateWindow(test.ID, data.OpensAt, data.ClosesAt); err != nil {
+		return fmt.Errorf("UpdateWindow: %w", err)
+	}
+
+	if err := s.invalidateTest(test); err != nil {
+		return fmt.Errorf("UpdateWindow: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TestSchedule) ScheduleChange(login string, testId uint, data *dtos.ScheduleChangeRequest) (*entity.ScheduledChange, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("ScheduleChange: failed get user by login: %w", err)
+	}
+
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("ScheduleChange: %w", err)
+	}
+
+	if !data.RunAt.After(time.Now()) {
+		return nil, fmt.Errorf("ScheduleChange: run time must be in the future")
+	}
+
+	change := &entity.ScheduledChange{
+		TestID:      test.ID,
+		Action:      data.Action,
+		RunAt:       data.RunAt,
+		CreatedByID: user.ID,
+	}
+	if err := s.changeRepo.CreateScheduledChange(change); err != nil {
+		return nil, fmt.Errorf("ScheduleChange: %w", err)
+	}
+
+	return change, nil
+}
+
+func (s *TestSchedule) GetScheduledChanges(login string, testId uint) ([]entity.ScheduledChange, error) {
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetScheduledChanges: %w", err)
+	}
+
+	changes, err := s.changeRepo.GetPendingChangesByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetScheduledChanges: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (s *TestSchedule) CancelScheduledChange(login string, testId uint, changeId uint) error {
+	test, err := s.editableTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("CancelScheduledChange: %w", err)
+	}
+
+	if err := s.changeRepo.DeleteScheduledChange(changeId, test.ID); err != nil {
+		return fmt.Errorf("CancelScheduledChange: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyDueChanges is run by the scheduler and publishes or unpublishes tests
+// whose scheduled time has come.
+func (s *TestSchedule) ApplyDueChanges(ctx context.Context) error {
+	now := time.Now()
+	changes, err := s.changeRepo.GetDueChanges(now, constants.SCHEDULED_CHANGES_BATCH)
+	if err != nil {
+		return fmt.Errorf("ApplyDueChanges: %w", err)
+	}
+
+	for _, change := range changes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		applied, err := s.changeRepo.ApplyChange(change.ID, change.TestID, change.Action == constants.SchedulePublish, now)
+		if err != nil {
+			return fmt.Errorf("ApplyDueChanges: %w", err)
+		}
+		if !applied {
+			continue
+		}
+
+		test, err := s.testRepo.GetTestById(change.TestID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("ApplyDueChanges: %w", err)
+		}
+
+		if err := s.invalidateTest(test); err != nil {
+			return fmt.Errorf("ApplyDueChanges: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *TestSchedule) editableTest(login string, testId uint) (*entity.Test, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, fmt.Errorf("editableTest: user %s can not edit test %d", login, testId)
+	}
+
+	return test, nil
+}
+
+func (s *TestSchedule) invalidateTest(test *entity.Test) error {
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return fmt.Errorf("invalidateTest: failed delete test from cache: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("tests:user:%d:*", test.UserID)); err != nil {
+		return fmt.Errorf("invalidateTest: failed delete tests from cache: %w", err)
+	}
+
+	return nil
+}
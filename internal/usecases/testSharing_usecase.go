@@ -0,0 +1,368 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/server/configs"
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type TestSharingRepoInterface interface {
+	GetInvitees(testId uint) ([]entity.TestInvitee, error)
+	ReplaceInvitees(testId uint, invitees []entity.TestInvitee) error
+	CreateInviteLink(link *entity.TestInviteLink) error
+	GetInviteLinkById(id uint) (*entity.TestInviteLink, error)
+	GetInviteLinksByTestId(testId uint) ([]entity.TestInviteLink, error)
+	UseInviteLink(id uint, now time.Time) error
+	RevokeInviteLink(id uint, testId uint, revokedAt time.Time) error
+	SaveGrant(grant *entity.TestAccessGrant) error
+	HasGrant(testId uint, userId uint) (bool, error)
+	DeleteGrants(testId uint) error
+}
+
+type SharingTestRepoInterface interface {
+	GetTestById(id uint) (*entity.Test, error)
+	GetTestBySlug(slug string) (*entity.Test, error)
+	UpdateAccess(testId uint, mode string, slug *string, accessCodeHash string) error
+}
+
+type TestSharing struct {
+	sharingRepo  TestSharingRepoInterface
+	testRepo     SharingTestRepoInterface
+	userRepo     UserRepoInterfaceGetByLogin
+	access       TestAccessInterface
+	cacheManager CacheManagerV2Interface
+	config       *configs.Config
+}
+
+func NewTestSharing(
+	sharingRepo TestSharingRepoInterface,
+	testRepo SharingTestRepoInterface,
+	userRepo UserRepoInterfaceGetByLogin,
+	access TestAccessInterface,
+	cacheManager CacheManagerV2Interface,
+	config *configs.Config,
+) *TestSharing {
+	return &TestSharing{
+		sharingRepo:  sharingRepo,
+		testRepo:     testRepo,
+		userRepo:     userRepo,
+		access:       access,
+		cacheManager: cacheManager,
+		config:       config,
+	}
+}
+
+func (s *TestSharing) GetAccess(login string, testId uint) (*dtos.TestAccessResponse, error) {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccess: %w", err)
+	}
+
+	response := dtos.ToTestAccessResponse(test)
+	return &response, nil
+}
+
+// UpdateAccess switches the access mode of a test. Any change of the mode or
+// the access code revokes access that was granted before.
+func (s *TestSharing) UpdateAccess(login string, testId uint, data *dtos.UpdateTestAccessRequest) (*dtos.TestAccessResponse, error) {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateAccess: %w", err)
+	}
+
+	slug := test.Slug
+	codeHash := test.AccessCodeHash
+
+	switch data.Mode {
+	case constants.AccessLink:
+		if slug == nil {
+			generated, err := securetoken.Generate(12)
+			if err != nil {
+				return nil, fmt.Errorf("UpdateAccess: failed generate slug: %w", err)
+			}
+			slug = &generated
+		}
+	case constants.AccessCode:
+		if data.AccessCode == "" && codeHash == "" {
+			return nil, fmt.Errorf("UpdateAccess: access code is required")
+		}
+	}
+
+	if data.AccessCode != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(data.AccessCode), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("UpdateAccess: failed to hash access code: %w", err)
+		}
+		codeHash = string(hash)
+	}
+
+	if err := s.testRepo.UpdateAccess(test.ID, data.Mode, slug, codeHash); err != nil {
+		return nil, fmt.Errorf("UpdateAccess: %w", err)
+	}
+
+	if data.Mode != test.AccessMode || data.AccessCode != "" {
+		if err := s.sharingRepo.DeleteGrants(test.ID); err != nil {
+			return nil, fmt.Errorf("UpdateAccess: %w", err)
+		}
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("test:%d", test.ID)); err != nil {
+		return nil, fmt.Errorf("UpdateAccess: failed delete test from cache: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("tests:user:%d:*", test.UserID)); err != nil {
+		return nil, fmt.Errorf("UpdateAccess: failed delete tests from cache: %w", err)
+	}
+
+	test.AccessMode = data.Mode
+	test.Slug = slug
+	test.AccessCodeHash = codeHash
+	response := dtos.ToTestAccessResponse(test)
+	return &response, nil
+}
+
+func (s *TestSharing) GetInvitees(login string, testId uint) ([]entity.TestInvitee, error) {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvitees: %w", err)
+	}
+
+	invitees, err := s.sharingRepo.GetInvitees(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvitees: %w", err)
+	}
+
+	return invitees, nil
+}
+
+// SetInvitees replaces the invite list. Entries with "@" are treated as emails,
+// everything else as logins.
+func (s *TestSharing) SetInvitees(login string, testId uint, data *dtos.UpdateTestInviteesRequest) ([]entity.TestInvitee, error) {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("SetInvitees: %w", err)
+	}
+
+	seen := make(map[string]bool, len(data.Invitees))
+	invitees := make([]entity.TestInvitee, 0, len(data.Invitees))
+	for _, entry := range data.Invitees {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		invitee := entity.TestInvitee{TestID: test.ID}
+		if strings.Contains(entry, "@") {
+			invitee.Email = strings.ToLower(entry)
+		} else {
+			invitee.Login = entry
+		}
+		invitees = append(invitees, invitee)
+	}
+
+	if err := s.sharingRepo.ReplaceInvitees(test.ID, invitees); err != nil {
+		return nil, fmt.Errorf("SetInvitees: %w", err)
+	}
+
+	return invitees, nil
+}
+
+func (s *TestSharing) CreateInviteLink(login string, testId uint, data *dtos.CreateTestInviteLinkRequest) (*dtos.TestInviteLinkResponse, error) {
+	test, user, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("CreateInviteLink: %w", err)
+	}
+
+	link := &entity.TestInviteLink{
+		TestID:      test.ID,
+		CreatedByID: user.ID,
+		MaxUses:     data.MaxUses,
+	}
+	if data.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(data.ExpiresInHours) * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := s.sharingRepo.CreateInviteLink(link); err != nil {
+		return nil, fmt.Errorf("CreateInviteLink: %w", err)
+	}
+
+	token := fmt.Sprintf("%d.%s", link.ID, securetoken.Sign(s.config.SECRET, inviteLinkPayload(link)))
+	response := dtos.ToTestInviteLinkResponse(link)
+	response.URL = fmt.Sprintf("%s/tests/invite?token=%s", s.config.CLIENT_URL, url.QueryEscape(token))
+	return &response, nil
+}
+
+func (s *TestSharing) GetInviteLinks(login string, testId uint) ([]dtos.TestInviteLinkResponse, error) {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return nil, fmt.Errorf("GetInviteLinks: %w", err)
+	}
+
+	links, err := s.sharingRepo.GetInviteLinksByTestId(test.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetInviteLinks: %w", err)
+	}
+
+	result := make([]dtos.TestInviteLinkResponse, len(links))
+	for i := range links {
+		result[i] = dtos.ToTestInviteLinkResponse(&links[i])
+	}
+
+	return result, nil
+}
+
+func (s *TestSharing) RevokeInviteLink(login string, testId uint, linkId uint) error {
+	test, _, err := s.editableTest(login, testId)
+	if err != nil {
+		return fmt.Errorf("RevokeInviteLink: %w", err)
+	}
+
+	if err := s.sharingRepo.RevokeInviteLink(linkId, test.ID, time.Now()); err != nil {
+		return fmt.Errorf("RevokeInviteLink: %w", err)
+	}
+
+	return nil
+}
+
+// RedeemInviteLink checks the link signature and grants the user access to the
+// test. Users who already have access do not use up the link.
+func (s *TestSharing) RedeemInviteLink(login string, token string) (uint, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: failed get user by login: %w", err)
+	}
+
+	rawId, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, fmt.Errorf("RedeemInviteLink: malformed token")
+	}
+
+	linkId, err := strconv.ParseUint(rawId, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: malformed token: %w", err)
+	}
+
+	link, err := s.sharingRepo.GetInviteLinkById(uint(linkId))
+	if err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: %w", err)
+	}
+
+	if !securetoken.Verify(s.config.SECRET, inviteLinkPayload(link), signature) {
+		return 0, fmt.Errorf("RedeemInviteLink: invalid signature")
+	}
+
+	granted, err := s.sharingRepo.HasGrant(link.TestID, user.ID)
+	if err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: %w", err)
+	}
+	if granted {
+		return link.TestID, nil
+	}
+
+	if err := s.sharingRepo.UseInviteLink(link.ID, time.Now()); err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: link is revoked, expired or used up: %w", err)
+	}
+
+	if err := s.grant(link.TestID, user.ID, constants.GrantSourceInviteLink); err != nil {
+		return 0, fmt.Errorf("RedeemInviteLink: %w", err)
+	}
+
+	return link.TestID, nil
+}
+
+func (s *TestSharing) OpenBySlug(login string, slug string) (uint, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return 0, fmt.Errorf("OpenBySlug: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestBySlug(slug)
+	if err != nil {
+		return 0, fmt.Errorf("OpenBySlug: %w", err)
+	}
+
+	if test.AccessMode != constants.AccessLink {
+		return 0, fmt.Errorf("OpenBySlug: test %d is not shared by link", test.ID)
+	}
+
+	if err := s.grant(test.ID, user.ID, constants.GrantSourceLink); err != nil {
+		return 0, fmt.Errorf("OpenBySlug: %w", err)
+	}
+
+	return test.ID, nil
+}
+
+func (s *TestSharing) Unlock(login string, testId uint, code string) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("Unlock: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return fmt.Errorf("Unlock: %w", err)
+	}
+
+	if test.AccessMode != constants.AccessCode || test.AccessCodeHash == "" {
+		return fmt.Errorf("Unlock: test %d is not protected by access code", test.ID)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(test.AccessCodeHash), []byte(code)); err != nil {
+		return fmt.Errorf("Unlock: invalid access code: %w", err)
+	}
+
+	if err := s.grant(test.ID, user.ID, constants.GrantSourceCode); err != nil {
+		return fmt.Errorf("Unlock: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TestSharing) grant(testId uint, userId uint, source string) error {
+	if err := s.sharingRepo.SaveGrant(&entity.TestAccessGrant{
+		TestID: testId,
+		UserID: userId,
+		Source: source,
+	}); err != nil {
+		return fmt.Errorf("grant: %w", err)
+	}
+	return nil
+}
+
+func (s *TestSharing) editableTest(login string, testId uint) (*entity.Test, *entity.User, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, nil, fmt.Errorf("editableTest: failed get user by login: %w", err)
+	}
+
+	test, err := s.testRepo.GetTestById(testId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	role, err := s.access.Role(test, user)
+	if err != nil {
+		return nil, nil, fmt.Errorf("editableTest: %w", err)
+	}
+
+	if !canEditTest(role) {
+		return nil, nil, fmt.Errorf("editableTest: user %s can not edit test %d", login, testId)
+	}
+
+	return test, user, nil
+}
+
+func inviteLinkPayload(link *entity.TestInviteLink) string {
+	return fmt.Sprintf("test-invite:%d:%d", link.ID, link.TestID)
+}
@@ -0,0 +1,203 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
+	securetoken "github.com/server/pkg/secureToken"
+	"github.com/server/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type TwoFactorUserRepoInterface interface {
+	GetUserByLogin(login string) (*entity.User, error)
+	SetTOTPSecret(userId uint, secret string) error
+	SetTOTPEnabled(userId uint, enabled bool) error
+}
+
+type SecondFactorRepoInterface interface {
+	ReplaceRecoveryCodes(userId uint, codeHashes []string) error
+	UseRecoveryCode(userId uint, codeHash string, usedAt time.Time) error
+	DeleteRecoveryCodes(userId uint) error
+	AcceptTOTPStep(userId uint, step int64) error
+	GetTOTPLock(userId uint) (*time.Time, error)
+	RecordTOTPFailure(userId uint, maxFailures int, lockUntil time.Time) error
+	ResetTOTPFailures(userId uint) error
+}
+
+type TwoFactor struct {
+	userRepo     TwoFactorUserRepoInterface
+	recoveryRepo SecondFactorRepoInterface
+	cacheManager CacheManagerV2Interface
+}
+
+func NewTwoFactor(
+	userRepo TwoFactorUserRepoInterface,
+	recoveryRepo SecondFactorRepoInterface,
+	cacheManager CacheManagerV2Interface,
+) *TwoFactor {
+	return &TwoFactor{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		cacheManager: cacheManager,
+	}
+}
+
+func (s *TwoFactor) Enroll(login string) (*dtos.TwoFactorEnrollResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("Enroll: failed get user by login: %w", err)
+	}
+
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("Enroll: two factor authentication already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("Enroll: %w", err)
+	}
+
+	if err := s.userRepo.SetTOTPSecret(user.ID, secret); err != nil {
+		return nil, fmt.Errorf("Enroll: %w", err)
+	}
+
+	return &dtos.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(constants.TOTP_ISSUER, user.Login, secret),
+	}, nil
+}
+
+func (s *TwoFactor) Confirm(login string, code string) (*dtos.TwoFactorConfirmResponse, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("Confirm: failed get user by login: %w", err)
+	}
+
+	if user.TOTPEnabled || user.TOTPSecret == "" {
+		return nil, fmt.Errorf("Confirm: two factor enrollment is not started")
+	}
+
+	step, ok := totp.ValidateStep(code, user.TOTPSecret, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("Confirm: invalid totp code")
+	}
+
+	if err := s.recoveryRepo.AcceptTOTPStep(user.ID, step); err != nil {
+		return nil, fmt.Errorf("Confirm: %w", err)
+	}
+
+	codes := make([]string, constants.RECOVERY_CODES_COUNT)
+	hashes := make([]string, constants.RECOVERY_CODES_COUNT)
+	for i := range codes {
+		code, err := securetoken.Generate(5)
+		if err != nil {
+			return nil, fmt.Errorf("Confirm: failed generate recovery code: %w", err)
+		}
+		codes[i] = code
+		hashes[i] = securetoken.Hash(code)
+	}
+
+	if err := s.recoveryRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("Confirm: %w", err)
+	}
+
+	if err := s.userRepo.SetTOTPEnabled(user.ID, true); err != nil {
+		return nil, fmt.Errorf("Confirm: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", login)); err != nil {
+		return nil, fmt.Errorf("Confirm: failed delete user from cache: %w", err)
+	}
+
+	return &dtos.TwoFactorConfirmResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *TwoFactor) Disable(login string, data *dtos.TwoFactorDisableRequest) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("Disable: failed get user by login: %w", err)
+	}
+
+	if !user.TOTPEnabled {
+		return fmt.Errorf("Disable: two factor authentication is not enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(data.Password)); err != nil {
+		return fmt.Errorf("Disable: invalid password: %w", err)
+	}
+
+	if err := verifySecondFactor(user, data.Code, data.RecoveryCode, s.recoveryRepo); err != nil {
+		return fmt.Errorf("Disable: %w", err)
+	}
+
+	if err := s.userRepo.SetTOTPEnabled(user.ID, false); err != nil {
+		return fmt.Errorf("Disable: %w", err)
+	}
+
+	if err := s.recoveryRepo.DeleteRecoveryCodes(user.ID); err != nil {
+		return fmt.Errorf("Disable: %w", err)
+	}
+
+	if err := s.cacheManager.Delete(fmt.Sprintf("user:login:%s", login)); err != nil {
+		return fmt.Errorf("Disable: failed delete user from cache: %w", err)
+	}
+
+	return nil
+}
+
+// verifySecondFactor checks a TOTP code or a recovery code of the user. A
+// TOTP code is accepted once, and too many wrong codes in a row lock the
+// second factor for a while.
+func verifySecondFactor(user *entity.User, code string, recoveryCode string, recoveryRepo SecondFactorRepoInterface) error {
+	now := time.Now()
+	lockUntil, err := recoveryRepo.GetTOTPLock(user.ID)
+	if err != nil {
+		return fmt.Errorf("verifySecondFactor: %w", err)
+	}
+	if lockUntil != nil && now.Before(*lockUntil) {
+		return fmt.Errorf("verifySecondFactor: second factor is locked until %s", lockUntil.Format(time.RFC3339))
+	}
+
+	if code == "" && recoveryCode == "" {
+		return fmt.Errorf("verifySecondFactor: code or recovery code is required")
+	}
+
+	if err := checkSecondFactor(user, code, recoveryCode, recoveryRepo, now); err != nil {
+		if err := recoveryRepo.RecordTOTPFailure(user.ID, constants.TWO_FACTOR_MAX_FAILURES, now.Add(constants.TWO_FACTOR_LOCKOUT)); err != nil {
+			return fmt.Errorf("verifySecondFactor: %w", err)
+		}
+		return fmt.Errorf("verifySecondFactor: %w", err)
+	}
+
+	if err := recoveryRepo.ResetTOTPFailures(user.ID); err != nil {
+		return fmt.Errorf("verifySecondFactor: %w", err)
+	}
+
+	return nil
+}
+
+func checkSecondFactor(user *entity.User, code string, recoveryCode string, recoveryRepo SecondFactorRepoInterface, now time.Time) error {
+	if code != "" {
+		step, ok := totp.ValidateStep(code, user.TOTPSecret, now)
+		if !ok {
+			return fmt.Errorf("checkSecondFactor: invalid totp code")
+		}
+		if err := recoveryRepo.AcceptTOTPStep(user.ID, step); err != nil {
+			return fmt.Errorf("checkSecondFactor: %w", err)
+		}
+		return nil
+	}
+
+	hash := securetoken.Hash(strings.ToLower(strings.TrimSpace(recoveryCode)))
+	if err := recoveryRepo.UseRecoveryCode(user.ID, hash, now); err != nil {
+		return fmt.Errorf("checkSecondFactor: %w", err)
+	}
+	return nil
+}
@@ -22,20 +22,35 @@ type CacheManagerV2Interface interface {
 type UserRepoInterfaceReaderAndWriter interface {
 	GetUserByLogin(login string) (*entity.User, error)
 	UpdateUser(user *dtos.UpdateUserRequest) error
-	DeleteRefreshToken(login string) error
+}
+
+type SessionRepoReaderAndWriterInterface interface {
+	GetSessionsByUserId(userId uint) ([]entity.Session, error)
+	DeleteSession(sessionId uint, userId uint) error
+	DeleteSessionsByUserId(userId uint) error
+}
+
+type AuditLogWriterInterface interface {
+	CreateAuditLog(entry *entity.AuditLog) error
 }
 
 type User struct {
 	userRepo     UserRepoInterfaceReaderAndWriter
+	sessionRepo  SessionRepoReaderAndWriterInterface
+	auditRepo    AuditLogWriterInterface
 	cacheManager CacheManagerV2Interface
 }
 
 func NewUser(
 	userRepo UserRepoInterfaceReaderAndWriter,
+	sessionRepo SessionRepoReaderAndWriterInterface,
+	auditRepo AuditLogWriterInterface,
 	cacheManager CacheManagerV2Interface,
 ) *User {
 	return &User{
 		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		auditRepo:    auditRepo,
 		cacheManager: cacheManager,
 	}
 }
@@ -81,10 +96,130 @@ func (s *User) Logout(w http.ResponseWriter, r *http.Request, logger *zap.Logger
 	if err != nil {
 		return fmt.Errorf("Logout: failed extract user login from token: %w", err)
 	}
-	if err := s.userRepo.DeleteRefreshToken(login); err != nil {
-		return fmt.Errorf("Logout: failed delete refresh token: %w", err)
+	sessionID, err := jwt.ExtractSessionFromToken(r)
+	if err != nil {
+		return fmt.Errorf("Logout: failed extract session from token: %w", err)
+	}
+
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("Logout: failed get user by login: %w", err)
+	}
+
+	closed, err := s.sessionsById(user.ID, sessionID)
+	if err != nil {
+		return fmt.Errorf("Logout: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSession(sessionID, user.ID); err != nil {
+		return fmt.Errorf("Logout: failed delete session: %w", err)
+	}
+
+	if err := s.auditImpersonationEnd(user, closed, r); err != nil {
+		return fmt.Errorf("Logout: %w", err)
+	}
+
+	cookies.Delete("token", w)
+	return nil
+}
+
+func (s *User) LogoutEverywhere(w http.ResponseWriter, r *http.Request, logger *zap.Logger) error {
+	cookies := cookiesmanager.New(r, logger)
+	login, err := jwt.NewJwt(logger).ExtractUserFromToken(r)
+	if err != nil {
+		return fmt.Errorf("LogoutEverywhere: failed extract user login from token: %w", err)
+	}
+
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("LogoutEverywhere: failed get user by login: %w", err)
+	}
+
+	closed, err := s.sessionRepo.GetSessionsByUserId(user.ID)
+	if err != nil {
+		return fmt.Errorf("LogoutEverywhere: failed get user sessions: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSessionsByUserId(user.ID); err != nil {
+		return fmt.Errorf("LogoutEverywhere: failed delete sessions: %w", err)
+	}
+
+	if err := s.auditImpersonationEnd(user, closed, r); err != nil {
+		return fmt.Errorf("LogoutEverywhere: %w", err)
 	}
 
 	cookies.Delete("token", w)
 	return nil
 }
+
+func (s *User) GetSessions(login string) ([]entity.Session, error) {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("GetSessions: failed get user by login: %w", err)
+	}
+
+	sessions, err := s.sessionRepo.GetSessionsByUserId(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetSessions: failed get user sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (s *User) RevokeSession(sessionID uint, login string, r *http.Request) error {
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return fmt.Errorf("RevokeSession: failed get user by login: %w", err)
+	}
+
+	closed, err := s.sessionsById(user.ID, sessionID)
+	if err != nil {
+		return fmt.Errorf("RevokeSession: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteSession(sessionID, user.ID); err != nil {
+		return fmt.Errorf("RevokeSession: failed delete session: %w", err)
+	}
+
+	if err := s.auditImpersonationEnd(user, closed, r); err != nil {
+		return fmt.Errorf("RevokeSession: %w", err)
+	}
+
+	return nil
+}
+
+func (s *User) sessionsById(userId uint, sessionID uint) ([]entity.Session, error) {
+	sessions, err := s.sessionRepo.GetSessionsByUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("sessionsById: failed get user sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return []entity.Session{session}, nil
+		}
+	}
+	return nil, nil
+}
+
+// auditImpersonationEnd writes the end of every impersonated session among
+// the closed ones to the audit log, in the name of the admin who opened it.
+func (s *User) auditImpersonationEnd(user *entity.User, closed []entity.Session, r *http.Request) error {
+	for _, session := range closed {
+		if session.ImpersonatorID == nil {
+			continue
+		}
+
+		if err := s.auditRepo.CreateAuditLog(&entity.AuditLog{
+			ActorID:    *session.ImpersonatorID,
+			Action:     constants.AuditImpersonationEnded,
+			TargetType: "user",
+			TargetID:   user.ID,
+			IP:         ClientIP(r),
+			Details:    fmt.Sprintf("session %d", session.ID),
+		}); err != nil {
+			return fmt.Errorf("auditImpersonationEnd: %w", err)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,14 @@
 package usecases
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/server/entity"
+	"github.com/server/internal/dtos"
+	"github.com/server/pkg/constants"
 )
 
 type TestManagerRepoV2Interface interface {
@@ -11,37 +16,228 @@ type TestManagerRepoV2Interface interface {
 	IncrementCountUserPast(testId uint, count int) error
 }
 
+type AttemptOpenerInterface interface {
+	OpenAttempt(user *entity.User, test *entity.Test, attemptId *uint, assignmentId *uint, now time.Time) (*entity.Attempt, error)
+}
+
 type TestValidator struct {
 	testManagerRepo TestManagerRepoV2Interface
+	userRepo        UserRepoInterfaceGetByLogin
+	attemptRepo     AttemptRepoInterface
+	attempts        AttemptOpenerInterface
+	access          TestAccessInterface
+	sandbox         CodeSandboxInterface
 }
 
 func NewTestValidator(
 	testManagerRepo TestManagerRepoV2Interface,
-
+	userRepo UserRepoInterfaceGetByLogin,
+	attemptRepo AttemptRepoInterface,
+	attempts AttemptOpenerInterface,
+	access TestAccessInterface,
+	sandbox CodeSandboxInterface,
 ) *TestValidator {
 	return &TestValidator{
 		testManagerRepo: testManagerRepo,
+		userRepo:        userRepo,
+		attemptRepo:     attemptRepo,
+		attempts:        attempts,
+		access:          access,
+		sandbox:         sandbox,
 	}
 }
 
-func (s *TestValidator) Validate(test *entity.Test) (*float64, error) {
+func (s *TestValidator) Validate(ctx context.Context, login string, data *dtos.ValidateResultRequestPayload) (*dtos.ValidateResultResponse, error) {
+	test := data.Test
+	user, err := s.userRepo.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("Validate: failed get user by login: %w", err)
+	}
+
 	exampleTest, err := s.testManagerRepo.GetTestById(test.ID)
 	if err != nil {
 		return nil, fmt.Errorf("Validate: failed to get test by ID: %w", err)
 	}
 
+	if exampleTest.Adaptive {
+		return nil, fmt.Errorf("Validate: test %d is adaptive and is answered question by question", exampleTest.ID)
+	}
+
+	role, err := s.access.Role(exampleTest, user)
+	if err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
+	now := time.Now()
+	if !canViewPrivateTest(role) && !isTestAvailable(exampleTest, now) {
+		return nil, fmt.Errorf("Validate: test is closed")
+	}
+
+	attempt, err := s.attempts.OpenAttempt(user, exampleTest, data.AttemptID, data.AssignmentID, now)
+	if err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
 	err = s.testManagerRepo.IncrementCountUserPast(test.ID, int(exampleTest.CountUserPast))
 	if err != nil {
 		return nil, fmt.Errorf("Validate: failed to increment count user past: %w", err)
 	}
 
-	var (
-		totalCorrect int
-		totalAnswers int
-	)
+	given := newSubmittedAnswers(test, data.TextAnswers, data.CodeAnswers, data.NumericAnswers)
 
-	for _, question := range exampleTest.Questions {
-		for _, userQuestion := range test.Questions {
+	var answers, scored []entity.AttemptAnswer
+	if len(exampleTest.Sections) > 0 {
+		answers, scored, err = s.gradeSections(ctx, exampleTest, attempt, given, now)
+		if err != nil {
+			return nil, fmt.Errorf("Validate: %w", err)
+		}
+	} else {
+		answers = gradeQuestions(ctx, s.sandbox, exampleTest.Questions, given, attempt, now)
+		scored = answers
+	}
+
+	percentage, pending := scoreAnswers(exampleTest, scored)
+
+	grade := gradeScore(exampleTest, percentage)
+	if err := s.attemptRepo.FinishAttempt(attempt.ID, percentage, grade, answers, pending, now); err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
+	return &dtos.ValidateResultResponse{
+		AttemptID:     attempt.ID,
+		PendingReview: pending,
+		Score:         percentage,
+		Passed:        grade.Passed,
+		Band:          grade.Band,
+		Feedback:      grade.Feedback,
+		Sections:      sectionScores(exampleTest, scored),
+	}, nil
+}
+
+// gradeSections grades the current section of an attempt by the submitted
+// answers, as long as its time is not up, and the sections never reached as
+// unanswered. The answers stored when the other sections were left are kept:
+// they are only in scored, next to the answers graded now.
+func (s *TestValidator) gradeSections(ctx context.Context, test *entity.Test, attempt *entity.Attempt, given submittedAnswers, now time.Time) ([]entity.AttemptAnswer, []entity.AttemptAnswer, error) {
+	stored, err := s.attemptRepo.GetAttemptAnswers(attempt.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gradeSections: %w", err)
+	}
+
+	answered := make(map[uint]bool, len(stored))
+	for _, answer := range stored {
+		answered[answer.QuestionID] = true
+	}
+
+	current := currentSection(test, attempt)
+	timely := current != nil && sectionInTime(*current, attemptSection(attempt, current.ID), now)
+
+	var open, unanswered []entity.Question
+	for _, question := range test.Questions {
+		if timely && question.SectionID != nil && *question.SectionID == current.ID {
+			open = append(open, question)
+		} else if !answered[question.ID] {
+			unanswered = append(unanswered, question)
+		}
+	}
+
+	answers := gradeQuestions(ctx, s.sandbox, open, given, attempt, now)
+	answers = append(answers, gradeQuestions(ctx, s.sandbox, unanswered, submittedAnswers{}, attempt, now)...)
+
+	regraded := make(map[uint]bool, len(open))
+	for _, question := range open {
+		regraded[question.ID] = true
+	}
+
+	scored := append([]entity.AttemptAnswer{}, answers...)
+	for _, answer := range stored {
+		if !regraded[answer.QuestionID] {
+			scored = append(scored, answer)
+		}
+	}
+
+	return answers, scored, nil
+}
+
+// submittedAnswers are the answers of a user as they were sent. Choices holds
+// the marked variants in the questions of a test like the user got it.
+type submittedAnswers struct {
+	choices *entity.Test
+	texts   map[uint]string
+	codes   map[uint]string
+	numbers map[uint]*float64
+}
+
+func newSubmittedAnswers(choices *entity.Test, texts []dtos.TextAnswerInput, codes []dtos.CodeAnswerInput, numbers []dtos.NumericAnswerInput) submittedAnswers {
+	given := submittedAnswers{
+		choices: choices,
+		texts:   make(map[uint]string, len(texts)),
+		codes:   make(map[uint]string, len(codes)),
+		numbers: make(map[uint]*float64, len(numbers)),
+	}
+	for _, answer := range texts {
+		given.texts[answer.QuestionID] = answer.Text
+	}
+	for _, answer := range codes {
+		given.codes[answer.QuestionID] = answer.Code
+	}
+	for _, answer := range numbers {
+		given.numbers[answer.QuestionID] = answer.Value
+	}
+	return given
+}
+
+// gradeQuestions turns the submitted answers to the given questions into
+// answers of the attempt.
+func gradeQuestions(ctx context.Context, sandbox CodeSandboxInterface, questions []entity.Question, given submittedAnswers, attempt *entity.Attempt, now time.Time) []entity.AttemptAnswer {
+	var answers []entity.AttemptAnswer
+	for _, question := range questions {
+		if question.Type == constants.QuestionNumeric {
+			answers = append(answers, gradeNumeric(question, attempt, given.numbers[question.ID], now))
+			continue
+		}
+
+		if question.Type == constants.QuestionCode {
+			answer := entity.AttemptAnswer{
+				AttemptID:  attempt.ID,
+				QuestionID: question.ID,
+				Text:       given.codes[question.ID],
+			}
+			if strings.TrimSpace(answer.Text) == "" {
+				zero := 0.0
+				answer.Points = &zero
+				answer.GradedAt = &now
+			} else if points, results, err := gradeCode(ctx, sandbox, question, answer.Text); err == nil {
+				answer.Points = &points
+				answer.CaseResults = results
+				answer.GradedAt = &now
+			}
+			// When the sandbox could not run the code the answer waits for a
+			// grader like a text answer does.
+			answers = append(answers, answer)
+			continue
+		}
+
+		if question.Type == constants.QuestionText {
+			answer := entity.AttemptAnswer{
+				AttemptID:  attempt.ID,
+				QuestionID: question.ID,
+				Text:       strings.TrimSpace(given.texts[question.ID]),
+			}
+			if answer.Text == "" {
+				zero := 0.0
+				answer.Points = &zero
+				answer.GradedAt = &now
+			}
+			answers = append(answers, answer)
+			continue
+		}
+
+		if given.choices == nil {
+			continue
+		}
+
+		for _, userQuestion := range given.choices.Questions {
 			if question.ID != userQuestion.ID {
 				continue
 			}
@@ -49,20 +245,55 @@ func (s *TestValidator) Validate(test *entity.Test) (*float64, error) {
 			for _, variant := range question.Variants {
 				for _, userVariant := range userQuestion.Variants {
 					if variant.Name == userVariant.Name {
-						totalAnswers++
-						if variant.IsCorrect == userVariant.IsCorrect {
-							totalCorrect++
+						correct := variant.IsCorrect == userVariant.IsCorrect
+						points := 0.0
+						if correct {
+							points = 1
 						}
+						answers = append(answers, entity.AttemptAnswer{
+							AttemptID:  attempt.ID,
+							QuestionID: question.ID,
+							VariantID:  variant.ID,
+							Selected:   userVariant.IsCorrect,
+							Correct:    correct,
+							Points:     &points,
+						})
 					}
 				}
 			}
 		}
 	}
+	return answers
+}
+
+// scoreAnswers turns answers into a percentage. Every marked variant is worth
+// one point and a text or code answer up to the MaxPoints of its question.
+// While such an answer is not graded the score is unknown and pending is true.
+func scoreAnswers(test *entity.Test, answers []entity.AttemptAnswer) (*float64, bool) {
+	maxPoints := make(map[uint]float64, len(test.Questions))
+	for _, question := range test.Questions {
+		if question.Type != constants.QuestionChoice {
+			maxPoints[question.ID] = question.MaxPoints
+		}
+	}
+
+	var got, total float64
+	for _, answer := range answers {
+		if answer.Points == nil {
+			return nil, true
+		}
+		got += *answer.Points
+		if max, ok := maxPoints[answer.QuestionID]; ok && answer.VariantID == 0 {
+			total += max
+		} else {
+			total++
+		}
+	}
 
-	if totalAnswers == 0 {
-		return nil, nil
+	if total == 0 {
+		return nil, false
 	}
 
-	percentage := (float64(totalCorrect) / float64(totalAnswers)) * 100
-	return &percentage, nil
+	score := got / total * 100
+	return &score, false
 }
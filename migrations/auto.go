@@ -6,8 +6,10 @@ import (
 	"github.com/server/adapters/storage/postgresql"
 	"github.com/server/configs"
 	"github.com/server/entity"
+	"github.com/server/pkg/constants"
 	"github.com/server/pkg/logger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -26,11 +28,42 @@ func main() {
 
 	connPostgres := db.Connection()
 
-	if err := connPostgres.AutoMigrate(&entity.User{}, &entity.Test{}, &entity.Question{}, &entity.Variant{}); err != nil {
+	if err := connPostgres.AutoMigrate(&entity.User{}, &entity.Session{}, &entity.PasswordResetToken{}, &entity.RecoveryCode{}, &entity.UserIdentity{}, &entity.APIKey{},
+		&entity.AuditLog{}, &entity.Organization{}, &entity.OrganizationMember{}, &entity.OrganizationInvite{}, &entity.Test{}, &entity.Question{}, &entity.Variant{},
+		&entity.TestCollaborator{}, &entity.Group{}, &entity.GroupMember{}, &entity.Assignment{}, &entity.Attempt{},
+		&entity.ScheduledChange{}, &entity.TestInvitee{}, &entity.TestInviteLink{}, &entity.TestAccessGrant{}, &entity.Guest{}, &entity.GradeBand{}, &entity.AttemptAnswer{},
+		&entity.RubricCriterion{}, &entity.RubricLevel{}, &entity.AnswerCriterionScore{}, &entity.SimilarityFlag{},
+		&entity.CodeTestCase{}, &entity.CodeTestResult{}, &entity.QuestionVariable{}, &entity.AttemptVariable{},
+		&entity.PracticeCard{}, &entity.PracticeReview{}, &entity.Section{}, &entity.AttemptSection{}); err != nil {
 		log.Error("Error migration", zap.Error(err))
 		os.Exit(1)
 	}
 
+	if err := connPostgres.Model(&entity.Attempt{}).
+		Where("started_at IS NULL").
+		Update("started_at", gorm.Expr("submitted_at")).Error; err != nil {
+		log.Error("Failed to backfill attempt start time", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// One open attempt per user, test and assignment, so concurrent starts
+	// cannot slip past the attempt limits.
+	if err := connPostgres.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_attempts_open
+		ON attempts (user_id, test_id, COALESCE(assignment_id, 0))
+		WHERE submitted_at IS NULL AND deleted_at IS NULL`).Error; err != nil {
+		log.Error("Failed to create open attempt index", zap.Error(err))
+		os.Exit(1)
+	}
+
+	if cfg.BOOTSTRAP_ADMIN_LOGIN != "" {
+		if err := connPostgres.Model(&entity.User{}).
+			Where("login = ?", cfg.BOOTSTRAP_ADMIN_LOGIN).
+			Update("role", constants.RoleAdmin).Error; err != nil {
+			log.Error("Failed to promote bootstrap admin", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
 	log.Info("Migrations completed")
 
 }
@@ -0,0 +1,14 @@
+package constants
+
+import "time"
+
+var (
+	AdaptiveModel1PL = "1pl"
+	AdaptiveModel2PL = "2pl"
+)
+
+const ITEM_CALIBRATION_INTERVAL = time.Hour
+
+// ITEM_CALIBRATION_MIN_RESPONSES is how many answers a question needs before
+// its parameters are fitted, fewer keep the current ones.
+const ITEM_CALIBRATION_MIN_RESPONSES = 20
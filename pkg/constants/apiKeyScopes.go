@@ -0,0 +1,9 @@
+package constants
+
+var (
+	API_KEY_PREFIX = "tc_"
+
+	ScopeReadTests   = "tests:read"
+	ScopeWriteTests  = "tests:write"
+	ScopeReadResults = "results:read"
+)
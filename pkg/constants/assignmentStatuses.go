@@ -0,0 +1,8 @@
+package constants
+
+var (
+	AssignmentUpcoming  = "upcoming"
+	AssignmentPending   = "pending"
+	AssignmentOverdue   = "overdue"
+	AssignmentCompleted = "completed"
+)
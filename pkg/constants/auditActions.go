@@ -0,0 +1,11 @@
+package constants
+
+var (
+	AuditUserSuspended      = "user.suspended"
+	AuditUserUnsuspended    = "user.unsuspended"
+	AuditUserRoleChanged    = "user.role_changed"
+	AuditUserImpersonate    = "user.impersonated"
+	AuditImpersonatedAction = "user.impersonated_action"
+	AuditImpersonationEnded = "user.impersonation_ended"
+	AuditTestUnpublished    = "test.unpublished"
+)
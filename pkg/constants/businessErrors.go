@@ -9,6 +9,74 @@ var (
 	ErrRegistration          = "Не получилось зарегистрировать вас в системе, попробуйте позже"
 	ErrGetUserData           = "Не удалось войти в систему, попробуйте позже"
 	ErrLogout                = "Не получилось выйти из аккаунта, попробуйте позже"
+	ErrGetSessions           = "Не удалось получить список сеансов, попробуйте позже"
+	ErrRevokeSession         = "Не удалось завершить сеанс, попробуйте позже"
+	ErrForgotPassword        = "Не удалось отправить письмо для сброса пароля, попробуйте позже"
+	ErrResetPassword         = "Ссылка для сброса пароля недействительна или устарела"
+	ErrChangePassword        = "Не удалось изменить пароль, проверьте текущий пароль"
+	ErrVerifyEmail           = "Ссылка для подтверждения почты недействительна или устарела"
+	ErrResendVerification    = "Не удалось отправить письмо для подтверждения почты"
+	ErrEmailNotVerified      = "Подтвердите адрес почты, чтобы продолжить"
+	ErrTwoFactorCode         = "Неверный код подтверждения"
+	ErrTwoFactorEnroll       = "Не удалось подключить двухфакторную аутентификацию"
+	ErrTwoFactorDisable      = "Не удалось отключить двухфакторную аутентификацию"
+	ErrOIDCLogin             = "Не удалось войти через внешнего провайдера"
+	ErrCreateAPIKey          = "Не удалось создать API-ключ"
+	ErrGetAPIKeys            = "Не удалось получить список API-ключей"
+	ErrRevokeAPIKey          = "Не удалось отозвать API-ключ"
+	ErrAccessDenied          = "Недостаточно прав для выполнения действия"
+	ErrGetUsers              = "Не удалось получить список пользователей"
+	ErrSuspendUser           = "Не удалось изменить блокировку пользователя"
+	ErrChangeRole            = "Не удалось изменить роль пользователя"
+	ErrImpersonate           = "Не удалось войти от имени пользователя"
+	ErrUnpublishTest         = "Не удалось снять тест с публикации"
+	ErrGetAuditLog           = "Не удалось получить журнал действий"
+	ErrCreateOrganization    = "Не удалось создать организацию"
+	ErrGetOrganizations      = "Не удалось получить список организаций"
+	ErrManageMembers         = "Не удалось изменить участников организации"
+	ErrInviteMember          = "Не удалось пригласить участника"
+	ErrAcceptInvite          = "Приглашение недействительно или устарело"
+	ErrGetCollaborators      = "Не удалось получить список соавторов"
+	ErrManageCollaborators   = "Не удалось изменить список соавторов"
+	ErrCreateGroup           = "Не удалось создать группу"
+	ErrGetGroups             = "Не удалось получить список групп"
+	ErrJoinGroup             = "Код приглашения в группу недействителен"
+	ErrManageGroup           = "Не удалось изменить группу"
+	ErrCreateAssignment      = "Не удалось назначить тест группе"
+	ErrGetAssignments        = "Не удалось получить список заданий"
+	ErrAssignmentReport      = "Не удалось получить отчёт по заданию"
+	ErrAssignmentClosed      = "Задание закрыто или попытки закончились"
+	ErrTestClosed            = "Тест сейчас недоступен для прохождения"
+	ErrChangeTestWindow      = "Не удалось изменить время доступности теста"
+	ErrScheduleChange        = "Не удалось запланировать изменение теста"
+	ErrGetScheduledChanges   = "Не удалось получить запланированные изменения"
+	ErrStartAttempt          = "Попытки закончились или следующая попытка пока недоступна"
+	ErrUpdateAttemptPolicy   = "Не удалось изменить правила попыток теста"
+	ErrGetResults            = "Не удалось получить результаты теста"
+	ErrGetLeaderboard        = "Не удалось получить таблицу лидеров"
+	ErrManageTestAccess      = "Не удалось изменить настройки доступа к тесту"
+	ErrTestAccessCode        = "Неверный код доступа к тесту"
+	ErrTestInviteLink        = "Ссылка-приглашение недействительна, устарела или исчерпана"
+	ErrStartGuest            = "Тест недоступен для прохождения без регистрации"
+	ErrClaimGuest            = "Не удалось перенести гостевые попытки в аккаунт"
+	ErrManageGuestAccess     = "Не удалось изменить гостевой доступ к тесту"
+	ErrUpdateGrading         = "Не удалось изменить проходной балл и шкалу оценок теста"
+	ErrUpdateReviewPolicy    = "Не удалось изменить настройки просмотра результатов"
+	ErrAttemptReview         = "Разбор попытки пока недоступен"
+	ErrGradeAnswers          = "Не удалось оценить ответы"
+	ErrFinalizeAttempt       = "Не удалось завершить проверку попытки"
+	ErrManageRubric          = "Не удалось изменить критерии оценивания вопроса"
+	ErrManageCodeTests       = "Не удалось изменить тесты задачи с кодом"
+	ErrAdaptiveSettings      = "Не удалось изменить настройки адаптивного тестирования"
+	ErrCalibrateItems        = "Не удалось откалибровать вопросы теста"
+	ErrNextQuestion          = "Не удалось получить следующий вопрос"
+	ErrPracticeSettings      = "Не удалось изменить настройки тренировки"
+	ErrGetPractice           = "Не удалось получить вопросы для тренировки"
+	ErrPracticeAnswer        = "Не удалось проверить ответ"
+	ErrManageSections        = "Не удалось изменить разделы теста"
+	ErrGetSection            = "Не удалось получить состояние раздела"
+	ErrEnterSection          = "Не удалось перейти в раздел"
+	ErrOIDCLink              = "Не удалось привязать внешнего провайдера"
 )
 
 var (
@@ -0,0 +1,10 @@
+package constants
+
+var (
+	GradingBest    = "best"
+	GradingLast    = "last"
+	GradingFirst   = "first"
+	GradingAverage = "average"
+)
+
+const LEADERBOARD_SIZE = 10
@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+var (
+	GuestInfoNone  = "none"
+	GuestInfoName  = "name"
+	GuestInfoEmail = "email"
+)
+
+const GUEST_EMAIL_DOMAIN = "guest.invalid"
+
+const GUEST_START_LIMIT = 10
+
+const GUEST_START_WINDOW = time.Hour
@@ -0,0 +1,16 @@
+package constants
+
+var (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleEditor = "editor"
+	OrgRoleViewer = "viewer"
+)
+
+// OrgRoleRank orders organization roles so checks can ask for "at least editor".
+var OrgRoleRank = map[string]int{
+	OrgRoleViewer: 1,
+	OrgRoleEditor: 2,
+	OrgRoleAdmin:  3,
+	OrgRoleOwner:  4,
+}
@@ -0,0 +1,15 @@
+package constants
+
+var (
+	QuestionChoice  = "choice"
+	QuestionText    = "text"
+	QuestionCode    = "code"
+	QuestionNumeric = "numeric"
+)
+
+// NUMERIC_MAX_DECIMALS bounds the rounding of numeric variables, more digits
+// overflow the scale values are rounded with.
+const NUMERIC_MAX_DECIMALS = 10
+
+// NUMERIC_MAX_EXPR_LENGTH bounds the answer expression of a numeric question.
+const NUMERIC_MAX_EXPR_LENGTH = 1000
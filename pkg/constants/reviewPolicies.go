@@ -0,0 +1,14 @@
+package constants
+
+var (
+	ReviewNever    = "never"
+	ReviewScore    = "score"
+	ReviewMistakes = "mistakes"
+	ReviewFull     = "full"
+)
+
+var (
+	ReviewReleaseImmediate = "immediate"
+	ReviewReleaseDeadline  = "after_deadline"
+	ReviewReleaseManual    = "manual"
+)
@@ -0,0 +1,29 @@
+package constants
+
+import "time"
+
+// SANDBOX_BUILD_TIMEOUT bounds compiling a submission, a cold build cache
+// makes the first builds slow.
+const SANDBOX_BUILD_TIMEOUT = 60 * time.Second
+
+// SANDBOX_RUN_TIMEOUT bounds one run of a submission on one test case.
+const SANDBOX_RUN_TIMEOUT = 2 * time.Second
+
+const SANDBOX_MEMORY_MB = 256
+
+// SANDBOX_OUTPUT_BYTES is how much of stdout and stderr is kept per run.
+const SANDBOX_OUTPUT_BYTES = 64 << 10
+
+// SANDBOX_PROCESSES caps the processes and threads of one run, so a fork
+// bomb stays inside its sandbox.
+const SANDBOX_PROCESSES = 32
+
+// SANDBOX_BUILD_MEMORY_MB and SANDBOX_BUILD_PROCESSES bound the compiler the
+// way the run limits bound a program. Processes count threads as well.
+const SANDBOX_BUILD_MEMORY_MB = 2048
+
+const SANDBOX_BUILD_PROCESSES = 256
+
+// SANDBOX_CONCURRENCY is how many submissions are built and run at once
+// across the server.
+const SANDBOX_CONCURRENCY = 4
@@ -0,0 +1,12 @@
+package constants
+
+import "time"
+
+var (
+	SchedulePublish   = "publish"
+	ScheduleUnpublish = "unpublish"
+)
+
+const SCHEDULED_CHANGES_INTERVAL = 30 * time.Second
+
+const SCHEDULED_CHANGES_BATCH = 100
@@ -0,0 +1,7 @@
+package constants
+
+import "time"
+
+// SECTION_GRACE_PERIOD is how late the answers of a timed section are still
+// taken, so that a request sent right at the limit is not lost.
+const SECTION_GRACE_PERIOD = 10 * time.Second
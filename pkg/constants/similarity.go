@@ -0,0 +1,12 @@
+package constants
+
+import "time"
+
+const SIMILARITY_CHECK_INTERVAL = 5 * time.Minute
+
+const SIMILARITY_CHECK_BATCH = 200
+
+// SIMILARITY_SHINGLE_SIZE is the number of words in a compared shingle.
+const SIMILARITY_SHINGLE_SIZE = 3
+
+const SIMILARITY_SIGNATURE_SIZE = 128
@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+var (
+	AccessPublic = "public"
+	AccessLink   = "link"
+	AccessCode   = "code"
+	AccessInvite = "invite"
+)
+
+var (
+	GrantSourceLink       = "link"
+	GrantSourceCode       = "code"
+	GrantSourceInviteLink = "invite_link"
+)
+
+const UNLOCK_LIMIT = 5
+
+const UNLOCK_WINDOW = 15 * time.Minute
@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+const PASSWORD_RESET_TOKEN_TTL = 1 * time.Hour
+
+const EMAIL_VERIFICATION_TOKEN_TTL = 24 * time.Hour
+
+const TWO_FACTOR_CHALLENGE_TTL = 5 * time.Minute
+
+const OIDC_STATE_TTL = 10 * time.Minute
+
+const ORGANIZATION_INVITE_TTL = 7 * 24 * time.Hour
+
+const GUEST_TOKEN_TTL = 2 * time.Hour
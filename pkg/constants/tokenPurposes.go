@@ -0,0 +1,7 @@
+package constants
+
+var (
+	EmailVerificationPurpose  = "email_verification"
+	TwoFactorChallengePurpose = "two_factor_challenge"
+	GuestPurpose              = "guest"
+)
@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+const (
+	TOTP_ISSUER          = "TestConstructor"
+	RECOVERY_CODES_COUNT = 10
+)
+
+// After TWO_FACTOR_MAX_FAILURES wrong codes in a row the second factor of a
+// user is locked for TWO_FACTOR_LOCKOUT.
+const (
+	TWO_FACTOR_MAX_FAILURES = 5
+	TWO_FACTOR_LOCKOUT      = 15 * time.Minute
+)
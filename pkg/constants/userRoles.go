@@ -3,4 +3,45 @@ package constants
 var (
 	PassingRole = "passing"
 	OwnerRole   = "owner"
+	EditorRole  = "editor"
+	ViewerRole  = "viewer"
 )
+
+// TestRoleRank orders the effective roles a user can have on a test.
+var TestRoleRank = map[string]int{
+	PassingRole: 0,
+	ViewerRole:  1,
+	EditorRole:  2,
+	OwnerRole:   3,
+}
+
+var (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	RoleGuest     = "guest"
+)
+
+var (
+	PermissionViewUsers      = "users:view"
+	PermissionManageUsers    = "users:manage"
+	PermissionImpersonate    = "users:impersonate"
+	PermissionUnpublishTests = "tests:unpublish"
+	PermissionViewAudit      = "audit:view"
+)
+
+var RolePermissions = map[string][]string{
+	RoleUser:  {},
+	RoleGuest: {},
+	RoleModerator: {
+		PermissionViewUsers,
+		PermissionUnpublishTests,
+	},
+	RoleAdmin: {
+		PermissionViewUsers,
+		PermissionManageUsers,
+		PermissionImpersonate,
+		PermissionUnpublishTests,
+		PermissionViewAudit,
+	},
+}
@@ -0,0 +1,456 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+const (
+	maxLength = 1000
+	maxDepth  = 64
+)
+
+// Expr is a parsed arithmetic expression over named variables. It supports
+// numbers, + - * / % ^, parentheses, the constants pi and e and a fixed set of
+// math functions. Nothing else can be reached from an expression.
+type Expr struct {
+	root      node
+	variables []string
+}
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type function struct {
+	minArgs int
+	maxArgs int
+	call    func(args []float64) float64
+}
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+var functions = map[string]function{
+	"abs":   unary(math.Abs),
+	"sqrt":  unary(math.Sqrt),
+	"cbrt":  unary(math.Cbrt),
+	"exp":   unary(math.Exp),
+	"ln":    unary(math.Log),
+	"log10": unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"pow":   {minArgs: 2, maxArgs: 2, call: func(args []float64) float64 { return math.Pow(args[0], args[1]) }},
+	"hypot": {minArgs: 2, maxArgs: 2, call: func(args []float64) float64 { return math.Hypot(args[0], args[1]) }},
+	"min":   {minArgs: 1, maxArgs: -1, call: fold(math.Min)},
+	"max":   {minArgs: 1, maxArgs: -1, call: fold(math.Max)},
+}
+
+func unary(fn func(float64) float64) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(args []float64) float64 { return fn(args[0]) }}
+}
+
+func fold(fn func(float64, float64) float64) func([]float64) float64 {
+	return func(args []float64) float64 {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = fn(result, arg)
+		}
+		return result
+	}
+}
+
+// IsName tells whether name can be used as a variable: an identifier that is
+// not taken by a constant or a function.
+func IsName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isIdentRune(r, i == 0) {
+			return false
+		}
+	}
+	_, isConstant := constants[name]
+	_, isFunction := functions[name]
+	return !isConstant && !isFunction
+}
+
+// Parse compiles source once so it can be evaluated with many sets of values.
+func Parse(source string) (*Expr, error) {
+	if len(source) > maxLength {
+		return nil, fmt.Errorf("Parse: expression is longer than %d characters", maxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("Parse: %w", err)
+	}
+
+	p := &parser{tokens: tokens, variables: make(map[string]bool)}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, fmt.Errorf("Parse: %w", err)
+	}
+	if p.peek().kind != tokenEnd {
+		return nil, fmt.Errorf("Parse: unexpected %q at %d", p.peek().text, p.peek().pos)
+	}
+
+	variables := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return &Expr{root: root, variables: variables}, nil
+}
+
+// Variables lists the names the expression needs values for.
+func (e *Expr) Variables() []string {
+	return e.variables
+}
+
+// Eval computes the expression. Missing variables and results that are not
+// finite numbers, like a division by zero, are errors.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	value, err := e.root.eval(vars)
+	if err != nil {
+		return 0, fmt.Errorf("Eval: %w", err)
+	}
+	return value, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenName
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+	pos   int
+}
+
+func isIdentRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("tokenize: bad number %q at %d", text, start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: start})
+		case isIdentRune(r, true):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i], false) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: string(runes[start:i]), pos: start})
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '%' || r == '^' || r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("tokenize: unexpected %q at %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens    []token
+	pos       int
+	variables map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(operator string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == operator {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseExpr handles + and -, the loosest operators.
+func (p *parser) parseExpr(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested too deep")
+	}
+
+	left, err := p.parseTerm(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.text != "+" && t.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseTerm(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseTerm(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOperator || (t.text != "*" && t.text != "/" && t.text != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: t.text, left: left, right: right}
+	}
+}
+
+// parseUnary binds looser than ^, so -2^2 is -4.
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("expression is nested too deep")
+	}
+
+	if p.accept("-") {
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	if p.accept("+") {
+		return p.parseUnary(depth + 1)
+	}
+	return p.parsePower(depth)
+}
+
+// parsePower is right associative, 2^3^2 is 2^9.
+func (p *parser) parsePower(depth int) (node, error) {
+	base, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("^") {
+		return base, nil
+	}
+	exponent, err := p.parseUnary(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{operator: "^", left: base, right: exponent}, nil
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return numberNode(t.value), nil
+	case tokenName:
+		if p.accept("(") {
+			return p.parseCall(t, depth)
+		}
+		if value, ok := constants[t.text]; ok {
+			return numberNode(value), nil
+		}
+		if _, ok := functions[t.text]; ok {
+			return nil, fmt.Errorf("function %s needs arguments at %d", t.text, t.pos)
+		}
+		p.variables[t.text] = true
+		return variableNode(t.text), nil
+	case tokenOperator:
+		if t.text == "(" {
+			inner, err := p.parseExpr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, fmt.Errorf("missing ) at %d", p.peek().pos)
+			}
+			return inner, nil
+		}
+	case tokenEnd:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at %d", name.text, name.pos)
+	}
+
+	var args []node
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseExpr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, fmt.Errorf("expected , or ) at %d", p.peek().pos)
+			}
+		}
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("function %s got %d arguments", name.text, len(args))
+	}
+
+	return callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+type numberNode float64
+
+func (n numberNode) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type variableNode string
+
+func (n variableNode) eval(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("variable %s has no value", string(n))
+	}
+	return value, nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n negateNode) eval(vars map[string]float64) (float64, error) {
+	value, err := n.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	return -value, nil
+}
+
+type binaryNode struct {
+	operator string
+	left     node
+	right    node
+}
+
+func (n binaryNode) eval(vars map[string]float64) (float64, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	var result float64
+	switch n.operator {
+	case "+":
+		result = left + right
+	case "-":
+		result = left - right
+	case "*":
+		result = left * right
+	case "/":
+		result = left / right
+	case "%":
+		result = math.Mod(left, right)
+	case "^":
+		result = math.Pow(left, right)
+	}
+	return finite(result, n.operator)
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return finite(n.fn.call(args), n.name)
+}
+
+func finite(value float64, operation string) (float64, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%s does not give a finite number", operation)
+	}
+	return value, nil
+}
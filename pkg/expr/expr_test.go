@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"a": 2, "b": -3, "x": 0.5}
+
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"7 % 4", 3},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"--2", 2},
+		{"+2", 2},
+		{"1.5e2", 150},
+		{"a * x + b", -2},
+		{"pi", math.Pi},
+		{"e", math.E},
+		{"abs(b)", 3},
+		{"sqrt(16)", 4},
+		{"pow(a, 10)", 1024},
+		{"hypot(3, 4)", 5},
+		{"min(3, a, 5)", 2},
+		{"max(3, a, 5)", 5},
+		{"max(1)", 1},
+		{"round(2.5)", 3},
+		{"floor(-x)", -1},
+	}
+
+	for _, tt := range tests {
+		parsed, err := Parse(tt.source)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.source, err)
+			continue
+		}
+		got, err := parsed.Eval(vars)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.source, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Eval(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"empty", ""},
+		{"unbalanced", "(1 + 2"},
+		{"trailing operator", "1 +"},
+		{"trailing token", "1 2"},
+		{"unknown character", "1 & 2"},
+		{"bad number", "1.2.3"},
+		{"unknown function", "foo(1)"},
+		{"function without call", "sqrt + 1"},
+		{"too few arguments", "pow(1)"},
+		{"too many arguments", "pow(1, 2, 3)"},
+		{"unary with two arguments", "sqrt(1, 2)"},
+		{"variadic without arguments", "min()"},
+		{"too long", strings.Repeat("1+", maxLength/2) + "1"},
+		{"nested parentheses", strings.Repeat("(", maxDepth+1) + "1" + strings.Repeat(")", maxDepth+1)},
+		{"nested calls", strings.Repeat("abs(", maxDepth+1) + "1" + strings.Repeat(")", maxDepth+1)},
+		{"nested signs", strings.Repeat("-", maxDepth+2) + "1"},
+		{"nested powers", strings.Repeat("2^", maxDepth+2) + "1"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.source); err == nil {
+			t.Errorf("%s: Parse(%q) succeeded", tt.name, tt.source)
+		}
+	}
+}
+
+func TestParseAllowsNestingWithinDepth(t *testing.T) {
+	source := strings.Repeat("(", maxDepth) + "1" + strings.Repeat(")", maxDepth)
+	if _, err := Parse(source); err != nil {
+		t.Fatalf("Parse of %d nested parentheses: %v", maxDepth, err)
+	}
+}
+
+func TestEvalRejectsNonFinite(t *testing.T) {
+	tests := []string{
+		"1 / 0",
+		"0 / 0",
+		"5 % 0",
+		"sqrt(-1)",
+		"ln(0)",
+		"10 ^ 400",
+		"exp(1000)",
+		"a / (a - 2)",
+	}
+
+	for _, source := range tests {
+		parsed, err := Parse(source)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", source, err)
+			continue
+		}
+		if got, err := parsed.Eval(map[string]float64{"a": 2}); err == nil {
+			t.Errorf("Eval(%q) = %v, want an error", source, got)
+		}
+	}
+}
+
+func TestEvalRejectsMissingVariable(t *testing.T) {
+	parsed, err := Parse("a + b")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := parsed.Eval(map[string]float64{"a": 1}); err == nil {
+		t.Fatal("Eval succeeded without a value for b")
+	}
+}
+
+func TestVariables(t *testing.T) {
+	parsed, err := Parse("x * y + sin(x) + pi + z")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, want := parsed.Variables(), []string{"x", "y", "z"}; !slices.Equal(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+}
+
+func TestIsName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"x", true},
+		{"rate_2", true},
+		{"_k", true},
+		{"", false},
+		{"2x", false},
+		{"a-b", false},
+		{"pi", false},
+		{"sqrt", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsName(tt.name); got != tt.want {
+			t.Errorf("IsName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package irt
+
+import "math"
+
+// Item is a question under the logistic model. Discrimination is 1 for every
+// item in the one parameter (Rasch) model.
+type Item struct {
+	Discrimination float64
+	Difficulty     float64
+}
+
+// Missing marks an item a person did not answer in a response matrix.
+const Missing int8 = -1
+
+const (
+	minAbility        = -4.0
+	maxAbility        = 4.0
+	quadraturePoints  = 81
+	minDiscrimination = 0.2
+	maxDiscrimination = 4.0
+	maxDifficulty     = 6.0
+)
+
+// Probability of a correct answer to item at the given ability.
+func Probability(ability float64, item Item) float64 {
+	return 1 / (1 + math.Exp(-item.Discrimination*(ability-item.Difficulty)))
+}
+
+// Information is how much an answer to item tells about an ability near the
+// given one. Adaptive tests ask the item with the most information next.
+func Information(ability float64, item Item) float64 {
+	p := Probability(ability, item)
+	return item.Discrimination * item.Discrimination * p * (1 - p)
+}
+
+// Estimate is the expected a posteriori ability under a standard normal prior
+// and its posterior standard deviation as the standard error. Unlike maximum
+// likelihood it is finite when every answer is right or every answer wrong.
+func Estimate(items []Item, correct []bool) (float64, float64) {
+	abilities, prior := grid()
+
+	var total, mean, square float64
+	for k, ability := range abilities {
+		logWeight := math.Log(prior[k])
+		for j, item := range items {
+			p := Probability(ability, item)
+			if correct[j] {
+				logWeight += math.Log(p)
+			} else {
+				logWeight += math.Log(1 - p)
+			}
+		}
+
+		weight := math.Exp(logWeight)
+		total += weight
+		mean += weight * ability
+		square += weight * ability * ability
+	}
+
+	if total == 0 {
+		return 0, 1
+	}
+
+	mean /= total
+	variance := square/total - mean*mean
+	return mean, math.Sqrt(math.Max(variance, 0))
+}
+
+// Calibrate fits item parameters to a response matrix with one row per person
+// and one column per item, holding 1, 0 or Missing. It is a marginal maximum
+// likelihood fit by EM over the same ability grid Estimate uses, with a
+// standard normal ability distribution fixing the scale and mild priors on
+// the items so that an item everybody got right still gets finite values.
+// Without twoParameter every discrimination stays 1.
+func Calibrate(responses [][]int8, items int, twoParameter bool) []Item {
+	result := make([]Item, items)
+	for i := range result {
+		result[i] = Item{Discrimination: 1}
+	}
+
+	abilities, prior := grid()
+	correct := make([][]float64, items)
+	answered := make([][]float64, items)
+	for i := range correct {
+		correct[i] = make([]float64, len(abilities))
+		answered[i] = make([]float64, len(abilities))
+	}
+
+	posterior := make([]float64, len(abilities))
+	for iteration := 0; iteration < 200; iteration++ {
+		for i := range correct {
+			clear(correct[i])
+			clear(answered[i])
+		}
+
+		// E step: spread every person over the grid by their posterior.
+		for _, row := range responses {
+			var total float64
+			for k, ability := range abilities {
+				logWeight := math.Log(prior[k])
+				for i, response := range row {
+					if response == Missing {
+						continue
+					}
+					p := Probability(ability, result[i])
+					if response == 1 {
+						logWeight += math.Log(p)
+					} else {
+						logWeight += math.Log(1 - p)
+					}
+				}
+				posterior[k] = math.Exp(logWeight)
+				total += posterior[k]
+			}
+			if total == 0 {
+				continue
+			}
+
+			for i, response := range row {
+				if response == Missing {
+					continue
+				}
+				for k := range abilities {
+					weight := posterior[k] / total
+					answered[i][k] += weight
+					if response == 1 {
+						correct[i][k] += weight
+					}
+				}
+			}
+		}
+
+		// M step: a few Newton steps per item on the expected counts.
+		var change float64
+		for i := range result {
+			item := result[i]
+			for step := 0; step < 5; step++ {
+				// Difficulty prior is normal with a standard deviation of 2.
+				gradient, curvature := -item.Difficulty/4, -0.25
+				for k, ability := range abilities {
+					p := Probability(ability, item)
+					gradient -= item.Discrimination * (correct[i][k] - answered[i][k]*p)
+					curvature -= item.Discrimination * item.Discrimination * answered[i][k] * p * (1 - p)
+				}
+				item.Difficulty = clamp(item.Difficulty-gradient/curvature, -maxDifficulty, maxDifficulty)
+
+				if !twoParameter {
+					continue
+				}
+
+				// Discrimination prior is normal around 1 with a deviation of 0.5.
+				gradient, curvature = -(item.Discrimination-1)/0.25, -4.0
+				for k, ability := range abilities {
+					p := Probability(ability, item)
+					distance := ability - item.Difficulty
+					gradient += (correct[i][k] - answered[i][k]*p) * distance
+					curvature -= answered[i][k] * p * (1 - p) * distance * distance
+				}
+				item.Discrimination = clamp(item.Discrimination-gradient/curvature, minDiscrimination, maxDiscrimination)
+			}
+
+			change = math.Max(change, math.Abs(item.Difficulty-result[i].Difficulty))
+			change = math.Max(change, math.Abs(item.Discrimination-result[i].Discrimination))
+			result[i] = item
+		}
+
+		if change < 1e-4 {
+			break
+		}
+	}
+
+	return result
+}
+
+// grid is the ability grid with standard normal weights summing to one.
+func grid() ([]float64, []float64) {
+	step := (maxAbility - minAbility) / (quadraturePoints - 1)
+	abilities := make([]float64, quadraturePoints)
+	weights := make([]float64, quadraturePoints)
+
+	var total float64
+	for k := range abilities {
+		abilities[k] = minAbility + float64(k)*step
+		weights[k] = math.Exp(-abilities[k] * abilities[k] / 2)
+		total += weights[k]
+	}
+	for k := range weights {
+		weights[k] /= total
+	}
+
+	return abilities, weights
+}
+
+func clamp(value float64, low float64, high float64) float64 {
+	return math.Min(math.Max(value, low), high)
+}
@@ -0,0 +1,88 @@
+package irt
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestProbability(t *testing.T) {
+	item := Item{Discrimination: 1.5, Difficulty: 0.5}
+	if got := Probability(0.5, item); math.Abs(got-0.5) > 1e-12 {
+		t.Errorf("Probability at the difficulty = %v, want 0.5", got)
+	}
+	if Probability(2, item) <= Probability(1, item) {
+		t.Error("Probability does not grow with the ability")
+	}
+	if got, want := Information(0.5, item), 1.5*1.5/4; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Information at the difficulty = %v, want %v", got, want)
+	}
+	if Information(3, item) >= Information(0.5, item) {
+		t.Error("Information away from the difficulty is not lower")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	ability, standardError := Estimate(nil, nil)
+	if math.Abs(ability) > 1e-9 || math.Abs(standardError-1) > 0.01 {
+		t.Errorf("Estimate without answers = %v ± %v, want the prior 0 ± 1", ability, standardError)
+	}
+
+	items := []Item{{1, -1}, {1, 0}, {1, 1}}
+	high, highError := Estimate(items, []bool{true, true, true})
+	low, lowError := Estimate(items, []bool{false, false, false})
+	if high <= 0 || math.IsInf(high, 0) || math.Abs(high+low) > 1e-9 {
+		t.Errorf("Estimate of all right = %v and all wrong = %v, want finite and opposite", high, low)
+	}
+	if highError >= 1 || math.Abs(highError-lowError) > 1e-9 {
+		t.Errorf("standard errors = %v and %v, want equal and below the prior", highError, lowError)
+	}
+
+	middle, _ := Estimate(items, []bool{true, true, false})
+	if middle <= low || middle >= high {
+		t.Errorf("Estimate of two right = %v, want between %v and %v", middle, low, high)
+	}
+}
+
+func TestCalibrateRecoversDifficulties(t *testing.T) {
+	truth := []Item{{1, -1.5}, {1, 0}, {1, 1.5}}
+	random := rand.New(rand.NewPCG(1, 2))
+
+	responses := make([][]int8, 2000)
+	for person := range responses {
+		ability := random.NormFloat64()
+		row := make([]int8, len(truth))
+		for i, item := range truth {
+			if random.Float64() < Probability(ability, item) {
+				row[i] = 1
+			}
+		}
+		if person%10 == 0 {
+			row[person%len(truth)] = Missing
+		}
+		responses[person] = row
+	}
+
+	for i, item := range Calibrate(responses, len(truth), false) {
+		if item.Discrimination != 1 {
+			t.Errorf("item %d discrimination = %v, want 1 in the one parameter model", i, item.Discrimination)
+		}
+		if math.Abs(item.Difficulty-truth[i].Difficulty) > 0.25 {
+			t.Errorf("item %d difficulty = %v, want near %v", i, item.Difficulty, truth[i].Difficulty)
+		}
+	}
+}
+
+func TestCalibrateKeepsUnanimousItemsFinite(t *testing.T) {
+	responses := [][]int8{{1, 0}, {1, 0}, {1, Missing}}
+	items := Calibrate(responses, 2, true)
+	if items[0].Difficulty >= items[1].Difficulty {
+		t.Errorf("difficulties = %v and %v, want the item everybody got right easier", items[0].Difficulty, items[1].Difficulty)
+	}
+	for i, item := range items {
+		if math.IsNaN(item.Difficulty) || math.Abs(item.Difficulty) > maxDifficulty ||
+			item.Discrimination < minDiscrimination || item.Discrimination > maxDiscrimination {
+			t.Errorf("item %d = %+v, want finite values within the bounds", i, item)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package jwt
+
+import "context"
+
+type contextKey string
+
+const loginContextKey contextKey = "login"
+
+func ContextWithLogin(ctx context.Context, login string) context.Context {
+	return context.WithValue(ctx, loginContextKey, login)
+}
+
+func loginFromContext(ctx context.Context) (string, bool) {
+	login, ok := ctx.Value(loginContextKey).(string)
+	return login, ok && login != ""
+}
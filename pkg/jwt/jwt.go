@@ -6,48 +6,66 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/server/configs"
 	cookiesmanager "github.com/server/pkg/cookiesManager"
+	"github.com/server/pkg/jwks"
 	"go.uber.org/zap"
 )
 
 type JWT struct {
 	Secret string
+	keys   *keySet
 	logger *zap.Logger
 }
 
+// NewJwt returns the token service over the keys loaded by Init, which has to
+// run first.
 func NewJwt(logger *zap.Logger) *JWT {
-	cfg, err := configs.Load(logger)
-	if err != nil {
-		return nil
+	if keys == nil {
+		panic("jwt: NewJwt called before Init")
 	}
 	return &JWT{
-		Secret: cfg.SECRET,
+		Secret: secret,
+		keys:   keys,
 		logger: logger,
 	}
 }
 
-func (s *JWT) createToken(login string, duration time.Duration) (string, error) {
+func (s *JWT) createToken(login string, sessionID uint, duration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"login": login,
+		"sid":   sessionID,
 		"exp":   time.Now().Add(duration).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.Secret))
+	return s.sign(claims)
+}
+
+func (s *JWT) sign(claims jwt.MapClaims) (string, error) {
+	if s.keys.signingKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.Secret))
+	}
+
+	token := jwt.NewWithClaims(s.keys.signingMethod, claims)
+	token.Header["kid"] = s.keys.signingKid
+	return token.SignedString(s.keys.signingKey)
 }
 
-func (s *JWT) CreateAccessToken(login string) (string, error) {
+func (s *JWT) PublicKeys() jwks.Set {
+	return s.keys.publicKeys()
+}
+
+func (s *JWT) CreateAccessToken(login string, sessionID uint) (string, error) {
 	healthTokenTime := time.Hour * 24
-	token, err := s.createToken(login, healthTokenTime)
+	token, err := s.createToken(login, sessionID, healthTokenTime)
 	if err != nil {
 		return "", fmt.Errorf("CreateAccessToken: failed to create access token: %w", err)
 	}
 	return token, nil
 }
 
-func (s *JWT) CreateRefreshToken(login string) (string, error) {
+func (s *JWT) CreateRefreshToken(login string, sessionID uint) (string, error) {
 	healthTokenTime := time.Hour * 24 * 7
-	token, err := s.createToken(login, healthTokenTime)
+	token, err := s.createToken(login, sessionID, healthTokenTime)
 	if err != nil {
 		return "", fmt.Errorf("CreateRefreshToken: failed to create refresh token: %w", err)
 	}
@@ -56,7 +74,15 @@ func (s *JWT) CreateRefreshToken(login string) (string, error) {
 
 func (s *JWT) VerifyToken(tokenString string) (*jwt.Token, jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if kid, ok := token.Header["kid"].(string); ok {
+			key, found := s.keys.verification[kid]
+			if !found || token.Method.Alg() != key.method.Alg() {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return key.key, nil
+		}
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || !s.keys.acceptHMAC {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return []byte(s.Secret), nil
@@ -75,6 +101,10 @@ func (s *JWT) VerifyToken(tokenString string) (*jwt.Token, jwt.MapClaims, error)
 }
 
 func (s *JWT) ExtractUserFromToken(r *http.Request) (string, error) {
+	if login, ok := loginFromContext(r.Context()); ok {
+		return login, nil
+	}
+
 	cookie := cookiesmanager.New(r, s.logger)
 	authToken, err := cookie.Get("token")
 	if err != nil {
@@ -85,6 +115,10 @@ func (s *JWT) ExtractUserFromToken(r *http.Request) (string, error) {
 		return "", fmt.Errorf("ExtractUserFromToken: failed verify auth token: %w", err)
 	}
 
+	if _, ok := claims["purpose"]; ok {
+		return "", fmt.Errorf("ExtractUserFromToken: token is not an auth token")
+	}
+
 	userLogin, ok := claims["login"].(string)
 	if !ok {
 		return "", fmt.Errorf("ExtractUserFromToken: failed get login from claims: %w", err)
@@ -93,16 +127,72 @@ func (s *JWT) ExtractUserFromToken(r *http.Request) (string, error) {
 	return userLogin, nil
 }
 
+func (s *JWT) ExtractSessionFromToken(r *http.Request) (uint, error) {
+	cookie := cookiesmanager.New(r, s.logger)
+	authToken, err := cookie.Get("token")
+	if err != nil {
+		return 0, fmt.Errorf("ExtractSessionFromToken: failed extract token from cookie: %w", err)
+	}
+	_, claims, err := s.VerifyToken(authToken)
+	if err != nil {
+		return 0, fmt.Errorf("ExtractSessionFromToken: failed verify auth token: %w", err)
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("ExtractSessionFromToken: failed get session id from claims")
+	}
+
+	return uint(sessionID), nil
+}
+
 func (s *JWT) RefreshAccessToken(refreshToken string) (string, error) {
 	_, claims, err := s.VerifyToken(refreshToken)
 	if err != nil {
 		return "", fmt.Errorf("RefreshAccessToken: failed verify refresh token: %w", err)
 	}
 
+	if _, ok := claims["purpose"]; ok {
+		return "", fmt.Errorf("RefreshAccessToken: token is not a refresh token")
+	}
+
 	userLogin, ok := claims["login"].(string)
 	if !ok {
 		return "", fmt.Errorf("RefreshAccessToken: failed get login from claims: %w", err)
 	}
 
-	return s.CreateAccessToken(userLogin)
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return "", fmt.Errorf("RefreshAccessToken: failed get session id from claims")
+	}
+
+	return s.CreateAccessToken(userLogin, uint(sessionID))
+}
+
+func (s *JWT) CreatePurposeToken(purpose string, claims jwt.MapClaims, duration time.Duration) (string, error) {
+	payload := jwt.MapClaims{}
+	for key, value := range claims {
+		payload[key] = value
+	}
+	payload["purpose"] = purpose
+	payload["exp"] = time.Now().Add(duration).Unix()
+
+	signed, err := s.sign(payload)
+	if err != nil {
+		return "", fmt.Errorf("CreatePurposeToken: failed to sign %s token: %w", purpose, err)
+	}
+	return signed, nil
+}
+
+func (s *JWT) VerifyPurposeToken(tokenString string, purpose string) (jwt.MapClaims, error) {
+	_, claims, err := s.VerifyToken(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyPurposeToken: %w", err)
+	}
+
+	if claims["purpose"] != purpose {
+		return nil, fmt.Errorf("VerifyPurposeToken: token is not a %s token", purpose)
+	}
+
+	return claims, nil
 }
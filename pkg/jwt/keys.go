@@ -0,0 +1,197 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/server/configs"
+	"github.com/server/pkg/jwks"
+)
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type keySet struct {
+	signingKid    string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.PrivateKey
+	verification  map[string]verificationKey
+	acceptHMAC    bool
+}
+
+var (
+	keys   *keySet
+	secret string
+)
+
+// Init loads the keys tokens are signed and verified with. It is called once
+// on startup, so a missing or broken key stops the server from starting
+// instead of failing every request.
+func Init(cfg *configs.Config) error {
+	set, err := newKeySet(cfg)
+	if err != nil {
+		return fmt.Errorf("Init: %w", err)
+	}
+
+	keys = set
+	secret = cfg.SECRET
+	return nil
+}
+
+func newKeySet(cfg *configs.Config) (*keySet, error) {
+	set := &keySet{
+		verification: make(map[string]verificationKey),
+		acceptHMAC:   cfg.JWT_ACCEPT_HS256,
+	}
+
+	signingPEM := cfg.JWT_SIGNING_KEY
+	if signingPEM == "" && cfg.JWT_SIGNING_KEY_FILE != "" {
+		data, err := os.ReadFile(cfg.JWT_SIGNING_KEY_FILE)
+		if err != nil {
+			return nil, fmt.Errorf("newKeySet: failed read signing key file: %w", err)
+		}
+		signingPEM = string(data)
+	}
+
+	if signingPEM == "" {
+		set.acceptHMAC = true
+		return set, nil
+	}
+
+	if cfg.JWT_SIGNING_KEY_ID == "" {
+		return nil, fmt.Errorf("newKeySet: JWT_SIGNING_KEY_ID is required with a signing key")
+	}
+
+	privateKey, method, err := parsePrivateKey([]byte(signingPEM))
+	if err != nil {
+		return nil, fmt.Errorf("newKeySet: %w", err)
+	}
+	set.signingKid = cfg.JWT_SIGNING_KEY_ID
+	set.signingMethod = method
+	set.signingKey = privateKey
+	set.verification[set.signingKid] = verificationKey{
+		method: method,
+		key:    privateKey.(crypto.Signer).Public(),
+	}
+
+	for _, entry := range strings.Split(cfg.JWT_VERIFICATION_KEYS, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, source, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("newKeySet: verification key %q must look like kid=path or kid=PEM", entry)
+		}
+
+		data, err := readVerificationKey(source)
+		if err != nil {
+			return nil, fmt.Errorf("newKeySet: failed read verification key %s: %w", kid, err)
+		}
+
+		publicKey, method, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("newKeySet: verification key %s: %w", kid, err)
+		}
+		set.verification[kid] = verificationKey{method: method, key: publicKey}
+	}
+
+	return set, nil
+}
+
+// readVerificationKey returns the PEM of a JWT_VERIFICATION_KEYS entry, which
+// is either the PEM itself or the path of a file holding it.
+func readVerificationKey(source string) ([]byte, error) {
+	source = strings.TrimSpace(source)
+	if strings.HasPrefix(source, "-----BEGIN ") {
+		return []byte(source), nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("readVerificationKey: %w", err)
+	}
+	return data, nil
+}
+
+func (s *keySet) publicKeys() jwks.Set {
+	set := jwks.Set{Keys: []jwks.Key{}}
+	for kid, key := range s.verification {
+		jwk, err := jwks.FromPublicKey(kid, key.method.Alg(), key.key)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func parsePrivateKey(data []byte) (crypto.PrivateKey, jwt.SigningMethod, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("parsePrivateKey: no PEM block found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, nil, fmt.Errorf("parsePrivateKey: unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsePrivateKey: %w", err)
+	}
+
+	switch privateKey := key.(type) {
+	case *rsa.PrivateKey:
+		return privateKey, jwt.SigningMethodRS256, nil
+	case ed25519.PrivateKey:
+		return privateKey, jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("parsePrivateKey: unsupported key type %T", key)
+	}
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, jwt.SigningMethod, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("parsePublicKey: no PEM block found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, nil, fmt.Errorf("parsePublicKey: unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsePublicKey: %w", err)
+	}
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		return publicKey, jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return publicKey, jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("parsePublicKey: unsupported key type %T", key)
+	}
+}
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/server/configs"
+	"go.uber.org/zap"
+)
+
+type Mailer struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+	logger   *zap.Logger
+}
+
+func New(cfg *configs.Config, logger *zap.Logger) *Mailer {
+	return &Mailer{
+		host:     cfg.SMTP_HOST,
+		port:     cfg.SMTP_PORT,
+		user:     cfg.SMTP_USER,
+		password: cfg.SMTP_PASSWORD,
+		from:     cfg.SMTP_FROM,
+		logger:   logger,
+	}
+}
+
+func (s *Mailer) Send(to string, subject string, body string) error {
+	if s.host == "" {
+		s.logger.Warn("SMTP is not configured, email is not sent", zap.String("to", to), zap.String("subject", subject))
+		return nil
+	}
+
+	message := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.user != "" {
+		auth = smtp.PlainAuth("", s.user, s.password, s.host)
+	}
+
+	if err := smtp.SendMail(net.JoinHostPort(s.host, s.port), auth, s.from, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("Send: failed send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/server/configs"
+	"github.com/server/pkg/jwks"
+)
+
+const keysRefreshInterval = time.Minute
+
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type Provider struct {
+	config configs.OIDCProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovered    bool
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+}
+
+func NewProvider(config configs.OIDCProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{
+		config: config,
+		client: client,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", fmt.Errorf("AuthCodeURL: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.config.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.config.AuthURL + separator + query.Encode(), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", fmt.Errorf("Exchange: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("Exchange: failed create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := p.doJSON(req, &token); err != nil {
+		return "", fmt.Errorf("Exchange: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return "", fmt.Errorf("Exchange: token response has no id_token")
+	}
+
+	return token.IDToken, nil
+}
+
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, fmt.Errorf("VerifyIDToken: %w", err)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyIDToken: invalid id token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("VerifyIDToken: nonce mismatch")
+	}
+
+	result := &Claims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	result.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+
+	if result.Subject == "" {
+		return nil, fmt.Errorf("VerifyIDToken: id token has no subject")
+	}
+
+	return result, nil
+}
+
+func NewPKCE() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("NewPKCE: failed read random bytes: %w", err)
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(buf)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered || (p.config.AuthURL != "" && p.config.TokenURL != "" && p.config.JWKSURL != "") {
+		p.discovered = true
+		return nil
+	}
+
+	endpoint := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("discover: failed create discovery request: %w", err)
+	}
+
+	var document discoveryDocument
+	if err := p.doJSON(req, &document); err != nil {
+		return fmt.Errorf("discover: %w", err)
+	}
+
+	if document.Issuer != p.config.Issuer {
+		return fmt.Errorf("discover: issuer mismatch: %s", document.Issuer)
+	}
+
+	if p.config.AuthURL == "" {
+		p.config.AuthURL = document.AuthorizationEndpoint
+	}
+	if p.config.TokenURL == "" {
+		p.config.TokenURL = document.TokenEndpoint
+	}
+	if p.config.JWKSURL == "" {
+		p.config.JWKSURL = document.JWKSURI
+	}
+	p.discovered = true
+
+	return nil
+}
+
+func (p *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval && p.keys != nil {
+		return nil, fmt.Errorf("publicKey: unknown key id %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("publicKey: failed create jwks request: %w", err)
+	}
+
+	var set jwks.Set
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("publicKey: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("publicKey: unknown key id %q", kid)
+}
+
+func (p *Provider) findKey(kid string) (crypto.PublicKey, bool) {
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok
+	}
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("doJSON: request to %s failed: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("doJSON: unexpected status %d from %s", resp.StatusCode, req.URL)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("doJSON: failed decode response from %s: %w", req.URL, err)
+	}
+
+	return nil
+}
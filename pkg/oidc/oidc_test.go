@@ -0,0 +1,235 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/server/pkg/oidc"
+	"github.com/server/pkg/oidc/oidctest"
+)
+
+var alice = oidctest.Identity{
+	Subject:       "alice-subject",
+	Email:         "alice@example.com",
+	EmailVerified: true,
+	Name:          "Alice",
+}
+
+// authorize starts a login and lets the provider approve it.
+func authorize(t *testing.T, server *oidctest.Server, provider *oidc.Provider, identity oidctest.Identity) (string, string, string) {
+	t.Helper()
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code, _, err := server.Authorize(authURL, identity)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	return code, verifier, "nonce"
+}
+
+func TestLoginWithPKCE(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	code, verifier, nonce := authorize(t, server, provider, alice)
+
+	idToken, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(context.Background(), idToken, nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+
+	if claims.Subject != alice.Subject || claims.Email != alice.Email || !claims.EmailVerified || claims.Name != alice.Name {
+		t.Errorf("claims = %+v, want identity %+v", claims, alice)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "the-state", "the-nonce", "the-challenge")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	if !strings.HasPrefix(authURL, server.URL()+"/authorize?") {
+		t.Fatalf("AuthCodeURL = %s, want the discovered endpoint", authURL)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        "the-challenge",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := parsed.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	code, _, _ := authorize(t, server, provider, alice)
+	otherVerifier, _, _ := oidc.NewPKCE()
+
+	if _, err := provider.Exchange(context.Background(), code, otherVerifier); err == nil {
+		t.Fatal("Exchange with a foreign code verifier succeeded")
+	}
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	code, verifier, _ := authorize(t, server, provider, alice)
+
+	if _, err := provider.Exchange(context.Background(), code, verifier); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, verifier); err == nil {
+		t.Fatal("second Exchange of the same code succeeded")
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongNonce(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	code, verifier, _ := authorize(t, server, provider, alice)
+	idToken, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(context.Background(), idToken, "another-nonce"); err == nil {
+		t.Fatal("VerifyIDToken accepted a token issued for another nonce")
+	}
+}
+
+func TestVerifyIDTokenRejectsForgedSignature(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	_, forged, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SignWith(forged)
+
+	code, verifier, nonce := authorize(t, server, provider, alice)
+	idToken, err := provider.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(context.Background(), idToken, nonce); err == nil {
+		t.Fatal("VerifyIDToken accepted a token signed with a key outside the JWKS")
+	}
+}
+
+func TestVerifyIDTokenRejectsTamperedToken(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	idToken, err := server.IDToken(server.Claims(alice, "nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := server.IDToken(server.Claims(oidctest.Identity{Subject: "mallory"}, "nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The payload of one token with the signature of another.
+	parts := strings.Split(idToken, ".")
+	parts[1] = strings.Split(other, ".")[1]
+
+	if _, err := provider.VerifyIDToken(context.Background(), strings.Join(parts, "."), "nonce"); err == nil {
+		t.Fatal("VerifyIDToken accepted a token with a swapped payload")
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	tests := []struct {
+		name   string
+		change func(claims jwt.MapClaims)
+	}{
+		{"foreign issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{"foreign audience", func(claims jwt.MapClaims) { claims["aud"] = "another-client" }},
+		{"expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", func(claims jwt.MapClaims) { delete(claims, "exp") }},
+		{"no subject", func(claims jwt.MapClaims) { delete(claims, "sub") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := server.Claims(alice, "nonce")
+			tt.change(claims)
+
+			idToken, err := server.IDToken(claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := provider.VerifyIDToken(context.Background(), idToken, "nonce"); err == nil {
+				t.Fatal("VerifyIDToken accepted the token")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsignedToken(t *testing.T) {
+	server := oidctest.NewServer("client")
+	defer server.Close()
+	provider := oidc.NewProvider(server.Config("test"), nil)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, server.Claims(alice, "nonce"))
+	idToken, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.VerifyIDToken(context.Background(), idToken, "nonce"); err == nil {
+		t.Fatal("VerifyIDToken accepted an unsigned token")
+	}
+}
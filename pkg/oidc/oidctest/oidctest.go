@@ -0,0 +1,220 @@
+// Package oidctest runs an identity provider in memory for tests of the OIDC
+// login. It serves discovery, a JWKS and a token endpoint that checks PKCE,
+// and signs id tokens for whatever identity a test authorizes.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/server/configs"
+	"github.com/server/pkg/jwks"
+)
+
+const keyID = "test-key"
+
+// Identity is the user the provider signs in.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type grant struct {
+	challenge string
+	nonce     string
+	identity  Identity
+}
+
+type Server struct {
+	ClientID string
+
+	server *httptest.Server
+
+	mu         sync.Mutex
+	key        ed25519.PrivateKey
+	signingKey ed25519.PrivateKey
+	grants     map[string]grant
+}
+
+func NewServer(clientID string) *Server {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed generate key: %v", err))
+	}
+
+	s := &Server{
+		ClientID:   clientID,
+		key:        key,
+		signingKey: key,
+		grants:     make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.keys)
+	mux.HandleFunc("POST /token", s.token)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config is the provider config that finds everything else by discovery.
+func (s *Server) Config(name string) configs.OIDCProviderConfig {
+	return configs.OIDCProviderConfig{
+		Name:        name,
+		Issuer:      s.server.URL,
+		ClientID:    s.ClientID,
+		RedirectURL: "http://client.test/auth/oidc/" + name + "/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}
+}
+
+// Authorize plays the user approving the login at authURL and returns the
+// code and the state the provider redirects back with.
+func (s *Server) Authorize(authURL string, identity Identity) (string, string, error) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", fmt.Errorf("Authorize: %w", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("client_id") != s.ClientID {
+		return "", "", fmt.Errorf("Authorize: unknown client %q", query.Get("client_id"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", fmt.Errorf("Authorize: S256 code challenge required")
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.grants[code] = grant{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		identity:  identity,
+	}
+	s.mu.Unlock()
+
+	return code, query.Get("state"), nil
+}
+
+// SignWith makes the provider sign id tokens with key, which is not in its
+// JWKS unless it is the original key.
+func (s *Server) SignWith(key ed25519.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signingKey = key
+}
+
+// IDToken signs claims the way the token endpoint does, so tests can shape
+// them freely.
+func (s *Server) IDToken(claims jwt.MapClaims) (string, error) {
+	s.mu.Lock()
+	key := s.signingKey
+	s.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+// Claims are the claims of a valid id token for identity.
+func (s *Server) Claims(identity Identity, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.server.URL,
+		"aud":            s.ClientID,
+		"sub":            identity.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          identity.Email,
+		"email_verified": identity.EmailVerified,
+		"name":           identity.Name,
+	}
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.server.URL,
+		"authorization_endpoint": s.server.URL + "/authorize",
+		"token_endpoint":         s.server.URL + "/token",
+		"jwks_uri":               s.server.URL + "/jwks",
+	})
+}
+
+func (s *Server) keys(w http.ResponseWriter, r *http.Request) {
+	key, err := jwks.FromPublicKey(keyID, "EdDSA", s.key.Public())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, jwks.Set{Keys: []jwks.Key{key}})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// A code is good for one exchange only, whatever its outcome.
+	s.mu.Lock()
+	granted, ok := s.grants[r.PostForm.Get("code")]
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != granted.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := s.IDToken(s.Claims(granted.identity, granted.nonce))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": randomString(),
+		"id_token":     idToken,
+		"token_type":   "Bearer",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
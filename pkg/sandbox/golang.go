@@ -0,0 +1,124 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const goModule = "module solution\n\ngo 1.23\n"
+
+// Go builds the submission and the harness as one main package with cgo and
+// the module proxy turned off, then runs the static binary in a jail that
+// holds nothing else.
+type Go struct {
+	binary   string
+	cacheDir string
+	limits   Limits
+}
+
+func NewGo(binary string, cacheDir string, limits Limits) *Go {
+	return &Go{
+		binary:   binary,
+		cacheDir: cacheDir,
+		limits:   limits,
+	}
+}
+
+func (g *Go) Language() string {
+	return "go"
+}
+
+func (g *Go) Run(ctx context.Context, program Program, inputs []string) ([]Result, error) {
+	dir, err := os.MkdirTemp("", "sandbox-go-")
+	if err != nil {
+		return nil, fmt.Errorf("Run: failed create work dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	jail := filepath.Join(dir, "jail")
+	for _, path := range []string{src, jail} {
+		if err := os.Mkdir(path, 0o700); err != nil {
+			return nil, fmt.Errorf("Run: failed create %s: %w", path, err)
+		}
+	}
+
+	files := map[string]string{
+		"go.mod":      goModule,
+		"solution.go": program.Source,
+		"harness.go":  program.Harness,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o600); err != nil {
+			return nil, fmt.Errorf("Run: failed write %s: %w", name, err)
+		}
+	}
+
+	if err := g.build(ctx, src, filepath.Join(jail, "program")); err != nil {
+		return nil, fmt.Errorf("Run: %w", err)
+	}
+
+	results := make([]Result, len(inputs))
+	for i, input := range inputs {
+		runCtx, cancel := context.WithTimeout(ctx, g.limits.RunTimeout)
+		cmd, err := jailed(runCtx, jail, "/program", g.limits.runBounds())
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("Run: %w", err)
+		}
+		cmd.Env = []string{"GOMAXPROCS=1", fmt.Sprintf("GOMEMLIMIT=%dMiB", g.limits.MemoryMB)}
+
+		results[i], _ = execute(runCtx, cmd, input, g.limits)
+		cancel()
+
+		// A time limit of the caller is not the program running too long.
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Run: %w", ctx.Err())
+		}
+	}
+
+	return results, nil
+}
+
+func (g *Go) build(ctx context.Context, src string, output string) error {
+	buildCtx, cancel := context.WithTimeout(ctx, g.limits.BuildTimeout)
+	defer cancel()
+
+	cmd, err := isolated(buildCtx, src, g.binary, g.limits.buildBounds(), "build", "-trimpath", "-o", output, ".")
+	if err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + src,
+		"GOCACHE=" + g.cacheDir,
+		"GOPATH=" + filepath.Join(src, ".gopath"),
+		"GOPROXY=off",
+		"GOFLAGS=-mod=mod",
+		"GOTOOLCHAIN=local",
+		"CGO_ENABLED=0",
+	}
+
+	result, err := execute(buildCtx, cmd, "", g.limits)
+	if ctx.Err() != nil {
+		return fmt.Errorf("build: %w", ctx.Err())
+	}
+	if result.TimedOut {
+		return &BuildError{Output: "build time limit exceeded"}
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &BuildError{Output: result.Error}
+	}
+
+	if err != nil {
+		return fmt.Errorf("build: failed run %s: %w", g.binary, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// initName is the name the server binary is started under to act as the
+// first process of a sandbox. It takes the jail root (empty for none), the
+// encoded resource limits, then the command and its arguments.
+const initName = "sandbox-init"
+
+// Namespaces the sandboxed program may not create for itself.
+const namespaceFlags = unix.CLONE_NEWNS | unix.CLONE_NEWUSER | unix.CLONE_NEWPID | unix.CLONE_NEWNET |
+	unix.CLONE_NEWIPC | unix.CLONE_NEWUTS | unix.CLONE_NEWCGROUP
+
+// deniedSyscalls fail with EPERM inside the sandbox. None of them is needed
+// to compile or run a program, all of them widen what the kernel exposes.
+var deniedSyscalls = []uint32{
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	unix.SYS_UNSHARE, unix.SYS_SETNS,
+	unix.SYS_OPEN_TREE, unix.SYS_MOVE_MOUNT, unix.SYS_FSOPEN, unix.SYS_FSCONFIG, unix.SYS_FSMOUNT, unix.SYS_FSPICK, unix.SYS_MOUNT_SETATTR,
+	unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+	unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN, unix.SYS_USERFAULTFD, unix.SYS_IO_URING_SETUP,
+	unix.SYS_OPEN_BY_HANDLE_AT, unix.SYS_NAME_TO_HANDLE_AT,
+	unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+	unix.SYS_KEXEC_LOAD, unix.SYS_REBOOT, unix.SYS_SWAPON, unix.SYS_SWAPOFF,
+	unix.SYS_ACCT, unix.SYS_SETTIMEOFDAY, unix.SYS_CLOCK_SETTIME, unix.SYS_ADJTIMEX,
+}
+
+var auditArch = map[string]uint32{
+	"amd64": unix.AUDIT_ARCH_X86_64,
+	"arm64": unix.AUDIT_ARCH_AARCH64,
+}
+
+func init() {
+	if len(os.Args) < 4 || os.Args[0] != initName {
+		return
+	}
+
+	err := enter(os.Args[1], os.Args[2], os.Args[3:])
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(126)
+}
+
+// enter sets the limits, moves into root and execs the command with no
+// capabilities, no way to gain new privileges and the syscall filter on. It
+// only returns on failure.
+func enter(root string, encodedLimits string, command []string) error {
+	limits, err := decodeRlimits(encodedLimits)
+	if err != nil {
+		return fmt.Errorf("enter: %w", err)
+	}
+
+	for resource, value := range limits {
+		if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: value, Max: value}); err != nil {
+			return fmt.Errorf("enter: failed set limit %d: %w", resource, err)
+		}
+	}
+
+	path := command[0]
+	if root == "" {
+		path, err = exec.LookPath(path)
+		if err != nil {
+			return fmt.Errorf("enter: %w", err)
+		}
+	} else {
+		if err := unix.Chroot(root); err != nil {
+			return fmt.Errorf("enter: failed chroot: %w", err)
+		}
+		if err := unix.Chdir("/"); err != nil {
+			return fmt.Errorf("enter: failed chdir: %w", err)
+		}
+	}
+
+	// No new privileges, the filter and the credentials exec hands on are
+	// all per thread.
+	runtime.LockOSThread()
+
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return fmt.Errorf("enter: failed drop capabilities: %w", err)
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("enter: failed set no_new_privs: %w", err)
+	}
+
+	filter, err := seccompFilter()
+	if err != nil {
+		return fmt.Errorf("enter: %w", err)
+	}
+	program := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&program)), 0, 0); err != nil {
+		return fmt.Errorf("enter: failed install seccomp filter: %w", err)
+	}
+
+	if err := syscall.Exec(path, command, os.Environ()); err != nil {
+		return fmt.Errorf("enter: failed exec %s: %w", command[0], err)
+	}
+	return nil
+}
+
+// seccompFilter kills processes of a foreign architecture, refuses the
+// denied syscalls and clone with any of the namespace flags. clone3 reports
+// ENOSYS because its flags can not be inspected, so callers fall back to
+// clone.
+func seccompFilter() ([]unix.SockFilter, error) {
+	arch, ok := auditArch[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("seccompFilter: no audit arch for %s", runtime.GOARCH)
+	}
+
+	const (
+		offsetNr   = 0
+		offsetArch = 4
+		offsetArg0 = 16
+		// Syscall numbers this high are the x32 ABI on amd64.
+		x32Bit = 0x40000000
+	)
+
+	load := func(offset uint32) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: offset}
+	}
+	jump := func(op uint16, value uint32, jt uint8, jf uint8) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_JMP | op | unix.BPF_K, Jt: jt, Jf: jf, K: value}
+	}
+	ret := func(value uint32) unix.SockFilter {
+		return unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: value}
+	}
+	eperm := ret(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+
+	filter := []unix.SockFilter{
+		load(offsetArch),
+		jump(unix.BPF_JEQ, arch, 1, 0),
+		ret(unix.SECCOMP_RET_KILL_PROCESS),
+		load(offsetNr),
+		jump(unix.BPF_JGE, x32Bit, 0, 1),
+		ret(unix.SECCOMP_RET_KILL_PROCESS),
+	}
+	for _, nr := range deniedSyscalls {
+		filter = append(filter, jump(unix.BPF_JEQ, nr, 0, 1), eperm)
+	}
+
+	return append(filter,
+		jump(unix.BPF_JEQ, unix.SYS_CLONE3, 0, 1),
+		ret(unix.SECCOMP_RET_ERRNO|uint32(unix.ENOSYS)),
+		jump(unix.BPF_JEQ, unix.SYS_CLONE, 1, 0),
+		ret(unix.SECCOMP_RET_ALLOW),
+		load(offsetArg0),
+		jump(unix.BPF_JSET, namespaceFlags, 0, 1),
+		eperm,
+		ret(unix.SECCOMP_RET_ALLOW),
+	), nil
+}
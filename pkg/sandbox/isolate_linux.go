@@ -0,0 +1,121 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// jailID is the user and group the program runs as inside its namespace.
+// Only the user of the server is mapped onto it, so the program holds no
+// privileges there and none on the host either.
+const jailID = 65534
+
+// isolated starts the command through the init step of the sandbox inside
+// fresh user, network, pid, ipc, uts and mount namespaces. Init applies the
+// bounds and drops every privilege before the command runs. The process gets
+// no network at all and its whole group is killed on timeout.
+func isolated(ctx context.Context, dir string, name string, bounds bounds, args ...string) (*exec.Cmd, error) {
+	return initCmd(ctx, dir, "", name, bounds, args...)
+}
+
+// jailed runs path inside root as the only visible file tree. The init step
+// keeps the right to chroot just long enough to enter root, so no privileges
+// are needed on the host.
+func jailed(ctx context.Context, root string, path string, bounds bounds) (*exec.Cmd, error) {
+	return initCmd(ctx, "/", root, path, bounds)
+}
+
+func initCmd(ctx context.Context, dir string, root string, name string, bounds bounds, args ...string) (*exec.Cmd, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("isolated: failed find server binary: %w", err)
+	}
+
+	var capabilities []uintptr
+	if root != "" {
+		capabilities = []uintptr{unix.CAP_SYS_CHROOT}
+	}
+
+	cmd := exec.CommandContext(ctx, self)
+	cmd.Args = append([]string{initName, root, encodeRlimits(bounds), name}, args...)
+	cmd.Dir = dir
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Pdeathsig:  syscall.SIGKILL,
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | syscall.CLONE_NEWNS,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: jailID, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: jailID, HostID: os.Getgid(), Size: 1},
+		},
+		AmbientCaps: capabilities,
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	return cmd, nil
+}
+
+// encodeRlimits lists the resource limits init has to set as
+// resource=value pairs. Zero bounds are not applied.
+func encodeRlimits(bounds bounds) string {
+	var limits []string
+	add := func(resource int, value uint64) {
+		limits = append(limits, fmt.Sprintf("%d=%d", resource, value))
+	}
+
+	if bounds.memoryMB > 0 {
+		add(unix.RLIMIT_DATA, uint64(bounds.memoryMB)<<20)
+	}
+	if bounds.cpu > 0 {
+		add(unix.RLIMIT_CPU, uint64(math.Ceil(bounds.cpu.Seconds())))
+	}
+	if bounds.processes > 0 {
+		add(unix.RLIMIT_NPROC, uint64(bounds.processes))
+	}
+	if bounds.readOnly {
+		add(unix.RLIMIT_FSIZE, 0)
+		add(unix.RLIMIT_NOFILE, 64)
+	}
+
+	return strings.Join(limits, ",")
+}
+
+func decodeRlimits(encoded string) (map[int]uint64, error) {
+	limits := make(map[int]uint64)
+	for _, pair := range strings.Split(encoded, ",") {
+		if pair == "" {
+			continue
+		}
+
+		resource, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("decodeRlimits: malformed limit %q", pair)
+		}
+
+		key, err := strconv.Atoi(resource)
+		if err != nil {
+			return nil, fmt.Errorf("decodeRlimits: %w", err)
+		}
+
+		limits[key], err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("decodeRlimits: %w", err)
+		}
+	}
+	return limits, nil
+}
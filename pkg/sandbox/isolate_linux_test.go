@@ -0,0 +1,65 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestRlimitsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds bounds
+		want   map[int]uint64
+	}{
+		{
+			name:   "none",
+			bounds: bounds{},
+			want:   map[int]uint64{},
+		},
+		{
+			name:   "build",
+			bounds: bounds{memoryMB: 2048, cpu: 60 * time.Second, processes: 256},
+			want: map[int]uint64{
+				unix.RLIMIT_DATA:  2048 << 20,
+				unix.RLIMIT_CPU:   60,
+				unix.RLIMIT_NPROC: 256,
+			},
+		},
+		{
+			name:   "run",
+			bounds: bounds{memoryMB: 256, cpu: 1500 * time.Millisecond, processes: 32, readOnly: true},
+			want: map[int]uint64{
+				unix.RLIMIT_DATA:   256 << 20,
+				unix.RLIMIT_CPU:    2,
+				unix.RLIMIT_NPROC:  32,
+				unix.RLIMIT_FSIZE:  0,
+				unix.RLIMIT_NOFILE: 64,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		encoded := encodeRlimits(tt.bounds)
+		got, err := decodeRlimits(encoded)
+		if err != nil {
+			t.Errorf("%s: decodeRlimits(%q): %v", tt.name, encoded, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("%s: decodeRlimits(encodeRlimits()) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeRlimitsRejectsMalformed(t *testing.T) {
+	for _, encoded := range []string{"7", "x=1", "7=-1", "7=1,=2", "7=1e3"} {
+		if _, err := decodeRlimits(encoded); err == nil {
+			t.Errorf("decodeRlimits(%q) succeeded", encoded)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Programs are isolated with linux namespaces only, elsewhere nothing is run.
+func isolated(ctx context.Context, dir string, name string, bounds bounds, args ...string) (*exec.Cmd, error) {
+	return nil, ErrUnsupported
+}
+
+func jailed(ctx context.Context, root string, path string, bounds bounds) (*exec.Cmd, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,198 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"time"
+)
+
+// ErrUnsupported is returned where programs can not be isolated.
+var ErrUnsupported = errors.New("sandbox is not supported on this platform")
+
+// Limits bound a single run of a program. Build limits are separate because
+// compilers need much more than the programs they build.
+type Limits struct {
+	BuildTimeout   time.Duration
+	BuildMemoryMB  int
+	BuildProcesses int
+	RunTimeout     time.Duration
+	MemoryMB       int
+	Processes      int
+	OutputBytes    int
+}
+
+// bounds are the kernel limits an isolated process starts with, zero fields
+// are not applied. A read-only process may not write files and may open only
+// a few.
+type bounds struct {
+	memoryMB  int
+	cpu       time.Duration
+	processes int
+	readOnly  bool
+}
+
+// buildBounds limit the compiler, which has to write its output.
+func (l Limits) buildBounds() bounds {
+	return bounds{
+		memoryMB:  l.BuildMemoryMB,
+		cpu:       l.BuildTimeout,
+		processes: l.BuildProcesses,
+	}
+}
+
+// runBounds limit one run of a built program.
+func (l Limits) runBounds() bounds {
+	return bounds{
+		memoryMB:  l.MemoryMB,
+		cpu:       l.RunTimeout,
+		processes: l.Processes,
+		readOnly:  true,
+	}
+}
+
+// Program is the submitted source and the harness of the question author that
+// calls into it. Both are compiled together.
+type Program struct {
+	Source  string
+	Harness string
+}
+
+// Result is one run of a program on one input. ExitCode is -1 when the
+// process was killed.
+type Result struct {
+	Output   string
+	Error    string
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+}
+
+// BuildError means the program did not compile, Output is what the compiler
+// said about it.
+type BuildError struct {
+	Output string
+}
+
+func (e *BuildError) Error() string {
+	return "build failed: " + e.Output
+}
+
+// Runner builds and runs programs of one language. Every input is fed to a
+// fresh process on stdin.
+type Runner interface {
+	Language() string
+	Run(ctx context.Context, program Program, inputs []string) ([]Result, error)
+}
+
+// Sandbox picks the runner by the language of the question. At most
+// concurrency programs are built and run at once, the others wait their turn.
+type Sandbox struct {
+	runners map[string]Runner
+	slots   chan struct{}
+}
+
+func New(concurrency int, runners ...Runner) *Sandbox {
+	sandbox := &Sandbox{
+		runners: make(map[string]Runner, len(runners)),
+		slots:   make(chan struct{}, concurrency),
+	}
+	for _, runner := range runners {
+		sandbox.runners[runner.Language()] = runner
+	}
+	return sandbox
+}
+
+func (s *Sandbox) Languages() []string {
+	languages := make([]string, 0, len(s.runners))
+	for language := range s.runners {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+func (s *Sandbox) Supports(language string) bool {
+	_, ok := s.runners[language]
+	return ok
+}
+
+func (s *Sandbox) Run(ctx context.Context, language string, program Program, inputs []string) ([]Result, error) {
+	runner, ok := s.runners[language]
+	if !ok {
+		return nil, fmt.Errorf("Run: no runner for language %s", language)
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("Run: gave up waiting for a free slot: %w", ctx.Err())
+	}
+
+	return runner.Run(ctx, program, inputs)
+}
+
+// execute runs an isolated command with a timeout, feeding it input and
+// keeping at most limits.OutputBytes of its stdout and stderr. The error of
+// the command is returned as well so callers can tell a failed exit apart.
+// Only the deadline of ctx counts as a timeout; a cancelled ctx is an error.
+func execute(ctx context.Context, cmd *exec.Cmd, input string, limits Limits) (Result, error) {
+	stdout := &limitedBuffer{limit: limits.OutputBytes}
+	stderr := &limitedBuffer{limit: limits.OutputBytes}
+	cmd.Stdin = bytes.NewBufferString(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{
+		Output:   stdout.String(),
+		Error:    stderr.String(),
+		ExitCode: -1,
+		Duration: time.Since(start),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.Error = "time limit exceeded"
+		return result, err
+	case ctx.Err() != nil:
+		return result, fmt.Errorf("execute: %w", ctx.Err())
+	}
+
+	if err != nil && result.Error == "" {
+		result.Error = err.Error()
+	}
+
+	return result, err
+}
+
+// limitedBuffer drops what is written past its limit instead of failing, so a
+// chatty program can not fill the memory of the server.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
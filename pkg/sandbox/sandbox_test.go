@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteTimesOutOnDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, _ := execute(ctx, exec.CommandContext(ctx, "sleep", "5"), "", Limits{OutputBytes: 64})
+	if !result.TimedOut || result.Error != "time limit exceeded" {
+		t.Errorf("result = %+v, want a timeout", result)
+	}
+}
+
+func TestExecuteReportsCancelAsError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	result, err := execute(ctx, exec.CommandContext(ctx, "sleep", "5"), "", Limits{OutputBytes: 64})
+	if result.TimedOut {
+		t.Errorf("result = %+v, a cancelled run is not a timeout", result)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("execute error = %v, want context.Canceled", err)
+	}
+}
+
+func TestExecuteKeepsOutputWithinLimit(t *testing.T) {
+	result, err := execute(context.Background(), exec.Command("cat"), strings.Repeat("x", 100), Limits{OutputBytes: 10})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if result.Output != strings.Repeat("x", 10) || result.ExitCode != 0 {
+		t.Errorf("result = %+v, want 10 bytes of output and exit code 0", result)
+	}
+}
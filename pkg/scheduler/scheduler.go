@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs periodically in their own goroutines until it is stopped.
+type Scheduler struct {
+	logger *zap.Logger
+	jobs   []job
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+	}
+}
+
+func (s *Scheduler) Add(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{
+		name:     name,
+		interval: interval,
+		run:      run,
+	})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	s.logger.Info("Scheduler started", zap.Int("jobs", len(s.jobs)))
+}
+
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, j)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Scheduled job panicked", zap.String("job", j.name), zap.Error(fmt.Errorf("%v", r)))
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		s.logger.Error("Scheduled job failed", zap.String("job", j.name), zap.Error(err))
+	}
+}
@@ -0,0 +1,171 @@
+package similarity
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Span is a byte range of the original text.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type token struct {
+	word  string
+	start int
+	end   int
+}
+
+// tokenize splits text into lower case words of letters and digits and keeps
+// where every word is in the original text.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func shingleHashes(tokens []token, size int) []uint64 {
+	if len(tokens) < size {
+		size = len(tokens)
+	}
+	if size == 0 {
+		return nil
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-size+1)
+	for i := 0; i+size <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+size] {
+			h.Write([]byte(t.word))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+	return hashes
+}
+
+// Shingles returns the set of hashed word k-grams of the text.
+func Shingles(text string, size int) map[uint64]struct{} {
+	set := make(map[uint64]struct{})
+	for _, hash := range shingleHashes(tokenize(text), size) {
+		set[hash] = struct{}{}
+	}
+	return set
+}
+
+// Signature is the MinHash of a shingle set. Two signatures agree on about
+// the Jaccard similarity of their sets.
+func Signature(shingles map[uint64]struct{}, size int) []uint64 {
+	signature := make([]uint64, size)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+
+	for shingle := range shingles {
+		for i := range signature {
+			if value := mix(shingle, uint64(i)); value < signature[i] {
+				signature[i] = value
+			}
+		}
+	}
+
+	return signature
+}
+
+// Estimate is the share of positions where two signatures agree.
+func Estimate(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	same := 0
+	for i := range a {
+		if a[i] == b[i] && a[i] != math.MaxUint64 {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// Jaccard is the exact similarity of two shingle sets.
+func Jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	common := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// Overlaps finds the passages two texts share as runs of common word
+// k-grams. Spans are returned for each text separately.
+func Overlaps(a, b string, size int) ([]Span, []Span) {
+	tokensA, tokensB := tokenize(a), tokenize(b)
+	hashesA, hashesB := shingleHashes(tokensA, size), shingleHashes(tokensB, size)
+
+	return overlapSpans(tokensA, hashesA, hashesB, size), overlapSpans(tokensB, hashesB, hashesA, size)
+}
+
+func overlapSpans(tokens []token, own []uint64, other []uint64, size int) []Span {
+	if len(tokens) < size {
+		size = len(tokens)
+	}
+
+	shared := make(map[uint64]struct{}, len(other))
+	for _, hash := range other {
+		shared[hash] = struct{}{}
+	}
+
+	covered := make([]bool, len(tokens))
+	for i, hash := range own {
+		if _, ok := shared[hash]; ok {
+			for j := i; j < i+size; j++ {
+				covered[j] = true
+			}
+		}
+	}
+
+	var spans []Span
+	for i := 0; i < len(tokens); i++ {
+		if !covered[i] {
+			continue
+		}
+		start := i
+		for i+1 < len(tokens) && covered[i+1] {
+			i++
+		}
+		spans = append(spans, Span{Start: tokens[start].start, End: tokens[i].end})
+	}
+	return spans
+}
+
+// mix derives the i-th hash function from a shingle hash (splitmix64).
+func mix(value uint64, seed uint64) uint64 {
+	value += (seed + 1) * 0x9e3779b97f4a7c15
+	value = (value ^ (value >> 30)) * 0xbf58476d1ce4e5b9
+	value = (value ^ (value >> 27)) * 0x94d049bb133111eb
+	return value ^ (value >> 31)
+}
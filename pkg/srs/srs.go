@@ -0,0 +1,66 @@
+// Package srs schedules spaced repetition reviews with the SM-2 algorithm.
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// Card is how well a person knows one item. Interval is in days.
+type Card struct {
+	Repetitions int
+	Interval    int
+	EaseFactor  float64
+}
+
+const (
+	DefaultEase = 2.5
+	MinEase     = 1.3
+
+	// MaxQuality is a perfect recall, an answer below PassQuality is a lapse.
+	MaxQuality  = 5
+	PassQuality = 3
+)
+
+// New is a card that was never reviewed.
+func New() Card {
+	return Card{EaseFactor: DefaultEase}
+}
+
+// Review returns the card after an answer of the given quality from 0 to 5.
+// A lapse starts the repetitions over, otherwise the interval grows by the
+// ease factor after the first two reviews of one and six days. The ease
+// factor follows the quality either way but never drops below MinEase.
+func Review(card Card, quality int) Card {
+	quality = max(0, min(MaxQuality, quality))
+	if card.EaseFactor < MinEase {
+		card.EaseFactor = DefaultEase
+	}
+
+	if quality < PassQuality {
+		card.Repetitions = 0
+		card.Interval = 1
+	} else {
+		switch card.Repetitions {
+		case 0:
+			card.Interval = 1
+		case 1:
+			card.Interval = 6
+		default:
+			card.Interval = int(math.Round(float64(card.Interval) * card.EaseFactor))
+		}
+		card.Repetitions++
+	}
+
+	miss := float64(MaxQuality - quality)
+	card.EaseFactor = math.Max(MinEase, card.EaseFactor+0.1-miss*(0.08+miss*0.02))
+
+	return card
+}
+
+// Due is the day the card should be reviewed again after a review at
+// reviewedAt, at the start of that day in the location of reviewedAt.
+func Due(card Card, reviewedAt time.Time) time.Time {
+	year, month, day := reviewedAt.Date()
+	return time.Date(year, month, day+card.Interval, 0, 0, 0, 0, reviewedAt.Location())
+}
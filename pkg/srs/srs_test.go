@@ -0,0 +1,61 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReviewIntervals(t *testing.T) {
+	tests := []struct {
+		name      string
+		quality   int
+		intervals []int
+		eases     []float64
+	}{
+		{"perfect", 5, []int{1, 6, 16, 45}, []float64{2.6, 2.7, 2.8, 2.9}},
+		{"good", 4, []int{1, 6, 15, 38}, []float64{2.5, 2.5, 2.5, 2.5}},
+		{"hard", 3, []int{1, 6, 13, 27}, []float64{2.36, 2.22, 2.08, 1.94}},
+		{"above the scale", 9, []int{1, 6, 16, 45}, []float64{2.6, 2.7, 2.8, 2.9}},
+	}
+
+	for _, tt := range tests {
+		card := New()
+		for i := range tt.intervals {
+			card = Review(card, tt.quality)
+			if card.Repetitions != i+1 || card.Interval != tt.intervals[i] || math.Abs(card.EaseFactor-tt.eases[i]) > 1e-9 {
+				t.Errorf("%s: review %d = %+v, want %d repetitions, interval %d, ease %v",
+					tt.name, i+1, card, i+1, tt.intervals[i], tt.eases[i])
+			}
+		}
+	}
+}
+
+func TestReviewLapse(t *testing.T) {
+	card := Review(Card{Repetitions: 4, Interval: 45, EaseFactor: 2.9}, 2)
+	if card.Repetitions != 0 || card.Interval != 1 || math.Abs(card.EaseFactor-2.58) > 1e-9 {
+		t.Errorf("Review after a lapse = %+v, want 0 repetitions, interval 1, ease 2.58", card)
+	}
+}
+
+func TestReviewEaseFloor(t *testing.T) {
+	card := Card{Repetitions: 3, Interval: 10, EaseFactor: MinEase}
+	for range 3 {
+		card = Review(card, 0)
+	}
+	if card.EaseFactor != MinEase {
+		t.Errorf("EaseFactor = %v, want the floor %v", card.EaseFactor, MinEase)
+	}
+
+	if got := Review(Card{}, MaxQuality); got.EaseFactor != DefaultEase+0.1 {
+		t.Errorf("Review of a card without ease = %+v, want it to start from %v", got, DefaultEase)
+	}
+}
+
+func TestDue(t *testing.T) {
+	reviewedAt := time.Date(2024, time.March, 30, 22, 15, 0, 0, time.UTC)
+	want := time.Date(2024, time.April, 5, 0, 0, 0, 0, time.UTC)
+	if got := Due(Card{Interval: 6}, reviewedAt); !got.Equal(want) {
+		t.Errorf("Due = %v, want %v", got, want)
+	}
+}
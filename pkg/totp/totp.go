@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	period = 30
+	digits = 6
+	skew   = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("GenerateSecret: failed read random bytes: %w", err)
+	}
+
+	return encoding.EncodeToString(buf), nil
+}
+
+func ProvisioningURI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(period))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return codeAt(secret, uint64(t.Unix()/period))
+}
+
+func Validate(code string, secret string, t time.Time) bool {
+	_, ok := ValidateStep(code, secret, t)
+	return ok
+}
+
+// ValidateStep is Validate that also tells the time step the code belongs
+// to, so a caller can refuse a code that was already accepted once.
+func ValidateStep(code string, secret string, t time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != digits {
+		return 0, false
+	}
+
+	counter := t.Unix() / period
+	for offset := int64(-skew); offset <= skew; offset++ {
+		expected, err := codeAt(secret, uint64(counter+offset))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter + offset, true
+		}
+	}
+
+	return 0, false
+}
+
+func codeAt(secret string, counter uint64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", fmt.Errorf("codeAt: failed decode secret: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1000000), nil
+}
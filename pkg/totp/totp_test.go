@@ -0,0 +1,131 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890"
+// in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes, these are their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateCodeMatchesRFC6238(t *testing.T) {
+	for _, tt := range rfcVectors {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidateStep(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / period
+
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"same step", now, true},
+		{"previous step", now.Add(-period * time.Second), true},
+		{"next step", now.Add(period * time.Second), true},
+		{"two steps back", now.Add(-2 * period * time.Second), false},
+		{"two steps ahead", now.Add(2 * period * time.Second), false},
+	}
+
+	for _, tt := range tests {
+		code, err := GenerateCode(rfcSecret, tt.at)
+		if err != nil {
+			t.Fatalf("%s: GenerateCode: %v", tt.name, err)
+		}
+
+		got, ok := ValidateStep(code, rfcSecret, now)
+		if ok != tt.ok {
+			t.Errorf("%s: ValidateStep ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.at.Unix()/period {
+			t.Errorf("%s: ValidateStep step = %d, want %d", tt.name, got, tt.at.Unix()/period)
+		}
+	}
+
+	if _, ok := ValidateStep(" 050471 ", rfcSecret, now); !ok {
+		t.Error("ValidateStep refused a code with surrounding spaces")
+	}
+	if got, _ := ValidateStep("050471", rfcSecret, now); got != step {
+		t.Errorf("ValidateStep step = %d, want %d", got, step)
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name   string
+		code   string
+		secret string
+	}{
+		{"wrong code", "050472", rfcSecret},
+		{"short code", "05047", rfcSecret},
+		{"long code", "0050471", rfcSecret},
+		{"empty code", "", rfcSecret},
+		{"other secret", "050471", "JBSWY3DPEHPK3PXP"},
+		{"broken secret", "050471", "not base32!"},
+	}
+
+	for _, tt := range tests {
+		if Validate(tt.code, tt.secret, now) {
+			t.Errorf("%s: Validate(%q) accepted", tt.name, tt.code)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want 20", secret, len(key), err)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Tests", "bob@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("ProvisioningURI is not a URL: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Tests:bob@example.com" {
+		t.Errorf("ProvisioningURI = %s, want otpauth://totp/Tests:bob@example.com", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != rfcSecret || query.Get("issuer") != "Tests" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("ProvisioningURI query = %v", query)
+	}
+}